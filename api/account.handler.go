@@ -5,15 +5,50 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	db "github.com/T-BO0/bank/db/sqlc"
+	"github.com/T-BO0/bank/token"
+	"github.com/T-BO0/bank/util/money"
 	"github.com/labstack/echo/v4"
 	"github.com/lib/pq"
 )
 
+// accountResponse is account returned to user with balance formatted as a decimal string
+type accountResponse struct {
+	ID      int64  `json:"id"`
+	Owner   string `json:"owner"`
+	Balance string `json:"balance"`
+	// AvailableBalance is the balance less the amount reserved by authorized holds
+	AvailableBalance string    `json:"available_balance"`
+	Currency         string    `json:"currency"`
+	Type             string    `json:"type"`
+	InterestRate     string    `json:"interest_rate"`
+	Status           string    `json:"status"`
+	StatusReason     string    `json:"status_reason,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// newAccountResponse converts db account to accountResponse
+func newAccountResponse(account db.Account) accountResponse {
+	return accountResponse{
+		ID:               account.ID,
+		Owner:            account.Owner,
+		Balance:          money.Format(account.Balance, account.Currency),
+		AvailableBalance: money.Format(account.AvailableBalance, account.Currency),
+		Currency:         account.Currency,
+		Type:             account.Type,
+		InterestRate:     account.InterestRate,
+		Status:           account.Status,
+		StatusReason:     account.StatusReason.String,
+		CreatedAt:        account.CreatedAt,
+	}
+}
+
+// createAccountRequest creates a checking account unless another type is asked for
 type createAccountRequest struct {
-	Owner    string `json:"owner" validate:"required"`
 	Currency string `json:"currency" validate:"required,oneof=USD EUR GEL"`
+	Type     string `json:"type" validate:"omitempty,oneof=checking savings overdraft"`
 }
 
 // ANCHOR - createAccount is a handler that creates new Account route:POST: /accounts
@@ -30,10 +65,15 @@ func (server *Server) createAccount(c echo.Context) error {
 		return err
 	}
 
+	authPayload := c.Get(authorizationPayloadKey).(*token.Payload)
 	args := db.CreateAccountParams{
-		Owner:    createAccReq.Owner,
+		Owner:    authPayload.Username,
 		Balance:  0,
 		Currency: createAccReq.Currency,
+		Type:     createAccReq.Type,
+	}
+	if args.Type == "" {
+		args.Type = db.AccountChecking
 	}
 
 	// create acc and get error or return error
@@ -46,13 +86,13 @@ func (server *Server) createAccount(c echo.Context) error {
 					fmt.Sprintf("there is no user with user name %s", args.Owner))
 			case "unique_violation":
 				return echo.NewHTTPError(http.StatusForbidden,
-					fmt.Sprintf("the user %s already have acc with Currency with %s", args.Owner, args.Currency))
+					fmt.Sprintf("the user %s already have %s acc with Currency with %s", args.Owner, args.Type, args.Currency))
 			}
 		}
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
-	return c.JSON(http.StatusOK, account)
+	return c.JSON(http.StatusOK, newAccountResponse(account))
 }
 
 // ANCHOR - getAccount will get account with specific AccountID route:GET: /accounts/:id
@@ -72,15 +112,21 @@ func (server *Server) getAccount(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error()) // something went wrong
 	}
 
-	return c.JSON(http.StatusOK, account)
+	authPayload := c.Get(authorizationPayloadKey).(*token.Payload)
+	if account.Owner != authPayload.Username {
+		return echo.NewHTTPError(http.StatusForbidden, "account doesn't belong to the authenticated user")
+	}
+
+	return c.JSON(http.StatusOK, newAccountResponse(account))
 }
 
 type getListOfAccountRequest struct {
-	PageSize   int32 `query:"size" validate:"required,gte=5,lte=30"`
-	PageNumber int32 `query:"page" validate:"required,gte=0"`
+	PageSize int32  `query:"size" validate:"required,gte=5,lte=30"`
+	Cursor   string `query:"cursor"`
 }
 
-// ANCHOR - getListOfAccount will get a list of accounts with Offset And Size route:GET: /accounts
+// ANCHOR - getListOfAccount will get a page of the caller's accounts route:GET: /accounts?size=?&cursor=?
+// The next page is read with the next_cursor of the response
 func (server *Server) getListOfAccount(c echo.Context) error {
 	getlisofAccReq := getListOfAccountRequest{}
 
@@ -93,12 +139,20 @@ func (server *Server) getListOfAccount(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, err)
 	}
 
-	args := db.ListAccountParams{
-		Limit:  getlisofAccReq.PageSize,
-		Offset: (getlisofAccReq.PageNumber - 1) * getlisofAccReq.PageSize,
+	cursor, err := decodePageCursor(getlisofAccReq.Cursor)
+	if err != nil {
+		return err
+	}
+
+	authPayload := c.Get(authorizationPayloadKey).(*token.Payload)
+	args := db.ListAccountByOwnerAfterParams{
+		Owner:          authPayload.Username,
+		AfterCreatedAt: cursor.CreatedAt,
+		AfterID:        cursor.ID,
+		LimitCount:     getlisofAccReq.PageSize + 1,
 	}
 	// get account or error
-	accounts, err := server.store.ListAccount(c.Request().Context(), args)
+	accounts, err := server.store.ListAccountByOwnerAfter(c.Request().Context(), args)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return echo.NewHTTPError(http.StatusNotFound, "record not found") // record not found
@@ -106,5 +160,10 @@ func (server *Server) getListOfAccount(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error()) // something went wrong
 	}
 
-	return c.JSON(http.StatusOK, accounts)
+	return c.JSON(http.StatusOK, newPageResponse(accounts, getlisofAccReq.PageSize, accountCursor, newAccountResponse))
+}
+
+// accountCursor is the page cursor of an account
+func accountCursor(account db.Account) pageCursor {
+	return pageCursor{CreatedAt: account.CreatedAt, ID: account.ID}
 }
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	db "github.com/T-BO0/bank/db/sqlc"
+	"github.com/labstack/echo/v4"
+)
+
+// accountStatusRequest is request json body of the freeze, unfreeze and close handlers
+type accountStatusRequest struct {
+	Reason string `json:"reason" validate:"required,max=255"`
+}
+
+// ANCHOR - freezeAccount stops an active account from sending and receiving money route:POST: /accounts/:id/freeze
+func (server *Server) freezeAccount(c echo.Context) error {
+	return server.changeAccountStatus(c, db.AccountFrozen)
+}
+
+// ANCHOR - unfreezeAccount makes a frozen account active again route:POST: /accounts/:id/unfreeze
+func (server *Server) unfreezeAccount(c echo.Context) error {
+	return server.changeAccountStatus(c, db.AccountActive)
+}
+
+// ANCHOR - closeAccount closes an active account for good route:POST: /accounts/:id/close
+// Only an account with a zero balance and no held money can be closed, the account and its history are kept
+func (server *Server) closeAccount(c echo.Context) error {
+	return server.changeAccountStatus(c, db.AccountClosed)
+}
+
+// changeAccountStatus moves the account of the id path param to status with the reason of the request body
+func (server *Server) changeAccountStatus(c echo.Context, status string) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+
+	req := accountStatusRequest{}
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+
+	account, err := server.store.UpdateAccountStatusTx(c.Request().Context(), db.UpdateAccountStatusTxParams{
+		AccountID: id,
+		Status:    status,
+		Reason:    req.Reason,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrAccountNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case errors.Is(err, db.ErrInvalidStatusTransition), errors.Is(err, db.ErrAccountNotEmpty):
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, newAccountResponse(account))
+}
@@ -0,0 +1,172 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mockdb "github.com/T-BO0/bank/db/mock"
+	db "github.com/T-BO0/bank/db/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountStatusAPI(t *testing.T) {
+	user, _ := getRandomUser(t)
+	account := getRandomAccount(user.Username)
+
+	frozen := account
+	frozen.Status = db.AccountFrozen
+	frozen.StatusReason = sql.NullString{String: "suspected fraud", Valid: true}
+
+	//SECTION - Test cases
+	testCases := []struct {
+		name          string
+		path          string
+		operatorKey   string
+		body          map[string]interface{}
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "FreezeOK",
+			path:        "freeze",
+			operatorKey: testOperatorAPIKey,
+			body:        map[string]interface{}{"reason": "suspected fraud"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateAccountStatusTx(gomock.Any(), gomock.Eq(db.UpdateAccountStatusTxParams{
+						AccountID: account.ID,
+						Status:    db.AccountFrozen,
+						Reason:    "suspected fraud",
+					})).
+					Times(1).
+					Return(frozen, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response accountResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, db.AccountFrozen, response.Status)
+				require.Equal(t, "suspected fraud", response.StatusReason)
+			},
+		},
+		{
+			name:        "UnfreezeOK",
+			path:        "unfreeze",
+			operatorKey: testOperatorAPIKey,
+			body:        map[string]interface{}{"reason": "cleared"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateAccountStatusTx(gomock.Any(), gomock.Eq(db.UpdateAccountStatusTxParams{
+						AccountID: account.ID,
+						Status:    db.AccountActive,
+						Reason:    "cleared",
+					})).
+					Times(1).
+					Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:        "CloseNotEmpty",
+			path:        "close",
+			operatorKey: testOperatorAPIKey,
+			body:        map[string]interface{}{"reason": "customer request"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateAccountStatusTx(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, db.ErrAccountNotEmpty)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:        "InvalidTransition",
+			path:        "unfreeze",
+			operatorKey: testOperatorAPIKey,
+			body:        map[string]interface{}{"reason": "cleared"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateAccountStatusTx(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, db.ErrInvalidStatusTransition)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:        "AccountNotFound",
+			path:        "freeze",
+			operatorKey: testOperatorAPIKey,
+			body:        map[string]interface{}{"reason": "suspected fraud"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateAccountStatusTx(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, db.ErrAccountNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:        "NoReason",
+			path:        "freeze",
+			operatorKey: testOperatorAPIKey,
+			body:        map[string]interface{}{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateAccountStatusTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:        "NoOperatorKey",
+			path:        "close",
+			operatorKey: "",
+			body:        map[string]interface{}{"reason": "customer request"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateAccountStatusTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+	//!SECTION
+
+	//SECTION - Test RUN
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			jsonBody, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/accounts/%d/%s", account.ID, tc.path)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(jsonBody))
+			require.NoError(t, err)
+			request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			if tc.operatorKey != "" {
+				request.Header.Set(operatorKeyHeader, tc.operatorKey)
+			}
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+	//!SECTION
+}
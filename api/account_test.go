@@ -9,9 +9,11 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	mockdb "github.com/T-BO0/bank/db/mock"
 	db "github.com/T-BO0/bank/db/sqlc"
+	"github.com/T-BO0/bank/token"
 	"github.com/T-BO0/bank/util"
 	"github.com/golang/mock/gomock"
 	"github.com/labstack/echo/v4"
@@ -19,18 +21,23 @@ import (
 )
 
 func TestGetAccountAPI(t *testing.T) {
-	account := getRandomAccount()
+	user, _ := getRandomUser(t)
+	account := getRandomAccount(user.Username)
 
 	//SECTION - Test cases
 	testCases := []struct {
 		name          string
 		accountID     int64
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
 		buildStubs    func(store *mockdb.MockStore)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
 			name:      "OK",
 			accountID: account.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
 					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
@@ -45,6 +52,9 @@ func TestGetAccountAPI(t *testing.T) {
 		{
 			name:      "NotFound",
 			accountID: account.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
 					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
@@ -58,6 +68,9 @@ func TestGetAccountAPI(t *testing.T) {
 		{
 			name:      "InternalServerError",
 			accountID: account.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
 					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
@@ -68,9 +81,42 @@ func TestGetAccountAPI(t *testing.T) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
+		{
+			name:      "UnauthorizedUser",
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "unauthorized", time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "NoAuthorization",
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
 		{
 			name:      "InvalidId",
 			accountID: 0,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
 					GetAccount(gomock.Any(), gomock.Any()).
@@ -95,7 +141,7 @@ func TestGetAccountAPI(t *testing.T) {
 			store := mockdb.NewMockStore(ctrl)
 			tc.buildStubs(store)
 
-			server := NewServer(store)
+			server := newTestServer(t, store)
 			recorder := httptest.NewRecorder()
 
 			url := fmt.Sprintf("/accounts/%d", tc.accountID)
@@ -103,6 +149,7 @@ func TestGetAccountAPI(t *testing.T) {
 
 			require.NoError(t, err)
 
+			tc.setupAuth(t, request, server.tokenMaker)
 			server.router.ServeHTTP(recorder, request)
 			tc.checkResponse(t, recorder)
 		})
@@ -111,35 +158,80 @@ func TestGetAccountAPI(t *testing.T) {
 }
 
 func TestCreateAccount(t *testing.T) {
-	account := getRandomAccountZero()
+	user, _ := getRandomUser(t)
+	account := getRandomAccountZero(user.Username)
 
 	//SECTION - Test cases
 	testCases := []struct {
 		name          string
 		args          interface{}
 		appType       string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
 		buildStubs    func(store *mockdb.MockStore)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
 			name:    "OK",
 			appType: echo.MIMEApplicationJSON,
-			args:    db.CreateAccountParams{Owner: account.Owner, Balance: 0, Currency: account.Currency},
+			args:    createAccountRequest{Currency: account.Currency},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					CreateAccount(gomock.Any(), gomock.Eq(db.CreateAccountParams{Owner: account.Owner, Balance: 0, Currency: account.Currency})).
+					CreateAccount(gomock.Any(), gomock.Eq(db.CreateAccountParams{Owner: account.Owner, Balance: 0, Currency: account.Currency, Type: db.AccountChecking})).
 					Times(1).
-					Return(db.Account{ID: account.ID, Owner: account.Owner, Balance: 0, Currency: account.Currency}, nil)
+					Return(account, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
 				checkBody(t, recorder.Body, account)
 			},
 		},
+		{
+			name:    "SavingsOK",
+			appType: echo.MIMEApplicationJSON,
+			args:    createAccountRequest{Currency: account.Currency, Type: db.AccountSavings},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				savings := account
+				savings.Type = db.AccountSavings
+				store.EXPECT().
+					CreateAccount(gomock.Any(), gomock.Eq(db.CreateAccountParams{Owner: account.Owner, Balance: 0, Currency: account.Currency, Type: db.AccountSavings})).
+					Times(1).
+					Return(savings, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response accountResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, db.AccountSavings, response.Type)
+			},
+		},
+		{
+			name:    "InvalidType",
+			appType: echo.MIMEApplicationJSON,
+			args:    createAccountRequest{Currency: account.Currency, Type: "brokerage"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
 		{
 			name:    "BadRequest",
 			appType: echo.MIMEApplicationJSON,
-			args:    db.CreateAccountParams{Owner: account.Owner, Balance: 0, Currency: "FUT"},
+			args:    createAccountRequest{Currency: "FUT"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
 					CreateAccount(gomock.Any(), gomock.Any()).
@@ -154,6 +246,9 @@ func TestCreateAccount(t *testing.T) {
 			name:    "InvalidBind",
 			args:    nil,
 			appType: "superType",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
 					CreateAccount(gomock.Any(), gomock.Any()).
@@ -166,8 +261,11 @@ func TestCreateAccount(t *testing.T) {
 		},
 		{
 			name:    "InternalError",
-			args:    db.CreateAccountParams{Owner: account.Owner, Balance: 0, Currency: account.Currency},
+			args:    createAccountRequest{Currency: account.Currency},
 			appType: echo.MIMEApplicationJSON,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
 					CreateAccount(gomock.Any(), gomock.Any()).
@@ -192,7 +290,7 @@ func TestCreateAccount(t *testing.T) {
 			store := mockdb.NewMockStore(ctrl)
 			tc.buildStubs(store)
 
-			server := NewServer(store)
+			server := newTestServer(t, store)
 			recorder := httptest.NewRecorder()
 
 			url := "/accounts"
@@ -204,6 +302,7 @@ func TestCreateAccount(t *testing.T) {
 			request.Header.Set(echo.HeaderContentType, tc.appType)
 			require.NoError(t, err)
 
+			tc.setupAuth(t, request, server.tokenMaker)
 			server.router.ServeHTTP(recorder, request)
 			tc.checkResponse(t, recorder)
 		})
@@ -212,43 +311,99 @@ func TestCreateAccount(t *testing.T) {
 }
 
 func TestGetListOfAccount(t *testing.T) {
+	user, _ := getRandomUser(t)
+
 	var accounts []db.Account
 	for i := 0; i < 10; i++ {
-		accounts = append(accounts, getRandomAccount())
+		accounts = append(accounts, getRandomAccount(user.Username))
 	}
 
 	expectedAccounts := append(accounts, accounts[len(accounts)/2-1:]...)
+	cursor := pageCursor{CreatedAt: time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC), ID: 1}
 
 	//SECTION - TestCases
 	testCases := []struct {
 		name          string
 		url           string
 		appType       string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
 		buildStubs    func(store *mockdb.MockStore)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
 			name:    "OK",
-			url:     fmt.Sprintf("/accounts?size=%d&page=%d", 5, 2),
+			url:     fmt.Sprintf("/accounts?size=%d", 5),
+			appType: echo.MIMEApplicationJSON,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAccountByOwnerAfter(gomock.Any(), gomock.Eq(db.ListAccountByOwnerAfterParams{Owner: user.Username, LimitCount: 6})).
+					Times(1).
+					Return(expectedAccounts[:5], nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				nextCursor := checkArrayOfAccount(t, recorder.Body, expectedAccounts[:5])
+				require.Nil(t, nextCursor)
+			},
+		},
+		{
+			name:    "NextPage",
+			url:     fmt.Sprintf("/accounts?size=%d&cursor=%s", 5, cursor.encode()),
 			appType: echo.MIMEApplicationJSON,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListAccountByOwnerAfterParams{
+					Owner:          user.Username,
+					AfterCreatedAt: cursor.CreatedAt,
+					AfterID:        cursor.ID,
+					LimitCount:     6,
+				}
 				store.EXPECT().
-					ListAccount(gomock.Any(), gomock.Eq(db.ListAccountParams{Limit: 5, Offset: (2 - 1) * 5})).
+					ListAccountByOwnerAfter(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
-					Return(expectedAccounts, nil)
+					Return(expectedAccounts[:6], nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
-				checkArrayOfAccount(t, recorder.Body, expectedAccounts)
+				nextCursor := checkArrayOfAccount(t, recorder.Body, expectedAccounts[:5])
+				require.NotNil(t, nextCursor)
+
+				next, err := decodePageCursor(*nextCursor)
+				require.NoError(t, err)
+				require.Equal(t, expectedAccounts[4].ID, next.ID)
+			},
+		},
+		{
+			name:    "InvalidCursor",
+			url:     fmt.Sprintf("/accounts?size=%d&cursor=%s", 5, "bm90LWpzb24"),
+			appType: echo.MIMEApplicationJSON,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAccountByOwnerAfter(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
 		{
 			name:    "Validation",
-			url:     fmt.Sprintf("/accounts?size=%d&page=%d", 5, 0),
+			url:     fmt.Sprintf("/accounts?size=%d", 3),
 			appType: echo.MIMEApplicationJSON,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					ListAccount(gomock.Any(), gomock.Any()).
+					ListAccountByOwnerAfter(gomock.Any(), gomock.Any()).
 					Times(0).
 					Return([]db.Account{}, sql.ErrConnDone)
 			},
@@ -258,11 +413,14 @@ func TestGetListOfAccount(t *testing.T) {
 		},
 		{
 			name:    "BindError",
-			url:     fmt.Sprintf("/accounts?size=%d&page=%s", 5, "a"),
+			url:     fmt.Sprintf("/accounts?size=%s", "a"),
 			appType: echo.MIMEApplicationJSON,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					ListAccount(gomock.Any(), gomock.Any()).
+					ListAccountByOwnerAfter(gomock.Any(), gomock.Any()).
 					Times(0).
 					Return([]db.Account{}, sql.ErrConnDone)
 			},
@@ -272,11 +430,14 @@ func TestGetListOfAccount(t *testing.T) {
 		},
 		{
 			name:    "RecordNotFound",
-			url:     fmt.Sprintf("/accounts?size=%d&page=%d", 5, 100),
+			url:     fmt.Sprintf("/accounts?size=%d", 5),
 			appType: echo.MIMEApplicationJSON,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					ListAccount(gomock.Any(), gomock.Any()).
+					ListAccountByOwnerAfter(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(nil, sql.ErrNoRows)
 			},
@@ -286,11 +447,14 @@ func TestGetListOfAccount(t *testing.T) {
 		},
 		{
 			name:    "InternalError",
-			url:     fmt.Sprintf("/accounts?size=%d&page=%d", 5, 2),
+			url:     fmt.Sprintf("/accounts?size=%d", 5),
 			appType: echo.MIMEApplicationJSON,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					ListAccount(gomock.Any(), gomock.Any()).
+					ListAccountByOwnerAfter(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(nil, sql.ErrConnDone)
 			},
@@ -312,13 +476,14 @@ func TestGetListOfAccount(t *testing.T) {
 			store := mockdb.NewMockStore(ctrl)
 			tc.buildStubs(store)
 
-			server := NewServer(store)
+			server := newTestServer(t, store)
 			recorder := httptest.NewRecorder()
 
 			request, err := http.NewRequest(http.MethodGet, tc.url, nil)
 			request.Header.Set(echo.HeaderContentType, tc.appType)
 			require.NoError(t, err)
 
+			tc.setupAuth(t, request, server.tokenMaker)
 			server.router.ServeHTTP(recorder, request)
 			tc.checkResponse(t, recorder)
 		})
@@ -326,42 +491,50 @@ func TestGetListOfAccount(t *testing.T) {
 	//!SECTION
 }
 
-func getRandomAccount() db.Account {
+func getRandomAccount(owner string) db.Account {
+	balance := util.RandomMoney()
 	return db.Account{
-		ID:       int64(util.RandomFloat(1, 1000)),
-		Owner:    util.RandomString(6),
-		Balance:  util.RandomFloat(100, 1000),
-		Currency: util.RandomCurrency(),
+		ID:               util.RandomInt(1, 1000),
+		Owner:            owner,
+		Balance:          balance,
+		AvailableBalance: balance,
+		Currency:         util.RandomCurrency(),
+		Type:             db.AccountChecking,
+		Status:           db.AccountActive,
 	}
 }
 
-func getRandomAccountZero() db.Account {
+func getRandomAccountZero(owner string) db.Account {
 	return db.Account{
-		ID:       int64(util.RandomFloat(1, 1000)),
-		Owner:    util.RandomString(6),
+		ID:       util.RandomInt(1, 1000),
+		Owner:    owner,
 		Balance:  0,
 		Currency: util.RandomCurrency(),
+		Type:     db.AccountChecking,
+		Status:   db.AccountActive,
 	}
 }
 
 func checkBody(t *testing.T, body *bytes.Buffer, account db.Account) {
 	data, err := io.ReadAll(body)
 	require.NoError(t, err)
-	var gotAccount db.Account
+	var gotAccount accountResponse
 	err = json.Unmarshal(data, &gotAccount)
 	require.NoError(t, err)
-	require.Equal(t, gotAccount, account)
+	require.Equal(t, newAccountResponse(account), gotAccount)
 }
 
-func checkArrayOfAccount(t *testing.T, body *bytes.Buffer, accounts []db.Account) {
+func checkArrayOfAccount(t *testing.T, body *bytes.Buffer, accounts []db.Account) *string {
 	data, err := io.ReadAll(body)
 	require.NoError(t, err)
-	var listOfAccounts []db.Account
+	var page pageResponse[accountResponse]
 
-	err = json.Unmarshal(data, &listOfAccounts)
+	err = json.Unmarshal(data, &page)
 	require.NoError(t, err)
 
-	for i, v := range listOfAccounts {
-		require.Equal(t, v, accounts[i])
+	require.Len(t, page.Items, len(accounts))
+	for i, v := range page.Items {
+		require.Equal(t, newAccountResponse(accounts[i]), v)
 	}
+	return page.NextCursor
 }
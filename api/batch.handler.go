@@ -0,0 +1,137 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	db "github.com/T-BO0/bank/db/sqlc"
+	"github.com/T-BO0/bank/token"
+	"github.com/T-BO0/bank/util/money"
+	"github.com/labstack/echo/v4"
+)
+
+// batchTransferLegRequest is one payment of a batch, amount is a decimal string in the currency of the batch
+type batchTransferLegRequest struct {
+	ToAccountID int64  `json:"toAccountId" validate:"required,numeric,min=1"`
+	Amount      string `json:"amount" validate:"required"`
+}
+
+// batchTransferRequest pays every leg out of the from account, all to accounts must be in the batch currency
+// Without bestEffort one failing leg fails the whole batch. A batch has at most 1000 legs, they all run in one database transaction
+type batchTransferRequest struct {
+	FromAccountID int64                     `json:"fromAccountId" validate:"required,numeric,min=1"`
+	Currency      string                    `json:"currency" validate:"required,oneof=USD EUR GEL"`
+	BestEffort    bool                      `json:"bestEffort"`
+	Legs          []batchTransferLegRequest `json:"legs" validate:"required,min=1,max=1000,dive"`
+}
+
+// batchLegResponse is the outcome of one leg, transfer is only set on a succeeded leg and error only on a failed one
+type batchLegResponse struct {
+	Index       int               `json:"index"`
+	ToAccountID int64             `json:"to_account_id"`
+	Amount      string            `json:"amount"`
+	Status      string            `json:"status"`
+	Transfer    *transferResponse `json:"transfer,omitempty"`
+	Error       string            `json:"error,omitempty"`
+}
+
+// batchTransferResponse is respons returned to user from the batch transfer handler, also as body of a failed batch
+type batchTransferResponse struct {
+	Message   string             `json:"message,omitempty"`
+	Succeeded int                `json:"succeeded"`
+	Failed    int                `json:"failed"`
+	Legs      []batchLegResponse `json:"legs"`
+}
+
+// newBatchTransferResponse converts db batch transfer result to batchTransferResponse
+func newBatchTransferResponse(arg db.BatchTransferTxParams, result db.BatchTransferTxResult) batchTransferResponse {
+	response := batchTransferResponse{
+		Succeeded: result.Succeeded,
+		Failed:    result.Failed,
+		Legs:      make([]batchLegResponse, 0, len(result.Legs)),
+	}
+	for i, leg := range result.Legs {
+		legResponse := batchLegResponse{
+			Index:       leg.Index,
+			ToAccountID: arg.Legs[i].ToAccountID,
+			Amount:      money.Format(arg.Legs[i].Amount, arg.Legs[i].Currency),
+			Status:      leg.Status,
+		}
+		if leg.Transfer != nil {
+			transfer := newTransferResponse(leg.Transfer.Transfer)
+			legResponse.Transfer = &transfer
+		}
+		if leg.Err != nil {
+			legResponse.Error = leg.Err.Error()
+		}
+		response.Legs = append(response.Legs, legResponse)
+	}
+	return response
+}
+
+// ANCHOR - createBatchTransfer pays many accounts from one account in a single transaction route:POST: /transfers/batch
+// By default the batch is all or nothing and a failed batch answers 422 with the error of every failing leg,
+// with bestEffort the legs that can go through are kept and the failed ones are reported with 200
+func (server *Server) createBatchTransfer(c echo.Context) error {
+	req := batchTransferRequest{}
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+
+	fromAccount, err := server.store.GetAccount(c.Request().Context(), req.FromAccountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return echo.NewHTTPError(http.StatusNotFound, "from account not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	authPayload := c.Get(authorizationPayloadKey).(*token.Payload)
+	if fromAccount.Owner != authPayload.Username {
+		return echo.NewHTTPError(http.StatusForbidden, "from account doesn't belong to the authenticated user")
+	}
+
+	if fromAccount.Currency != req.Currency {
+		return echo.NewHTTPError(http.StatusBadRequest, "from account currency mismatch")
+	}
+
+	arg := db.BatchTransferTxParams{
+		Legs:       make([]db.BatchTransferLeg, 0, len(req.Legs)),
+		BestEffort: req.BestEffort,
+	}
+	for i, leg := range req.Legs {
+		if leg.ToAccountID == req.FromAccountID {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("leg %d: from and to account must be different", i))
+		}
+
+		amount, err := money.Parse(leg.Amount, req.Currency)
+		if err != nil || amount <= 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("leg %d: amount %s is not a valid positive %s amount", i, leg.Amount, req.Currency))
+		}
+
+		arg.Legs = append(arg.Legs, db.BatchTransferLeg{
+			FromAccountID: req.FromAccountID,
+			ToAccountID:   leg.ToAccountID,
+			Amount:        amount,
+			Currency:      req.Currency,
+		})
+	}
+
+	result, err := server.store.BatchTransferTx(c.Request().Context(), arg)
+	if errors.Is(err, db.ErrBatchFailed) {
+		response := newBatchTransferResponse(arg, result)
+		response.Message = err.Error()
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, response)
+	}
+	if err != nil {
+		return transferTxHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, newBatchTransferResponse(arg, result))
+}
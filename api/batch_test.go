@@ -0,0 +1,225 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/T-BO0/bank/db/mock"
+	db "github.com/T-BO0/bank/db/sqlc"
+	"github.com/T-BO0/bank/token"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateBatchTransferAPI(t *testing.T) {
+	user1, _ := getRandomUser(t)
+	user2, _ := getRandomUser(t)
+
+	account1 := getRandomAccount(user1.Username)
+	account1.ID, account1.Currency = 1, "USD"
+
+	legs := []map[string]interface{}{
+		{"toAccountId": 2, "amount": "100.00"},
+		{"toAccountId": 3, "amount": "250.50"},
+	}
+	arg := db.BatchTransferTxParams{
+		Legs: []db.BatchTransferLeg{
+			{FromAccountID: 1, ToAccountID: 2, Amount: 10000, Currency: "USD"},
+			{FromAccountID: 1, ToAccountID: 3, Amount: 25050, Currency: "USD"},
+		},
+	}
+	bestEffortArg := arg
+	bestEffortArg.BestEffort = true
+
+	//SECTION - Test cases
+	testCases := []struct {
+		name          string
+		body          map[string]interface{}
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: map[string]interface{}{"fromAccountId": 1, "currency": "USD", "legs": legs},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().
+					BatchTransferTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.BatchTransferTxResult{
+						Succeeded: 2,
+						Legs: []db.BatchLegResult{
+							{Index: 0, Status: db.BatchLegSucceeded, Transfer: &db.TransferTxResult{Transfer: db.Transfer{ID: 10, Amount: 10000, Currency: "USD"}}},
+							{Index: 1, Status: db.BatchLegSucceeded, Transfer: &db.TransferTxResult{Transfer: db.Transfer{ID: 11, Amount: 25050, Currency: "USD"}}},
+						},
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response batchTransferResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, 2, response.Succeeded)
+				require.Len(t, response.Legs, 2)
+				require.Equal(t, int64(11), response.Legs[1].Transfer.ID)
+				require.Equal(t, "250.50", response.Legs[1].Amount)
+			},
+		},
+		{
+			name: "BatchFailed",
+			body: map[string]interface{}{"fromAccountId": 1, "currency": "USD", "legs": legs},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().
+					BatchTransferTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.BatchTransferTxResult{
+						Failed: 1,
+						Legs: []db.BatchLegResult{
+							{Index: 0, Status: db.BatchLegRolledBack},
+							{Index: 1, Status: db.BatchLegFailed, Err: db.ErrInsufficientFunds},
+						},
+					}, db.ErrBatchFailed)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+				var response batchTransferResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, db.BatchLegRolledBack, response.Legs[0].Status)
+				require.Equal(t, db.BatchLegFailed, response.Legs[1].Status)
+				require.Equal(t, db.ErrInsufficientFunds.Error(), response.Legs[1].Error)
+			},
+		},
+		{
+			name: "BestEffort",
+			body: map[string]interface{}{"fromAccountId": 1, "currency": "USD", "bestEffort": true, "legs": legs},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().
+					BatchTransferTx(gomock.Any(), gomock.Eq(bestEffortArg)).
+					Times(1).
+					Return(db.BatchTransferTxResult{
+						Succeeded: 1,
+						Failed:    1,
+						Legs: []db.BatchLegResult{
+							{Index: 0, Status: db.BatchLegSucceeded, Transfer: &db.TransferTxResult{Transfer: db.Transfer{ID: 10, Amount: 10000, Currency: "USD"}}},
+							{Index: 1, Status: db.BatchLegFailed, Err: db.ErrAccountNotFound},
+						},
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response batchTransferResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, 1, response.Succeeded)
+				require.Equal(t, 1, response.Failed)
+			},
+		},
+		{
+			name: "UnauthorizedUser",
+			body: map[string]interface{}{"fromAccountId": 1, "currency": "USD", "legs": legs},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user2.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "LegToSourceAccount",
+			body: map[string]interface{}{"fromAccountId": 1, "currency": "USD", "legs": []map[string]interface{}{
+				{"toAccountId": 1, "amount": "1.00"},
+			}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidLegAmount",
+			body: map[string]interface{}{"fromAccountId": 1, "currency": "USD", "legs": []map[string]interface{}{
+				{"toAccountId": 2, "amount": "1.001"},
+			}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NoLegs",
+			body: map[string]interface{}{"fromAccountId": 1, "currency": "USD", "legs": []map[string]interface{}{}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+	//!SECTION
+
+	//SECTION - Test RUN
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			jsonBody, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/transfers/batch", bytes.NewBuffer(jsonBody))
+			require.NoError(t, err)
+			request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+	//!SECTION
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	db "github.com/T-BO0/bank/db/sqlc"
+	"github.com/T-BO0/bank/util/money"
+	"github.com/labstack/echo/v4"
+)
+
+// cashRequest is request json body of deposit and withdrawal handlers
+type cashRequest struct {
+	Amount   string `json:"amount" validate:"required"`
+	Currency string `json:"currency" validate:"required,oneof=USD EUR GEL"`
+}
+
+// cashTxResponse is respons returned to operator from deposit and withdrawal handlers
+type cashTxResponse struct {
+	Transfer transferResponse `json:"transfer"`
+	Account  accountResponse  `json:"account"`
+	Entry    entryResponse    `json:"entry"`
+}
+
+// newCashTxResponse converts db cash tx result to cashTxResponse
+func newCashTxResponse(result db.CashTxResult) cashTxResponse {
+	return cashTxResponse{
+		Transfer: newTransferResponse(result.Transfer),
+		Account:  newAccountResponse(result.Account),
+		Entry:    newEntryResponse(result.Entry, result.Account.Currency),
+	}
+}
+
+// ANCHOR - createDeposit credits money to an account from the bank's cash account route:POST: /accounts/:id/deposits
+func (server *Server) createDeposit(c echo.Context) error {
+	return server.cashTx(c, server.store.DepositTx)
+}
+
+// ANCHOR - createWithdrawal debits money from an account to the bank's cash account route:POST: /accounts/:id/withdrawals
+func (server *Server) createWithdrawal(c echo.Context) error {
+	return server.cashTx(c, server.store.WithdrawTx)
+}
+
+// cashTx validates a deposit or withdrawal request and runs it with txFn
+func (server *Server) cashTx(c echo.Context, txFn func(context.Context, db.CashTxParams) (db.CashTxResult, error)) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+
+	req := cashRequest{}
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+
+	amount, err := money.Parse(req.Amount, req.Currency)
+	if err != nil || amount <= 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("amount %s is not a valid positive %s amount", req.Amount, req.Currency))
+	}
+
+	account, err := server.store.GetAccount(c.Request().Context(), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return echo.NewHTTPError(http.StatusNotFound, "account not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	if db.IsInternalAccount(account) {
+		return echo.NewHTTPError(http.StatusBadRequest, "can not deposit to or withdraw from an internal account")
+	}
+	if account.Currency != req.Currency {
+		return echo.NewHTTPError(http.StatusBadRequest, "account currency mismatch")
+	}
+
+	result, err := txFn(c.Request().Context(), db.CashTxParams{
+		AccountID: id,
+		Amount:    amount,
+		Currency:  req.Currency,
+	})
+	if err != nil {
+		return transferTxHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, newCashTxResponse(result))
+}
@@ -0,0 +1,166 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	db "github.com/T-BO0/bank/db/sqlc"
+	"github.com/T-BO0/bank/token"
+	"github.com/T-BO0/bank/util/money"
+	"github.com/labstack/echo/v4"
+)
+
+// Directions of an entry, a debit takes money out of the account and a credit puts it in
+const (
+	entryDebit  = "debit"
+	entryCredit = "credit"
+)
+
+// bankDateLayout is the layout of the days the history of an account is filtered by
+const bankDateLayout = "2006-01-02"
+
+// listAccountEntriesRequest filters the entries of an account, from and to are days on the bank's clock and both inclusive
+type listAccountEntriesRequest struct {
+	Limit     int32  `query:"limit" validate:"required,numeric,min=1,max=100"`
+	Cursor    string `query:"cursor"`
+	From      string `query:"from" validate:"omitempty,datetime=2006-01-02"`
+	To        string `query:"to" validate:"omitempty,datetime=2006-01-02"`
+	Direction string `query:"direction" validate:"omitempty,oneof=debit credit"`
+}
+
+// counterpartyResponse is the other account of the transfer an entry belongs to
+type counterpartyResponse struct {
+	AccountID int64  `json:"account_id"`
+	Owner     string `json:"owner"`
+}
+
+// accountEntryResponse is one row of the history of an account
+// Amount and BalanceAfter are formatted in the currency of the account, BalanceAfter is the balance right after the entry
+type accountEntryResponse struct {
+	ID           int64                 `json:"id"`
+	Direction    string                `json:"direction"`
+	Amount       string                `json:"amount"`
+	BalanceAfter string                `json:"balance_after"`
+	CreatedAt    time.Time             `json:"created_at"`
+	TransferID   *int64                `json:"transfer_id,omitempty"`
+	Description  string                `json:"description,omitempty"`
+	Reference    string                `json:"reference,omitempty"`
+	Counterparty *counterpartyResponse `json:"counterparty,omitempty"`
+}
+
+// newAccountEntryResponse converts a db account entry to accountEntryResponse
+func newAccountEntryResponse(entry db.ListAccountEntriesRow, currency string) accountEntryResponse {
+	response := accountEntryResponse{
+		ID:           entry.ID,
+		Direction:    entryCredit,
+		Amount:       money.Format(entry.Amount, currency),
+		BalanceAfter: money.Format(entry.BalanceAfter, currency),
+		CreatedAt:    entry.CreatedAt,
+		Description:  entry.Description.String,
+		Reference:    entry.Reference.String,
+	}
+	if entry.Amount < 0 {
+		response.Direction = entryDebit
+	}
+	if entry.TransferID.Valid {
+		response.TransferID = &entry.TransferID.Int64
+	}
+	if entry.CounterpartyAccountID.Valid {
+		response.Counterparty = &counterpartyResponse{
+			AccountID: entry.CounterpartyAccountID.Int64,
+			Owner:     entry.CounterpartyOwner.String,
+		}
+	}
+	return response
+}
+
+// parseBankDateRange turns the inclusive from and to days into the half open range of timestamps they cover
+// Timestamps are recorded on the bank's clock, so a bank day is compared to them as it is
+func parseBankDateRange(from string, to string) (sql.NullTime, sql.NullTime, error) {
+	var fromTime, toTime sql.NullTime
+	if from != "" {
+		t, err := time.Parse(bankDateLayout, from)
+		if err != nil {
+			return fromTime, toTime, echo.NewHTTPError(http.StatusBadRequest, "from must be a date like 2006-01-02")
+		}
+		fromTime = sql.NullTime{Time: t, Valid: true}
+	}
+	if to != "" {
+		t, err := time.Parse(bankDateLayout, to)
+		if err != nil {
+			return fromTime, toTime, echo.NewHTTPError(http.StatusBadRequest, "to must be a date like 2006-01-02")
+		}
+		toTime = sql.NullTime{Time: t.AddDate(0, 0, 1), Valid: true}
+	}
+	if fromTime.Valid && toTime.Valid && !fromTime.Time.Before(toTime.Time) {
+		return fromTime, toTime, echo.NewHTTPError(http.StatusBadRequest, "from must not be after to")
+	}
+	return fromTime, toTime, nil
+}
+
+// ANCHOR - listAccountEntries lists a page of the history of an account in chronological order route:GET: /accounts/:id/entries
+// Each entry carries the balance right after it and the transfer and counterparty it came from,
+// the next page is read with the next_cursor of the response
+func (server *Server) listAccountEntries(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+
+	req := listAccountEntriesRequest{}
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+
+	fromTime, toTime, err := parseBankDateRange(req.From, req.To)
+	if err != nil {
+		return err
+	}
+
+	cursor, err := decodePageCursor(req.Cursor)
+	if err != nil {
+		return err
+	}
+
+	account, err := server.store.GetAccount(c.Request().Context(), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return echo.NewHTTPError(http.StatusNotFound, "account not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	authPayload := c.Get(authorizationPayloadKey).(*token.Payload)
+	if account.Owner != authPayload.Username {
+		return echo.NewHTTPError(http.StatusForbidden, "account doesn't belong to the authenticated user")
+	}
+
+	entries, err := server.store.ListAccountEntries(c.Request().Context(), db.ListAccountEntriesParams{
+		AccountID:      account.ID,
+		FromTime:       fromTime,
+		ToTime:         toTime,
+		Direction:      sql.NullString{String: req.Direction, Valid: req.Direction != ""},
+		AfterCreatedAt: cursor.CreatedAt,
+		AfterID:        cursor.ID,
+		LimitCount:     req.Limit + 1,
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, newPageResponse(entries, req.Limit, accountEntryCursor,
+		func(entry db.ListAccountEntriesRow) accountEntryResponse {
+			return newAccountEntryResponse(entry, account.Currency)
+		}))
+}
+
+// accountEntryCursor is the page cursor of an entry
+func accountEntryCursor(entry db.ListAccountEntriesRow) pageCursor {
+	return pageCursor{CreatedAt: entry.CreatedAt, ID: entry.ID}
+}
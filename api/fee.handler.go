@@ -0,0 +1,80 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	db "github.com/T-BO0/bank/db/sqlc"
+	"github.com/T-BO0/bank/util/money"
+	"github.com/labstack/echo/v4"
+)
+
+// quoteTransferRequest takes the same fields as createTransferRequest as query params
+type quoteTransferRequest struct {
+	FromAccountID int64  `query:"fromAccountId" validate:"required,numeric,min=1"`
+	ToAccountID   int64  `query:"toAccountId" validate:"required,numeric,min=1"`
+	Amount        string `query:"amount" validate:"required"`
+	Currency      string `query:"currency" validate:"required,oneof=USD EUR GEL"`
+}
+
+// transferQuoteResponse previews a transfer, Total is what leaves the from account
+type transferQuoteResponse struct {
+	Amount     string `json:"amount"`
+	Currency   string `json:"currency"`
+	Fee        string `json:"fee"`
+	Total      string `json:"total"`
+	ToAmount   string `json:"to_amount"`
+	ToCurrency string `json:"to_currency"`
+	FxRate     string `json:"fx_rate"`
+}
+
+// ANCHOR - quoteTransfer previews the fee and the credited amount of a transfer route:GET: /transfers/quote
+// Nothing is reserved, the rate of a cross-currency quote is the current one and may change before the transfer
+func (server *Server) quoteTransfer(c echo.Context) error {
+	req := quoteTransferRequest{}
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+
+	fromAccount, toAccount, err := server.validateTransferRequest(c, createTransferRequest{
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Currency:      req.Currency,
+	})
+	if err != nil {
+		return err
+	}
+
+	amount, err := money.Parse(req.Amount, req.Currency)
+	if err != nil || amount <= 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("amount %s is not a valid positive %s amount", req.Amount, req.Currency))
+	}
+
+	toAmount, rate := amount, "1"
+	if toAccount.Currency != req.Currency {
+		rate, err = server.rateProvider.Rate(c.Request().Context(), req.Currency, toAccount.Currency)
+		if err != nil {
+			return transferTxHTTPError(err)
+		}
+		toAmount, err = money.Convert(amount, req.Currency, toAccount.Currency, rate)
+		if err != nil {
+			return transferTxHTTPError(err)
+		}
+	}
+
+	fee := db.QuoteFee(fromAccount, amount)
+	return c.JSON(http.StatusOK, transferQuoteResponse{
+		Amount:     money.Format(amount, req.Currency),
+		Currency:   req.Currency,
+		Fee:        money.Format(fee, req.Currency),
+		Total:      money.Format(amount+fee, req.Currency),
+		ToAmount:   money.Format(toAmount, toAccount.Currency),
+		ToCurrency: toAccount.Currency,
+		FxRate:     rate,
+	})
+}
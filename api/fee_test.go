@@ -0,0 +1,188 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/T-BO0/bank/db/mock"
+	db "github.com/T-BO0/bank/db/sqlc"
+	"github.com/T-BO0/bank/token"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuoteTransferAPI(t *testing.T) {
+	user1, _ := getRandomUser(t)
+	user2, _ := getRandomUser(t)
+
+	account1 := getRandomAccount(user1.Username)
+	account2 := getRandomAccount(user2.Username)
+	account3 := getRandomAccount(user2.Username)
+	account1.ID, account2.ID, account3.ID = 1, 2, 3
+	account1.Currency, account2.Currency, account3.Currency = "USD", "USD", "GEL"
+	overdraftAccount := account1
+	overdraftAccount.Type = db.AccountOverdraft
+
+	//SECTION - Test cases
+	testCases := []struct {
+		name          string
+		query         string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: fmt.Sprintf("fromAccountId=%d&toAccountId=%d&amount=10.50&currency=USD", account1.ID, account2.ID),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, transferQuoteResponse{
+					Amount:     "10.50",
+					Currency:   "USD",
+					Fee:        "0.00",
+					Total:      "10.50",
+					ToAmount:   "10.50",
+					ToCurrency: "USD",
+					FxRate:     "1",
+				}, decodeQuote(t, recorder))
+			},
+		},
+		{
+			name:  "OverdraftMinimumFee",
+			query: fmt.Sprintf("fromAccountId=%d&toAccountId=%d&amount=10.50&currency=USD", account1.ID, account2.ID),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(overdraftAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				quote := decodeQuote(t, recorder)
+				require.Equal(t, "0.50", quote.Fee)
+				require.Equal(t, "11.00", quote.Total)
+			},
+		},
+		{
+			name:  "CrossCurrency",
+			query: fmt.Sprintf("fromAccountId=%d&toAccountId=%d&amount=10.00&currency=USD", account1.ID, account3.ID),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
+				store.EXPECT().
+					GetFxRate(gomock.Any(), gomock.Eq(db.GetFxRateParams{BaseCurrency: "USD", QuoteCurrency: "GEL"})).
+					Times(1).
+					Return(db.FxRate{BaseCurrency: "USD", QuoteCurrency: "GEL", Rate: "2.7000000000"}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				quote := decodeQuote(t, recorder)
+				require.Equal(t, "27.00", quote.ToAmount)
+				require.Equal(t, "GEL", quote.ToCurrency)
+			},
+		},
+		{
+			name:  "RateNotFound",
+			query: fmt.Sprintf("fromAccountId=%d&toAccountId=%d&amount=10.00&currency=USD", account1.ID, account3.ID),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
+				store.EXPECT().GetFxRate(gomock.Any(), gomock.Any()).Times(2).Return(db.FxRate{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:  "UnauthorizedUser",
+			query: fmt.Sprintf("fromAccountId=%d&toAccountId=%d&amount=10.50&currency=USD", account1.ID, account2.ID),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user2.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidAmount",
+			query: fmt.Sprintf("fromAccountId=%d&toAccountId=%d&amount=10.505&currency=USD", account1.ID, account2.ID),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "MissingCurrency",
+			query: fmt.Sprintf("fromAccountId=%d&toAccountId=%d&amount=10.50", account1.ID, account2.ID),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+	//!SECTION
+
+	//SECTION - Test RUN
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/transfers/quote?"+tc.query, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+	//!SECTION
+}
+
+func decodeQuote(t *testing.T, recorder *httptest.ResponseRecorder) transferQuoteResponse {
+	var quote transferQuoteResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &quote))
+	return quote
+}
@@ -0,0 +1,217 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	db "github.com/T-BO0/bank/db/sqlc"
+	"github.com/T-BO0/bank/token"
+	"github.com/T-BO0/bank/util/money"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// defaultHoldExpiry is how long an authorization reserves the money when the request doesn't say
+	defaultHoldExpiry = 7 * 24 * time.Hour
+	maxHoldExpiry     = 30 * 24 * time.Hour
+)
+
+// authorizeTransferRequest takes amount as a decimal string like "12.34" in the currency of both accounts
+// Without expiresAt the hold expires after defaultHoldExpiry
+type authorizeTransferRequest struct {
+	FromAccountID int64      `json:"fromAccountId" validate:"required,numeric,min=1"`
+	ToAccountID   int64      `json:"toAccountId" validate:"required,numeric,min=1"`
+	Amount        string     `json:"amount" validate:"required"`
+	Currency      string     `json:"currency" validate:"required,oneof=USD EUR GEL"`
+	ExpiresAt     *time.Time `json:"expiresAt"`
+}
+
+// captureTransferRequest takes an optional decimal amount, without it the whole hold is captured
+type captureTransferRequest struct {
+	Amount string `json:"amount"`
+}
+
+// holdResponse is hold returned to user with amounts formatted as decimal strings
+type holdResponse struct {
+	ID             int64     `json:"id"`
+	FromAccountID  int64     `json:"from_account_id"`
+	ToAccountID    int64     `json:"to_account_id"`
+	Amount         string    `json:"amount"`
+	Currency       string    `json:"currency"`
+	Status         string    `json:"status"`
+	CapturedAmount string    `json:"captured_amount"`
+	TransferID     *int64    `json:"transfer_id,omitempty"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// newHoldResponse converts db hold to holdResponse
+func newHoldResponse(hold db.Hold) holdResponse {
+	response := holdResponse{
+		ID:             hold.ID,
+		FromAccountID:  hold.FromAccountID,
+		ToAccountID:    hold.ToAccountID,
+		Amount:         money.Format(hold.Amount, hold.Currency),
+		Currency:       hold.Currency,
+		Status:         hold.Status,
+		CapturedAmount: money.Format(hold.CapturedAmount, hold.Currency),
+		ExpiresAt:      hold.ExpiresAt,
+		CreatedAt:      hold.CreatedAt,
+	}
+	if hold.TransferID.Valid {
+		response.TransferID = &hold.TransferID.Int64
+	}
+	return response
+}
+
+// holdTxResponse is respons returned to user from the authorize, capture and void handlers
+type holdTxResponse struct {
+	Hold        holdResponse        `json:"hold"`
+	FromAccount accountResponse     `json:"fromAccount"`
+	Transfer    *transferTxResponse `json:"transfer,omitempty"`
+}
+
+// newHoldTxResponse converts db hold tx result to holdTxResponse
+func newHoldTxResponse(result db.HoldTxResult) holdTxResponse {
+	response := holdTxResponse{
+		Hold:        newHoldResponse(result.Hold),
+		FromAccount: newAccountResponse(result.FromAccount),
+	}
+	if result.Transfer != nil {
+		transfer := newTransferTxResponse(*result.Transfer)
+		response.Transfer = &transfer
+	}
+	return response
+}
+
+// ANCHOR - authorizeTransfer reserves money on the from account without moving it route:POST: /transfers/authorize
+// The returned hold id is the id to capture or void, both accounts must be in the currency of the authorization
+func (server *Server) authorizeTransfer(c echo.Context) error {
+	req := authorizeTransferRequest{}
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	expiresAt := now.Add(defaultHoldExpiry)
+	if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(now) || req.ExpiresAt.After(now.Add(maxHoldExpiry)) {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("expiresAt must be in the next %s", maxHoldExpiry))
+		}
+		expiresAt = req.ExpiresAt.UTC()
+	}
+
+	_, toAccount, err := server.validateTransferRequest(c, createTransferRequest{
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Currency:      req.Currency,
+	})
+	if err != nil {
+		return err
+	}
+
+	if toAccount.Currency != req.Currency {
+		return echo.NewHTTPError(http.StatusBadRequest, "to account currency mismatch")
+	}
+
+	amount, err := money.Parse(req.Amount, req.Currency)
+	if err != nil || amount <= 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("amount %s is not a valid positive %s amount", req.Amount, req.Currency))
+	}
+
+	result, err := server.store.AuthorizeTx(c.Request().Context(), db.AuthorizeTxParams{
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        amount,
+		Currency:      req.Currency,
+		ExpiresAt:     expiresAt,
+	})
+	if err != nil {
+		return transferTxHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, newHoldTxResponse(result))
+}
+
+// ANCHOR - captureTransfer books an authorized hold as a transfer route:POST: /transfers/:id/capture
+// The id is the hold id, a capture without amount takes the whole hold and a smaller one releases the rest
+func (server *Server) captureTransfer(c echo.Context) error {
+	req := captureTransferRequest{}
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	hold, err := server.getOwnHold(c)
+	if err != nil {
+		return err
+	}
+
+	var amount int64
+	if req.Amount != "" {
+		amount, err = money.Parse(req.Amount, hold.Currency)
+		if err != nil || amount <= 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("amount %s is not a valid positive %s amount", req.Amount, hold.Currency))
+		}
+	}
+
+	result, err := server.store.CaptureTx(c.Request().Context(), db.CaptureTxParams{
+		HoldID: hold.ID,
+		Amount: amount,
+	})
+	if err != nil {
+		return transferTxHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, newHoldTxResponse(result))
+}
+
+// ANCHOR - voidTransfer cancels an authorized hold and releases the money route:POST: /transfers/:id/void
+func (server *Server) voidTransfer(c echo.Context) error {
+	hold, err := server.getOwnHold(c)
+	if err != nil {
+		return err
+	}
+
+	result, err := server.store.VoidTx(c.Request().Context(), hold.ID)
+	if err != nil {
+		return transferTxHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, newHoldTxResponse(result))
+}
+
+// getOwnHold loads the hold of the id path param if the authenticated user owns its from or to account
+func (server *Server) getOwnHold(c echo.Context) (db.Hold, error) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return db.Hold{}, echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+
+	hold, err := server.store.GetHold(c.Request().Context(), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return db.Hold{}, echo.NewHTTPError(http.StatusNotFound, "hold not found")
+		}
+		return db.Hold{}, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	authPayload := c.Get(authorizationPayloadKey).(*token.Payload)
+	for _, accountID := range []int64{hold.FromAccountID, hold.ToAccountID} {
+		account, err := server.store.GetAccount(c.Request().Context(), accountID)
+		if err != nil {
+			return db.Hold{}, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		if account.Owner == authPayload.Username {
+			return hold, nil
+		}
+	}
+	return db.Hold{}, echo.NewHTTPError(http.StatusForbidden, "hold doesn't belong to the authenticated user")
+}
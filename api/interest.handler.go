@@ -0,0 +1,68 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+
+	db "github.com/T-BO0/bank/db/sqlc"
+	"github.com/labstack/echo/v4"
+)
+
+// maxInterestRateDecimals is the scale of the interest_rate column
+const maxInterestRateDecimals = 6
+
+// setInterestRateRequest takes the annual rate as a decimal fraction, "0.025" is 2.5%
+type setInterestRateRequest struct {
+	Rate string `json:"rate" validate:"required"`
+}
+
+// ANCHOR - setInterestRate sets the annual interest rate of an account route:PUT: /accounts/:id/interest-rate
+// The new rate applies from the next day accrued, days already accrued keep the rate they were accrued with
+func (server *Server) setInterestRate(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+
+	req := setInterestRateRequest{}
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+
+	if !isValidInterestRate(req.Rate) {
+		return echo.NewHTTPError(http.StatusBadRequest,
+			fmt.Sprintf("rate %s must be a fraction from 0 to below 1 with at most %d decimals", req.Rate, maxInterestRateDecimals))
+	}
+
+	account, err := server.store.UpdateAccountInterestRate(c.Request().Context(), db.UpdateAccountInterestRateParams{
+		ID:           id,
+		InterestRate: req.Rate,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return echo.NewHTTPError(http.StatusNotFound, "account not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, newAccountResponse(account))
+}
+
+// isValidInterestRate reports whether rate is a plain decimal in [0, 1) that fits the interest_rate column
+func isValidInterestRate(rate string) bool {
+	whole, fraction, _ := strings.Cut(rate, ".")
+	if whole == "" || len(fraction) > maxInterestRateDecimals || strings.ContainsAny(rate, "+-eE/") {
+		return false
+	}
+
+	value, ok := new(big.Rat).SetString(rate)
+	return ok && value.Sign() >= 0 && value.Cmp(big.NewRat(1, 1)) < 0
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mockdb "github.com/T-BO0/bank/db/mock"
+	db "github.com/T-BO0/bank/db/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetInterestRateAPI(t *testing.T) {
+	user, _ := getRandomUser(t)
+	account := getRandomAccount(user.Username)
+
+	updated := account
+	updated.InterestRate = "0.025000"
+
+	//SECTION - Test cases
+	testCases := []struct {
+		name          string
+		operatorKey   string
+		body          map[string]interface{}
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "OK",
+			operatorKey: testOperatorAPIKey,
+			body:        map[string]interface{}{"rate": "0.025"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateAccountInterestRate(gomock.Any(), gomock.Eq(db.UpdateAccountInterestRateParams{ID: account.ID, InterestRate: "0.025"})).
+					Times(1).
+					Return(updated, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response accountResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, "0.025000", response.InterestRate)
+			},
+		},
+		{
+			name:        "RateTooHigh",
+			operatorKey: testOperatorAPIKey,
+			body:        map[string]interface{}{"rate": "1.5"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateAccountInterestRate(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:        "TooManyDecimals",
+			operatorKey: testOperatorAPIKey,
+			body:        map[string]interface{}{"rate": "0.0000001"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateAccountInterestRate(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:        "Negative",
+			operatorKey: testOperatorAPIKey,
+			body:        map[string]interface{}{"rate": "-0.01"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateAccountInterestRate(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:        "AccountNotFound",
+			operatorKey: testOperatorAPIKey,
+			body:        map[string]interface{}{"rate": "0.01"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateAccountInterestRate(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:        "NoOperatorKey",
+			operatorKey: "",
+			body:        map[string]interface{}{"rate": "0.01"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateAccountInterestRate(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+	//!SECTION
+
+	//SECTION - Test RUN
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			jsonBody, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/accounts/%d/interest-rate", account.ID)
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewBuffer(jsonBody))
+			require.NoError(t, err)
+			request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			if tc.operatorKey != "" {
+				request.Header.Set(operatorKeyHeader, tc.operatorKey)
+			}
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+	//!SECTION
+}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	db "github.com/T-BO0/bank/db/sqlc"
+	"github.com/labstack/echo/v4"
+)
+
+// ANCHOR - verifyLedger walks the hash chain of an account's entries route:GET: /accounts/:id/ledger/verification
+// A broken chain is still a 200, the body reports the first entry that does not check out
+func (server *Server) verifyLedger(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+
+	verification, err := server.store.VerifyLedger(c.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, db.ErrAccountNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, verification)
+}
+
+// ANCHOR - reconcile checks all balances and transfers against the entries route:GET: /reconciliation
+// Like the verification, findings are reported in the body of a 200
+func (server *Server) reconcile(c echo.Context) error {
+	report, err := server.store.Reconcile(c.Request().Context())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
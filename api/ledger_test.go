@@ -0,0 +1,206 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mockdb "github.com/T-BO0/bank/db/mock"
+	db "github.com/T-BO0/bank/db/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyLedgerAPI(t *testing.T) {
+	user, _ := getRandomUser(t)
+	account := getRandomAccount(user.Username)
+
+	//SECTION - Test cases
+	testCases := []struct {
+		name          string
+		accountID     int64
+		operatorKey   string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "Valid",
+			accountID:   account.ID,
+			operatorKey: testOperatorAPIKey,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					VerifyLedger(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(db.LedgerVerification{AccountID: account.ID, EntriesChecked: 4, Valid: true}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var verification db.LedgerVerification
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &verification))
+				require.True(t, verification.Valid)
+				require.Equal(t, int64(4), verification.EntriesChecked)
+			},
+		},
+		{
+			name:        "Broken",
+			accountID:   account.ID,
+			operatorKey: testOperatorAPIKey,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					VerifyLedger(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(db.LedgerVerification{AccountID: account.ID, EntriesChecked: 2, BrokenEntryID: 7, Reason: "hash does not match the entry or its transfer"}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var verification db.LedgerVerification
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &verification))
+				require.False(t, verification.Valid)
+				require.Equal(t, int64(7), verification.BrokenEntryID)
+			},
+		},
+		{
+			name:        "AccountNotFound",
+			accountID:   account.ID,
+			operatorKey: testOperatorAPIKey,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().VerifyLedger(gomock.Any(), gomock.Any()).Times(1).Return(db.LedgerVerification{}, db.ErrAccountNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:        "InvalidID",
+			accountID:   0,
+			operatorKey: testOperatorAPIKey,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().VerifyLedger(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:        "NoOperatorKey",
+			accountID:   account.ID,
+			operatorKey: "",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().VerifyLedger(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+	//!SECTION
+
+	//SECTION - Test RUN
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/ledger/verification", tc.accountID)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+			if tc.operatorKey != "" {
+				request.Header.Set(operatorKeyHeader, tc.operatorKey)
+			}
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+	//!SECTION
+}
+
+func TestReconcileAPI(t *testing.T) {
+	//SECTION - Test cases
+	testCases := []struct {
+		name          string
+		operatorKey   string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "OK",
+			operatorKey: testOperatorAPIKey,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Reconcile(gomock.Any()).Times(1).Return(db.ReconciliationReport{
+					AccountsChecked:   3,
+					BalanceMismatches: []db.ListBalanceMismatchesRow{{ID: 1, Balance: 100, EntriesTotal: 0}},
+				}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var report db.ReconciliationReport
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &report))
+				require.False(t, report.OK)
+				require.Equal(t, int64(3), report.AccountsChecked)
+				require.Len(t, report.BalanceMismatches, 1)
+			},
+		},
+		{
+			name:        "InternalError",
+			operatorKey: testOperatorAPIKey,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Reconcile(gomock.Any()).Times(1).Return(db.ReconciliationReport{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:        "NoOperatorKey",
+			operatorKey: "",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Reconcile(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+	//!SECTION
+
+	//SECTION - Test RUN
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/reconciliation", nil)
+			require.NoError(t, err)
+			if tc.operatorKey != "" {
+				request.Header.Set(operatorKeyHeader, tc.operatorKey)
+			}
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+	//!SECTION
+}
@@ -0,0 +1,25 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/T-BO0/bank/db/dbx"
+	"github.com/T-BO0/bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+const testOperatorAPIKey = "test-operator-key"
+
+func newTestServer(t *testing.T, store dbx.Store) *Server {
+	config := util.Config{
+		TokenSymmetricKey:   util.RandomString(32),
+		AccessTokenDuration: time.Minute,
+		OperatorAPIKey:      testOperatorAPIKey,
+	}
+
+	server, err := NewServer(config, store)
+	require.NoError(t, err)
+
+	return server
+}
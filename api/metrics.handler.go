@@ -0,0 +1,12 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ANCHOR - getMetrics returns the transaction and retry counters of the store route:GET: /metrics
+func (server *Server) getMetrics(c echo.Context) error {
+	return c.JSON(http.StatusOK, server.store.TxStats())
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/T-BO0/bank/token"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	authorizationHeaderKey  = "authorization"
+	authorizationTypeBearer = "bearer"
+	authorizationPayloadKey = "authorization_payload"
+	operatorKeyHeader       = "X-Operator-Key"
+)
+
+// authMiddleware rejects requests without a valid bearer token and stores its payload in the context
+func authMiddleware(tokenMaker token.Maker) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			authorizationHeader := c.Request().Header.Get(authorizationHeaderKey)
+			if len(authorizationHeader) == 0 {
+				return echo.NewHTTPError(http.StatusUnauthorized, "authorization header is not provided")
+			}
+
+			fields := strings.Fields(authorizationHeader)
+			if len(fields) < 2 {
+				return echo.NewHTTPError(http.StatusUnauthorized, "invalid authorization header format")
+			}
+
+			authorizationType := strings.ToLower(fields[0])
+			if authorizationType != authorizationTypeBearer {
+				return echo.NewHTTPError(http.StatusUnauthorized,
+					fmt.Sprintf("unsupported authorization type %s", authorizationType))
+			}
+
+			payload, err := tokenMaker.VerifyToken(fields[1])
+			if err != nil {
+				return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+			}
+
+			c.Set(authorizationPayloadKey, payload)
+			return next(c)
+		}
+	}
+}
+
+// operatorMiddleware only lets through requests carrying the configured operator API key
+// With no key configured every operator route is rejected
+func operatorMiddleware(operatorAPIKey string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if operatorAPIKey == "" {
+				return echo.NewHTTPError(http.StatusForbidden, "operator routes are disabled")
+			}
+
+			key := c.Request().Header.Get(operatorKeyHeader)
+			if len(key) == 0 {
+				return echo.NewHTTPError(http.StatusUnauthorized, fmt.Sprintf("%s header is not provided", operatorKeyHeader))
+			}
+
+			if subtle.ConstantTimeCompare([]byte(key), []byte(operatorAPIKey)) != 1 {
+				return echo.NewHTTPError(http.StatusUnauthorized, "invalid operator key")
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// pageCursor is the position of the last item of a page, lists are ordered by (created_at, id)
+// Rows inserted while a client pages through a list never shift the pages it has not read yet.
+// Amount is only used by lists sorted by (amount, id)
+type pageCursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        int64     `json:"id"`
+	Amount    int64     `json:"a,omitempty"`
+}
+
+// encode returns the cursor as an opaque url safe token
+func (cursor pageCursor) encode() string {
+	// a struct of a time and numbers always marshals
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodePageCursor parses a token made by encode, an empty token is the start of the list
+func decodePageCursor(token string) (pageCursor, error) {
+	var cursor pageCursor
+	if token == "" {
+		return cursor, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || json.Unmarshal(data, &cursor) != nil || cursor.ID <= 0 {
+		return pageCursor{}, echo.NewHTTPError(http.StatusBadRequest, "invalid cursor")
+	}
+	return cursor, nil
+}
+
+// pageResponse is the envelope of list responses, NextCursor is null on the last page
+type pageResponse[T any] struct {
+	Items      []T     `json:"items"`
+	NextCursor *string `json:"next_cursor"`
+}
+
+// newPageResponse converts the rows of a page to a pageResponse
+// The rows are queried with limit+1, a row past limit means there is a next page starting after the last item
+func newPageResponse[R any, T any](rows []R, limit int32, cursorOf func(R) pageCursor, convert func(R) T) pageResponse[T] {
+	var nextCursor *string
+	if len(rows) > int(limit) {
+		rows = rows[:limit]
+		next := cursorOf(rows[len(rows)-1]).encode()
+		nextCursor = &next
+	}
+
+	items := make([]T, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, convert(row))
+	}
+	return pageResponse[T]{Items: items, NextCursor: nextCursor}
+}
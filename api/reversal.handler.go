@@ -0,0 +1,62 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	db "github.com/T-BO0/bank/db/sqlc"
+	"github.com/T-BO0/bank/token"
+	"github.com/labstack/echo/v4"
+)
+
+// reverseTransferRequest is request json body of the reverse transfer handler
+type reverseTransferRequest struct {
+	Reason string `json:"reason" validate:"required,max=255"`
+}
+
+// ANCHOR - reverseTransfer refunds a transfer with an opposite one linked to it route:POST: /transfers/:id/reverse
+// Only the owner of the to account can reverse, since the reversal is debited from it
+func (server *Server) reverseTransfer(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+
+	req := reverseTransferRequest{}
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+
+	transfer, err := server.store.GetTransfer(c.Request().Context(), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return echo.NewHTTPError(http.StatusNotFound, "transfer not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	toAccount, err := server.store.GetAccount(c.Request().Context(), transfer.ToAccountID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	authPayload := c.Get(authorizationPayloadKey).(*token.Payload)
+	if toAccount.Owner != authPayload.Username {
+		return echo.NewHTTPError(http.StatusForbidden, "to account doesn't belong to the authenticated user")
+	}
+
+	result, err := server.store.ReverseTransferTx(c.Request().Context(), db.ReverseTransferTxParams{
+		TransferID: id,
+		Reason:     req.Reason,
+	})
+	if err != nil {
+		return transferTxHTTPError(err)
+	}
+
+	return c.JSON(http.StatusOK, newTransferTxResponse(result))
+}
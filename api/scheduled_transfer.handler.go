@@ -0,0 +1,366 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	db "github.com/T-BO0/bank/db/sqlc"
+	"github.com/T-BO0/bank/token"
+	"github.com/T-BO0/bank/util/money"
+	"github.com/T-BO0/bank/util/schedule"
+	"github.com/labstack/echo/v4"
+)
+
+// createScheduledTransferRequest takes amount as a decimal string and a rule as described in package schedule
+// Without startAt the first run is the first one of the rule from now
+type createScheduledTransferRequest struct {
+	FromAccountID int64      `json:"fromAccountId" validate:"required,numeric,min=1"`
+	ToAccountID   int64      `json:"toAccountId" validate:"required,numeric,min=1"`
+	Amount        string     `json:"amount" validate:"required"`
+	Currency      string     `json:"currency" validate:"required,oneof=USD EUR GEL"`
+	Rule          string     `json:"rule" validate:"required"`
+	StartAt       *time.Time `json:"startAt"`
+	EndAt         *time.Time `json:"endAt"`
+}
+
+// updateScheduledTransferRequest changes only the fields that are set
+type updateScheduledTransferRequest struct {
+	Amount *string    `json:"amount"`
+	Rule   *string    `json:"rule"`
+	EndAt  *time.Time `json:"endAt"`
+	Active *bool      `json:"active"`
+}
+
+type listScheduledTransferRequest struct {
+	Limit      int32 `query:"limit" validate:"required,numeric,min=1"`
+	PageNumber int32 `query:"page" validate:"required,numeric,min=1"`
+}
+
+// scheduledTransferResponse is scheduled transfer returned to user with amount formatted as a decimal string
+type scheduledTransferResponse struct {
+	ID            int64      `json:"id"`
+	Owner         string     `json:"owner"`
+	FromAccountID int64      `json:"from_account_id"`
+	ToAccountID   int64      `json:"to_account_id"`
+	Amount        string     `json:"amount"`
+	Currency      string     `json:"currency"`
+	Rule          string     `json:"rule"`
+	NextRunAt     time.Time  `json:"next_run_at"`
+	EndAt         *time.Time `json:"end_at,omitempty"`
+	Active        bool       `json:"active"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// newScheduledTransferResponse converts db scheduled transfer to scheduledTransferResponse
+func newScheduledTransferResponse(scheduled db.ScheduledTransfer) scheduledTransferResponse {
+	response := scheduledTransferResponse{
+		ID:            scheduled.ID,
+		Owner:         scheduled.Owner,
+		FromAccountID: scheduled.FromAccountID,
+		ToAccountID:   scheduled.ToAccountID,
+		Amount:        money.Format(scheduled.Amount, scheduled.Currency),
+		Currency:      scheduled.Currency,
+		Rule:          scheduled.Rule,
+		NextRunAt:     scheduled.NextRunAt,
+		Active:        scheduled.Active,
+		CreatedAt:     scheduled.CreatedAt,
+	}
+	if scheduled.EndAt.Valid {
+		response.EndAt = &scheduled.EndAt.Time
+	}
+	return response
+}
+
+// scheduledTransferRunResponse is the outcome of one run, transfer_id is set when it succeeded and error when it failed
+type scheduledTransferRunResponse struct {
+	ID                  int64     `json:"id"`
+	ScheduledTransferID int64     `json:"scheduled_transfer_id"`
+	RunAt               time.Time `json:"run_at"`
+	Status              string    `json:"status"`
+	TransferID          *int64    `json:"transfer_id,omitempty"`
+	Error               string    `json:"error,omitempty"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+// newScheduledTransferRunResponse converts db scheduled transfer run to scheduledTransferRunResponse
+func newScheduledTransferRunResponse(run db.ScheduledTransferRun) scheduledTransferRunResponse {
+	response := scheduledTransferRunResponse{
+		ID:                  run.ID,
+		ScheduledTransferID: run.ScheduledTransferID,
+		RunAt:               run.RunAt,
+		Status:              run.Status,
+		Error:               run.Error.String,
+		CreatedAt:           run.CreatedAt,
+	}
+	if run.TransferID.Valid {
+		response.TransferID = &run.TransferID.Int64
+	}
+	return response
+}
+
+// ANCHOR - createScheduledTransfer creates a standing order of the authenticated user route:POST: /scheduled-transfers
+// Scheduled transfers run through the same checks as transfers, but only between accounts of one currency
+func (server *Server) createScheduledTransfer(c echo.Context) error {
+	req := createScheduledTransferRequest{}
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+
+	if req.FromAccountID == req.ToAccountID {
+		return echo.NewHTTPError(http.StatusBadRequest, "from and to account must be different")
+	}
+
+	rule, err := schedule.Parse(req.Rule)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	amount, err := money.Parse(req.Amount, req.Currency)
+	if err != nil || amount <= 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("amount %s is not a valid positive %s amount", req.Amount, req.Currency))
+	}
+
+	now := time.Now().UTC()
+	nextRunAt := rule.Next(now)
+	if req.StartAt != nil {
+		if !req.StartAt.After(now) {
+			return echo.NewHTTPError(http.StatusBadRequest, "startAt must be in the future")
+		}
+		nextRunAt = req.StartAt.UTC()
+	}
+	if nextRunAt.IsZero() {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("rule %s needs a startAt", req.Rule))
+	}
+
+	endAt := sql.NullTime{}
+	if req.EndAt != nil {
+		if !req.EndAt.After(nextRunAt) {
+			return echo.NewHTTPError(http.StatusBadRequest, "endAt must be after the first run")
+		}
+		endAt = sql.NullTime{Time: req.EndAt.UTC(), Valid: true}
+	}
+
+	authPayload := c.Get(authorizationPayloadKey).(*token.Payload)
+	err = server.validateScheduledTransferAccounts(c, req, authPayload.Username)
+	if err != nil {
+		return err
+	}
+
+	scheduled, err := server.store.CreateScheduledTransfer(c.Request().Context(), db.CreateScheduledTransferParams{
+		Owner:         authPayload.Username,
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        amount,
+		Currency:      req.Currency,
+		Rule:          req.Rule,
+		NextRunAt:     nextRunAt,
+		EndAt:         endAt,
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, newScheduledTransferResponse(scheduled))
+}
+
+// validateScheduledTransferAccounts checks that the from account belongs to owner and both accounts are in the currency
+func (server *Server) validateScheduledTransferAccounts(c echo.Context, req createScheduledTransferRequest, owner string) error {
+	fromAccount, err := server.store.GetAccount(c.Request().Context(), req.FromAccountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return echo.NewHTTPError(http.StatusNotFound, "from account not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if fromAccount.Owner != owner {
+		return echo.NewHTTPError(http.StatusForbidden, "from account doesn't belong to the authenticated user")
+	}
+
+	toAccount, err := server.store.GetAccount(c.Request().Context(), req.ToAccountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return echo.NewHTTPError(http.StatusNotFound, "to account not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	if fromAccount.Currency != req.Currency || toAccount.Currency != req.Currency {
+		return echo.NewHTTPError(http.StatusBadRequest, "both accounts must be in the currency of a scheduled transfer")
+	}
+	return nil
+}
+
+// ANCHOR - getScheduledTransfer returns a scheduled transfer of the authenticated user route:GET: /scheduled-transfers/:id
+func (server *Server) getScheduledTransfer(c echo.Context) error {
+	scheduled, err := server.getOwnedScheduledTransfer(c)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, newScheduledTransferResponse(scheduled))
+}
+
+// ANCHOR - listScheduledTransfers lists the scheduled transfers of the authenticated user route:GET: /scheduled-transfers?limit=?&page=?
+func (server *Server) listScheduledTransfers(c echo.Context) error {
+	req := listScheduledTransferRequest{}
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	authPayload := c.Get(authorizationPayloadKey).(*token.Payload)
+	scheduledTransfers, err := server.store.ListScheduledTransfersByOwner(c.Request().Context(), db.ListScheduledTransfersByOwnerParams{
+		Owner:  authPayload.Username,
+		Limit:  req.Limit,
+		Offset: (req.PageNumber - 1) * req.Limit,
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	response := make([]scheduledTransferResponse, 0, len(scheduledTransfers))
+	for _, scheduled := range scheduledTransfers {
+		response = append(response, newScheduledTransferResponse(scheduled))
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// ANCHOR - updateScheduledTransfer changes amount, rule, end or activity of a scheduled transfer route:PATCH: /scheduled-transfers/:id
+// A new rule or a reactivation moves the next run to the first one of the rule from now
+func (server *Server) updateScheduledTransfer(c echo.Context) error {
+	scheduled, err := server.getOwnedScheduledTransfer(c)
+	if err != nil {
+		return err
+	}
+
+	req := updateScheduledTransferRequest{}
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	arg := db.UpdateScheduledTransferParams{ID: scheduled.ID}
+
+	if req.Amount != nil {
+		amount, err := money.Parse(*req.Amount, scheduled.Currency)
+		if err != nil || amount <= 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("amount %s is not a valid positive %s amount", *req.Amount, scheduled.Currency))
+		}
+		arg.Amount = sql.NullInt64{Int64: amount, Valid: true}
+	}
+
+	ruleText := scheduled.Rule
+	if req.Rule != nil {
+		ruleText = *req.Rule
+		arg.Rule = sql.NullString{String: ruleText, Valid: true}
+	}
+	if req.Active != nil {
+		arg.Active = sql.NullBool{Bool: *req.Active, Valid: true}
+	}
+
+	if req.Rule != nil || (req.Active != nil && *req.Active && !scheduled.Active) {
+		rule, err := schedule.Parse(ruleText)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		nextRunAt := rule.Next(time.Now().UTC())
+		if nextRunAt.IsZero() {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("rule %s has no run left", ruleText))
+		}
+		arg.NextRunAt = sql.NullTime{Time: nextRunAt, Valid: true}
+	}
+
+	if req.EndAt != nil {
+		arg.EndAt = sql.NullTime{Time: req.EndAt.UTC(), Valid: true}
+	}
+
+	scheduled, err = server.store.UpdateScheduledTransfer(c.Request().Context(), arg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, newScheduledTransferResponse(scheduled))
+}
+
+// ANCHOR - cancelScheduledTransfer deactivates a scheduled transfer, its runs are kept route:DELETE: /scheduled-transfers/:id
+func (server *Server) cancelScheduledTransfer(c echo.Context) error {
+	scheduled, err := server.getOwnedScheduledTransfer(c)
+	if err != nil {
+		return err
+	}
+
+	scheduled, err = server.store.UpdateScheduledTransfer(c.Request().Context(), db.UpdateScheduledTransferParams{
+		ID:     scheduled.ID,
+		Active: sql.NullBool{Bool: false, Valid: true},
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, newScheduledTransferResponse(scheduled))
+}
+
+// ANCHOR - listScheduledTransferRuns lists the outcomes of a scheduled transfer, newest first route:GET: /scheduled-transfers/:id/runs?limit=?&page=?
+func (server *Server) listScheduledTransferRuns(c echo.Context) error {
+	scheduled, err := server.getOwnedScheduledTransfer(c)
+	if err != nil {
+		return err
+	}
+
+	req := listScheduledTransferRequest{}
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	runs, err := server.store.ListScheduledTransferRuns(c.Request().Context(), db.ListScheduledTransferRunsParams{
+		ScheduledTransferID: scheduled.ID,
+		Limit:               req.Limit,
+		Offset:              (req.PageNumber - 1) * req.Limit,
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	response := make([]scheduledTransferRunResponse, 0, len(runs))
+	for _, run := range runs {
+		response = append(response, newScheduledTransferRunResponse(run))
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// getOwnedScheduledTransfer loads the scheduled transfer of the id path param and checks it belongs to the authenticated user
+func (server *Server) getOwnedScheduledTransfer(c echo.Context) (db.ScheduledTransfer, error) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return db.ScheduledTransfer{}, echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+
+	scheduled, err := server.store.GetScheduledTransfer(c.Request().Context(), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return db.ScheduledTransfer{}, echo.NewHTTPError(http.StatusNotFound, "scheduled transfer not found")
+		}
+		return db.ScheduledTransfer{}, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	authPayload := c.Get(authorizationPayloadKey).(*token.Payload)
+	if scheduled.Owner != authPayload.Username {
+		return db.ScheduledTransfer{}, echo.NewHTTPError(http.StatusForbidden, "scheduled transfer doesn't belong to the authenticated user")
+	}
+	return scheduled, nil
+}
@@ -1,15 +1,22 @@
 package api
 
 import (
-	db "github.com/T-BO0/bank/db/sqlc"
-	"github.com/go-playground/validator/v10"
+	"fmt"
+
+	"github.com/T-BO0/bank/db/dbx"
+	"github.com/T-BO0/bank/fx"
+	"github.com/T-BO0/bank/token"
+	"github.com/T-BO0/bank/util"
 	"github.com/labstack/echo/v4"
 )
 
 // Server serves HTTP requests for our banking service
 type Server struct {
-	store  db.Store
-	router *echo.Echo
+	config       util.Config
+	store        dbx.Store
+	tokenMaker   token.Maker
+	rateProvider fx.RateProvider
+	router       *echo.Echo
 }
 
 // Start runs the HTTP server on a specific address
@@ -18,22 +25,80 @@ func (server *Server) Start(address string) error {
 }
 
 // NewServer creates a new HTTP server and setup routing
-func NewServer(store db.Store) *Server {
-	server := &Server{store: store}
-	router := echo.New()
-	router.Validator = &CustomValidator{validator: validator.New()}
+func NewServer(config util.Config, store dbx.Store) (*Server, error) {
+	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create token maker: %w", err)
+	}
+
+	// rates come from the fx_rates table unless a rates file is configured
+	rateProvider := fx.NewDBRateProvider(store)
+	if config.FXRatesFile != "" {
+		rateProvider, err = fx.NewFileRateProvider(config.FXRatesFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create rate provider: %w", err)
+		}
+	}
 
-	router.POST("/accounts", server.createAccount)
-	router.GET("/accounts/:id", server.getAccount)
-	router.GET("/accounts", server.getListOfAccount)
+	server := &Server{
+		config:       config,
+		store:        store,
+		tokenMaker:   tokenMaker,
+		rateProvider: rateProvider,
+	}
+	server.setupRouter()
+	return server, nil
+}
 
-	router.POST("/transfers", server.createTransfer)
-	router.GET("/transfers/:id", server.getTransfer)
-	router.GET("/transfers", server.listTransfers)
+// setupRouter registers all routes, the account and transfer ones behind the auth middleware
+// and the cash, account status, interest, limit, audit and metrics ones behind the operator middleware
+func (server *Server) setupRouter() {
+	router := echo.New()
+	router.Validator = newCustomValidator()
 
 	router.POST("/users", server.createUser)
+	router.POST("/users/login", server.loginUser)
 	router.GET("/users/*", server.getUser)
 
+	authRoutes := router.Group("", authMiddleware(server.tokenMaker))
+
+	authRoutes.POST("/accounts", server.createAccount)
+	authRoutes.GET("/accounts/:id", server.getAccount)
+	authRoutes.GET("/accounts", server.getListOfAccount)
+	authRoutes.GET("/accounts/:id/entries", server.listAccountEntries)
+	authRoutes.GET("/accounts/:id/statements", server.getAccountStatement)
+
+	authRoutes.POST("/transfers", server.createTransfer)
+	authRoutes.GET("/transfers/quote", server.quoteTransfer)
+	authRoutes.GET("/transfers/search", server.searchTransfers)
+	authRoutes.POST("/transfers/batch", server.createBatchTransfer)
+	authRoutes.GET("/transfers/:id", server.getTransfer)
+	authRoutes.GET("/transfers", server.listTransfers)
+	authRoutes.POST("/transfers/:id/reverse", server.reverseTransfer)
+	authRoutes.POST("/transfers/authorize", server.authorizeTransfer)
+	authRoutes.POST("/transfers/:id/capture", server.captureTransfer)
+	authRoutes.POST("/transfers/:id/void", server.voidTransfer)
+
+	authRoutes.POST("/scheduled-transfers", server.createScheduledTransfer)
+	authRoutes.GET("/scheduled-transfers", server.listScheduledTransfers)
+	authRoutes.GET("/scheduled-transfers/:id", server.getScheduledTransfer)
+	authRoutes.PATCH("/scheduled-transfers/:id", server.updateScheduledTransfer)
+	authRoutes.DELETE("/scheduled-transfers/:id", server.cancelScheduledTransfer)
+	authRoutes.GET("/scheduled-transfers/:id/runs", server.listScheduledTransferRuns)
+
+	operatorRoutes := router.Group("", operatorMiddleware(server.config.OperatorAPIKey))
+
+	operatorRoutes.POST("/accounts/:id/deposits", server.createDeposit)
+	operatorRoutes.POST("/accounts/:id/withdrawals", server.createWithdrawal)
+	operatorRoutes.GET("/accounts/:id/ledger/verification", server.verifyLedger)
+	operatorRoutes.POST("/accounts/:id/freeze", server.freezeAccount)
+	operatorRoutes.POST("/accounts/:id/unfreeze", server.unfreezeAccount)
+	operatorRoutes.POST("/accounts/:id/close", server.closeAccount)
+	operatorRoutes.PUT("/accounts/:id/interest-rate", server.setInterestRate)
+	operatorRoutes.PUT("/accounts/:id/limits", server.setAccountTransferLimits)
+	operatorRoutes.PUT("/users/:username/limits", server.setUserTransferLimits)
+	operatorRoutes.GET("/reconciliation", server.reconcile)
+	operatorRoutes.GET("/metrics", server.getMetrics)
+
 	server.router = router
-	return server
 }
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/T-BO0/bank/db/dbx"
+	db "github.com/T-BO0/bank/db/sqlc"
+	"github.com/T-BO0/bank/statement"
+	"github.com/T-BO0/bank/token"
+	"github.com/labstack/echo/v4"
+)
+
+// getAccountStatementRequest selects the period of a statement, from and to are days on the bank's clock and both inclusive
+type getAccountStatementRequest struct {
+	From   string `query:"from" validate:"required,datetime=2006-01-02"`
+	To     string `query:"to" validate:"required,datetime=2006-01-02"`
+	Format string `query:"format" validate:"omitempty,oneof=csv json ofx camt053 mt940"`
+}
+
+// ANCHOR - getAccountStatement downloads the statement of an account for a period route:GET: /accounts/:id/statements
+// The statement has the opening balance, every entry of the period with its running balance and the closing balance,
+// it is streamed as it is read so a long period is never held in memory. format is one of csv, json, ofx,
+// camt053 for ISO 20022 camt.053 XML or mt940 for SWIFT MT940, json by default.
+func (server *Server) getAccountStatement(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+
+	req := getAccountStatementRequest{}
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+	if req.Format == "" {
+		req.Format = statement.FormatJSON
+	}
+
+	fromTime, toTime, err := parseBankDateRange(req.From, req.To)
+	if err != nil {
+		return err
+	}
+
+	account, err := server.store.GetAccount(c.Request().Context(), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return echo.NewHTTPError(http.StatusNotFound, "account not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	authPayload := c.Get(authorizationPayloadKey).(*token.Payload)
+	if account.Owner != authPayload.Username {
+		return echo.NewHTTPError(http.StatusForbidden, "account doesn't belong to the authenticated user")
+	}
+
+	w, err := statement.NewWriter(req.Format, c.Response())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentType, statement.ContentType(req.Format))
+	header.Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=statement-%d-%s-%s.%s",
+		account.ID, req.From, req.To, statement.Extension(req.Format)))
+
+	// the period ends at the start of the day after to, the statement wants the last day itself
+	err = statement.Generate(c.Request().Context(), server.store, account.ID, fromTime.Time, toTime.Time.AddDate(0, 0, -1), time.Now().UTC(), w)
+	if err != nil {
+		// once the first bytes are out the status is sent, the error can only end the response early
+		if c.Response().Committed {
+			return err
+		}
+		header.Del(echo.HeaderContentDisposition)
+		// a statement whose entries miss its balances is never sent, not even its error
+		if errors.Is(err, dbx.ErrStatementMismatch) {
+			header.Del(echo.HeaderContentType)
+			return c.NoContent(http.StatusInternalServerError)
+		}
+		if errors.Is(err, db.ErrAccountNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "account not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return nil
+}
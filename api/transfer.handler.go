@@ -2,21 +2,132 @@ package api
 
 import (
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	db "github.com/T-BO0/bank/db/sqlc"
+	"github.com/T-BO0/bank/fx"
+	"github.com/T-BO0/bank/token"
+	"github.com/T-BO0/bank/util/money"
 	"github.com/labstack/echo/v4"
 )
 
+// createTransferRequest takes amount as a decimal string like "12.34" in the given currency
 type createTransferRequest struct {
-	FromAccountID int64   `json:"fromAccountId" validate:"required,numeric,min=1"`
-	ToAccountID   int64   `json:"toAccountId" validate:"required,numeric,min=1"`
-	Amount        float64 `json:"amount" validate:"required,numeric,gt=0"`
-	Currency      string  `json:"currency" validate:"required,oneof=USD EUR GEL"`
+	FromAccountID int64  `json:"fromAccountId" validate:"required,numeric,min=1"`
+	ToAccountID   int64  `json:"toAccountId" validate:"required,numeric,min=1"`
+	Amount        string `json:"amount" validate:"required"`
+	Currency      string `json:"currency" validate:"required,oneof=USD EUR GEL"`
+	// Description is free text for the customers, Reference is the external reference of the payment
+	// and Metadata holds up to 20 string pairs for the client's own use
+	Description string            `json:"description" validate:"omitempty,max=140,transfertext"`
+	Reference   string            `json:"reference" validate:"omitempty,max=35,reference"`
+	Metadata    map[string]string `json:"metadata" validate:"omitempty,max=20,dive,keys,required,max=40,metadatakey,endkeys,max=500,transfertext"`
 }
 
+// transferResponse is transfer returned to user with amount formatted as a decimal string
+type transferResponse struct {
+	ID            int64     `json:"id"`
+	FromAccountID int64     `json:"from_account_id"`
+	ToAccountID   int64     `json:"to_account_id"`
+	Amount        string    `json:"amount"`
+	Currency      string    `json:"currency"`
+	ToAmount      string    `json:"to_amount"`
+	ToCurrency    string    `json:"to_currency"`
+	FxRate        string    `json:"fx_rate"`
+	Fee           string    `json:"fee"`
+	CreatedAt     time.Time `json:"created_at"`
+	// ReversalOf and ReversalReason are only set on transfers that reverse another one
+	ReversalOf     *int64          `json:"reversal_of,omitempty"`
+	ReversalReason string          `json:"reversal_reason,omitempty"`
+	Description    string          `json:"description,omitempty"`
+	Reference      string          `json:"reference,omitempty"`
+	Metadata       json.RawMessage `json:"metadata"`
+}
+
+// newTransferResponse converts db transfer to transferResponse
+func newTransferResponse(transfer db.Transfer) transferResponse {
+	response := transferResponse{
+		ID:            transfer.ID,
+		FromAccountID: transfer.FromAccountID,
+		ToAccountID:   transfer.ToAccountID,
+		Amount:        money.Format(transfer.Amount, transfer.Currency),
+		Currency:      transfer.Currency,
+		ToAmount:      money.Format(transfer.ToAmount, transfer.ToCurrency),
+		ToCurrency:    transfer.ToCurrency,
+		FxRate:        transfer.FxRate,
+		Fee:           money.Format(transfer.Fee, transfer.Currency),
+		CreatedAt:     transfer.CreatedAt,
+		Description:   transfer.Description.String,
+		Reference:     transfer.Reference.String,
+		Metadata:      transfer.Metadata,
+	}
+	if len(response.Metadata) == 0 {
+		response.Metadata = json.RawMessage("{}")
+	}
+	if transfer.ReversalOf.Valid {
+		response.ReversalOf = &transfer.ReversalOf.Int64
+		response.ReversalReason = transfer.ReversalReason.String
+	}
+	return response
+}
+
+// entryResponse is entry returned to user with amount formatted in the currency of its account
+type entryResponse struct {
+	ID        int64     `json:"id"`
+	AccountID int64     `json:"account_id"`
+	Amount    string    `json:"amount"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// newEntryResponse converts db entry to entryResponse
+func newEntryResponse(entry db.Entry, currency string) entryResponse {
+	return entryResponse{
+		ID:        entry.ID,
+		AccountID: entry.AccountID,
+		Amount:    money.Format(entry.Amount, currency),
+		CreatedAt: entry.CreatedAt,
+	}
+}
+
+// transferTxResponse is respons returned to user from create transfer handler
+type transferTxResponse struct {
+	Transfer    transferResponse `json:"transfer"`
+	FromAccount accountResponse  `json:"fromAccount"`
+	ToAccount   accountResponse  `json:"toAccount"`
+	FromEntry   entryResponse    `json:"fromEntry"`
+	ToEntry     entryResponse    `json:"toEntry"`
+	FeeEntry    *entryResponse   `json:"feeEntry,omitempty"`
+}
+
+// newTransferTxResponse converts db transfer tx result to transferTxResponse
+func newTransferTxResponse(result db.TransferTxResult) transferTxResponse {
+	response := transferTxResponse{
+		Transfer:    newTransferResponse(result.Transfer),
+		FromAccount: newAccountResponse(result.FromAccount),
+		ToAccount:   newAccountResponse(result.ToAccount),
+		FromEntry:   newEntryResponse(result.FromEntry, result.FromAccount.Currency),
+		ToEntry:     newEntryResponse(result.ToEntry, result.ToAccount.Currency),
+	}
+	if result.FeeEntry != nil {
+		feeEntry := newEntryResponse(*result.FeeEntry, result.Transfer.Currency)
+		response.FeeEntry = &feeEntry
+	}
+	return response
+}
+
+const (
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
 // ANCHOR -  TransferHandler handles the creation of a transfer. route:POST /transfers
+// An optional Idempotency-Key header makes client retries return the original response
 func (server *Server) createTransfer(c echo.Context) error {
 	createTransfer := createTransferRequest{}
 
@@ -25,7 +136,13 @@ func (server *Server) createTransfer(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
 
-	err = server.validateTransferRequest(c, createTransfer)
+	idempotencyKey := c.Request().Header.Get(idempotencyKeyHeader)
+	if len(idempotencyKey) > maxIdempotencyKeyLength {
+		return echo.NewHTTPError(http.StatusBadRequest,
+			fmt.Sprintf("%s must be at most %d characters", idempotencyKeyHeader, maxIdempotencyKeyLength))
+	}
+
+	_, toAccount, err := server.validateTransferRequest(c, createTransfer)
 	if err != nil {
 		return err
 	}
@@ -35,49 +152,135 @@ func (server *Server) createTransfer(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
 
-	transfer, err := server.store.TransferTx(c.Request().Context(), db.TransferTxParams{
-		FromAccountID: createTransfer.FromAccountID,
-		ToAccountID:   createTransfer.ToAccountID,
-		Amount:        createTransfer.Amount,
-	})
+	amount, err := money.Parse(createTransfer.Amount, createTransfer.Currency)
+	if err != nil || amount <= 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("amount %s is not a valid positive %s amount", createTransfer.Amount, createTransfer.Currency))
+	}
+
+	authPayload := c.Get(authorizationPayloadKey).(*token.Payload)
+	arg := db.TransferTxParams{
+		FromAccountID:  createTransfer.FromAccountID,
+		ToAccountID:    createTransfer.ToAccountID,
+		Amount:         amount,
+		Currency:       createTransfer.Currency,
+		Description:    createTransfer.Description,
+		Reference:      createTransfer.Reference,
+		Metadata:       createTransfer.Metadata,
+		IdempotencyKey: idempotencyKey,
+		Username:       authPayload.Username,
+	}
+
+	var result db.TransferTxResult
+	if toAccount.Currency == createTransfer.Currency {
+		result, err = server.store.TransferTx(c.Request().Context(), arg)
+	} else {
+		result, err = server.fxTransfer(c, arg, toAccount.Currency)
+	}
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return transferTxHTTPError(err)
+	}
+
+	if result.Replayed {
+		c.Response().Header().Set(idempotentReplayedHeader, "true")
 	}
 
-	return c.JSON(http.StatusOK, transfer)
+	return c.JSON(http.StatusOK, newTransferTxResponse(result))
 }
 
-// validateTransferRequest validates the transfer request bsed from and to account id, currency and account existence
-func (server *Server) validateTransferRequest(c echo.Context, req createTransferRequest) error {
+// fxTransfer converts the transfer to the currency of the to account at the current rate
+func (server *Server) fxTransfer(c echo.Context, arg db.TransferTxParams, toCurrency string) (db.TransferTxResult, error) {
+	rate, err := server.rateProvider.Rate(c.Request().Context(), arg.Currency, toCurrency)
+	if err != nil {
+		return db.TransferTxResult{}, err
+	}
+
+	return server.store.FXTransferTx(c.Request().Context(), db.FXTransferTxParams{
+		TransferTxParams: arg,
+		ToCurrency:       toCurrency,
+		Rate:             rate,
+	})
+}
+
+// transferTxHTTPError maps typed store errors of a transfer transaction to http errors
+func transferTxHTTPError(err error) *echo.HTTPError {
+	var limitErr *db.LimitExceededError
+	switch {
+	case errors.As(err, &limitErr):
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, newLimitExceededResponse(limitErr))
+	case errors.Is(err, db.ErrInsufficientFunds):
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, db.ErrAccountNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case errors.Is(err, db.ErrCurrencyMismatch):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, db.ErrIdempotencyKeyReused):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case errors.Is(err, db.ErrTransferNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case errors.Is(err, db.ErrTransferAlreadyReversed), errors.Is(err, db.ErrTransferIsReversal):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case errors.Is(err, db.ErrAccountNotActive), errors.Is(err, db.ErrWithdrawalLimitReached):
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, db.ErrHoldNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case errors.Is(err, db.ErrHoldNotAuthorized), errors.Is(err, db.ErrHoldExpired):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case errors.Is(err, db.ErrCaptureExceedsHold):
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, fx.ErrRateNotFound):
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, money.ErrInvalidAmount):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+}
+
+// validateTransferRequest validates the transfer request bsed from and to account id, currency, account existence
+// and that both accounts are active
+// and returns both accounts so the caller can check whether the transfer crosses currencies.
+// The from account is checked to belong to the authenticated user before anything else about either account,
+// so the errors can't be used to probe accounts of other users
+func (server *Server) validateTransferRequest(c echo.Context, req createTransferRequest) (db.Account, db.Account, error) {
 	if req.FromAccountID == req.ToAccountID {
-		return echo.NewHTTPError(http.StatusBadRequest, "from and to account must be different")
+		return db.Account{}, db.Account{}, echo.NewHTTPError(http.StatusBadRequest, "from and to account must be different")
 	}
 
 	acc1, err := server.store.GetAccount(c.Request().Context(), req.FromAccountID)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return echo.NewHTTPError(http.StatusNotFound, "from account not found")
+			return db.Account{}, db.Account{}, echo.NewHTTPError(http.StatusNotFound, "from account not found")
 		}
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return db.Account{}, db.Account{}, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
+
+	authPayload := c.Get(authorizationPayloadKey).(*token.Payload)
+	if acc1.Owner != authPayload.Username {
+		return db.Account{}, db.Account{}, echo.NewHTTPError(http.StatusForbidden, "from account doesn't belong to the authenticated user")
+	}
+
 	acc2, err2 := server.store.GetAccount(c.Request().Context(), req.ToAccountID)
 	if err2 != nil {
 		if err2 == sql.ErrNoRows {
-			return echo.NewHTTPError(http.StatusNotFound, "to account not found")
+			return db.Account{}, db.Account{}, echo.NewHTTPError(http.StatusNotFound, "to account not found")
 		}
-		return echo.NewHTTPError(http.StatusInternalServerError, err2.Error())
+		return db.Account{}, db.Account{}, echo.NewHTTPError(http.StatusInternalServerError, err2.Error())
 	}
 
 	if acc1.Currency != req.Currency {
-		return echo.NewHTTPError(http.StatusBadRequest, "from account currency mismatch")
+		return db.Account{}, db.Account{}, echo.NewHTTPError(http.StatusBadRequest, "from account currency mismatch")
+	}
+
+	if acc1.Status != db.AccountActive {
+		return db.Account{}, db.Account{}, echo.NewHTTPError(http.StatusUnprocessableEntity, fmt.Sprintf("from account is %s", acc1.Status))
 	}
-	if acc2.Currency != req.Currency {
-		return echo.NewHTTPError(http.StatusBadRequest, "to account currency mismatch")
+	if acc2.Status != db.AccountActive {
+		return db.Account{}, db.Account{}, echo.NewHTTPError(http.StatusUnprocessableEntity, fmt.Sprintf("to account is %s", acc2.Status))
 	}
-	return nil
+	return acc1, acc2, nil
 }
 
 // ANCHOR -  GetTransferHandler handles fetching transfer details. route:GET /transfers/:id
+// Only the owner of the from or the to account can read a transfer
 func (server *Server) getTransfer(c echo.Context) error {
 	idstr := c.Param("id")
 	if idstr == "" {
@@ -85,7 +288,7 @@ func (server *Server) getTransfer(c echo.Context) error {
 	}
 
 	id, err := strconv.ParseInt(idstr, 10, 64)
-	if err != nil {
+	if err != nil || id <= 0 {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
 	}
 
@@ -97,15 +300,41 @@ func (server *Server) getTransfer(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
-	return c.JSON(http.StatusOK, transfer)
+	authPayload := c.Get(authorizationPayloadKey).(*token.Payload)
+	owns, err := server.ownsTransfer(c, transfer, authPayload.Username)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if !owns {
+		return echo.NewHTTPError(http.StatusForbidden, "transfer doesn't belong to the authenticated user")
+	}
+
+	return c.JSON(http.StatusOK, newTransferResponse(transfer))
+}
+
+// ownsTransfer reports whether username owns the from or the to account of the transfer
+func (server *Server) ownsTransfer(c echo.Context, transfer db.Transfer, username string) (bool, error) {
+	for _, accountID := range []int64{transfer.FromAccountID, transfer.ToAccountID} {
+		account, err := server.store.GetAccount(c.Request().Context(), accountID)
+		if err != nil {
+			return false, err
+		}
+		if account.Owner == username {
+			return true, nil
+		}
+	}
+	return false, nil
 }
 
 type listTransferRequest struct {
-	Limit      int32 `query:"limit" validate:"required,numeric,min=1"`
-	PageNumber int32 `query:"page" validate:"required,numeric,min=1"`
+	Limit     int32  `query:"limit" validate:"required,numeric,min=1,max=100"`
+	Cursor    string `query:"cursor"`
+	Reference string `query:"reference" validate:"omitempty,max=35,reference"`
 }
 
-// ANCHOR - listTransfersHandler handles fetching list of transfers based on limit and offset. route:GET /transfers?limit=?&offset=?
+// ANCHOR - listTransfersHandler handles fetching a page of transfers. route:GET /transfers?limit=?&cursor=?
+// Only the transfers in or out of the accounts of the authenticated user are listed, with reference only the ones
+// with that reference. The next page is read with the next_cursor of the response
 func (server *Server) listTransfers(c echo.Context) error {
 	req := listTransferRequest{}
 	err := c.Bind(&req)
@@ -118,10 +347,29 @@ func (server *Server) listTransfers(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
 
-	transfers, err := server.store.ListTransfer(c.Request().Context(), db.ListTransferParams{
-		Limit:  req.Limit,
-		Offset: (req.PageNumber - 1) * req.Limit,
-	})
+	cursor, err := decodePageCursor(req.Cursor)
+	if err != nil {
+		return err
+	}
+
+	authPayload := c.Get(authorizationPayloadKey).(*token.Payload)
+	var transfers []db.Transfer
+	if req.Reference != "" {
+		transfers, err = server.store.ListTransferByReference(c.Request().Context(), db.ListTransferByReferenceParams{
+			Reference:      req.Reference,
+			Owner:          authPayload.Username,
+			AfterCreatedAt: cursor.CreatedAt,
+			AfterID:        cursor.ID,
+			LimitCount:     req.Limit + 1,
+		})
+	} else {
+		transfers, err = server.store.ListTransferByOwnerAfter(c.Request().Context(), db.ListTransferByOwnerAfterParams{
+			Owner:          authPayload.Username,
+			AfterCreatedAt: cursor.CreatedAt,
+			AfterID:        cursor.ID,
+			LimitCount:     req.Limit + 1,
+		})
+	}
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return echo.NewHTTPError(http.StatusNotFound, "no transfers found")
@@ -129,5 +377,10 @@ func (server *Server) listTransfers(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
-	return c.JSON(http.StatusOK, transfers)
+	return c.JSON(http.StatusOK, newPageResponse(transfers, req.Limit, transferCursor, newTransferResponse))
+}
+
+// transferCursor is the page cursor of a transfer
+func transferCursor(transfer db.Transfer) pageCursor {
+	return pageCursor{CreatedAt: transfer.CreatedAt, ID: transfer.ID}
 }
@@ -0,0 +1,232 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	db "github.com/T-BO0/bank/db/sqlc"
+	"github.com/T-BO0/bank/util/money"
+	"github.com/labstack/echo/v4"
+	"github.com/lib/pq"
+)
+
+// transferLimitRequest takes amounts as decimal strings in the currency of the limit, an omitted field means no limit
+// A request replaces all limits of its scope, so a limit is lifted by leaving it out
+type transferLimitRequest struct {
+	PerTransfer   *string `json:"perTransfer"`
+	DailyAmount   *string `json:"dailyAmount"`
+	DailyCount    *int64  `json:"dailyCount" validate:"omitempty,min=0"`
+	MonthlyAmount *string `json:"monthlyAmount"`
+	MonthlyCount  *int64  `json:"monthlyCount" validate:"omitempty,min=0"`
+}
+
+// userTransferLimitRequest is transferLimitRequest for all accounts of a user in Currency
+type userTransferLimitRequest struct {
+	transferLimitRequest
+	Currency string `json:"currency" validate:"required,oneof=USD EUR GEL"`
+}
+
+// transferLimitResponse is transfer limit returned to user with amounts formatted as decimal strings, null means no limit
+type transferLimitResponse struct {
+	Username      *string   `json:"username,omitempty"`
+	AccountID     *int64    `json:"account_id,omitempty"`
+	Currency      string    `json:"currency"`
+	PerTransfer   *string   `json:"per_transfer"`
+	DailyAmount   *string   `json:"daily_amount"`
+	DailyCount    *int64    `json:"daily_count"`
+	MonthlyAmount *string   `json:"monthly_amount"`
+	MonthlyCount  *int64    `json:"monthly_count"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// newTransferLimitResponse converts db transfer limit to transferLimitResponse
+func newTransferLimitResponse(limit db.TransferLimit) transferLimitResponse {
+	formatAmount := func(amount sql.NullInt64) *string {
+		if !amount.Valid {
+			return nil
+		}
+		formatted := money.Format(amount.Int64, limit.Currency)
+		return &formatted
+	}
+	count := func(count sql.NullInt64) *int64 {
+		if !count.Valid {
+			return nil
+		}
+		return &count.Int64
+	}
+
+	response := transferLimitResponse{
+		Currency:      limit.Currency,
+		PerTransfer:   formatAmount(limit.MaxPerTransfer),
+		DailyAmount:   formatAmount(limit.MaxDailyAmount),
+		DailyCount:    count(limit.MaxDailyCount),
+		MonthlyAmount: formatAmount(limit.MaxMonthlyAmount),
+		MonthlyCount:  count(limit.MaxMonthlyCount),
+		UpdatedAt:     limit.UpdatedAt,
+	}
+	if limit.Username.Valid {
+		response.Username = &limit.Username.String
+	}
+	if limit.AccountID.Valid {
+		response.AccountID = &limit.AccountID.Int64
+	}
+	return response
+}
+
+// parsedTransferLimits are the limits of a transferLimitRequest in minor units
+type parsedTransferLimits struct {
+	perTransfer   sql.NullInt64
+	dailyAmount   sql.NullInt64
+	dailyCount    sql.NullInt64
+	monthlyAmount sql.NullInt64
+	monthlyCount  sql.NullInt64
+}
+
+// parse converts the amounts of the request to minor units of currency
+func (req transferLimitRequest) parse(currency string) (parsedTransferLimits, error) {
+	var limits parsedTransferLimits
+	amounts := []struct {
+		name   string
+		amount *string
+		target *sql.NullInt64
+	}{
+		{name: "perTransfer", amount: req.PerTransfer, target: &limits.perTransfer},
+		{name: "dailyAmount", amount: req.DailyAmount, target: &limits.dailyAmount},
+		{name: "monthlyAmount", amount: req.MonthlyAmount, target: &limits.monthlyAmount},
+	}
+
+	for _, a := range amounts {
+		if a.amount == nil {
+			continue
+		}
+		amount, err := money.Parse(*a.amount, currency)
+		if err != nil || amount < 0 {
+			return limits, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("%s %s is not a valid %s amount", a.name, *a.amount, currency))
+		}
+		*a.target = sql.NullInt64{Int64: amount, Valid: true}
+	}
+
+	if req.DailyCount != nil {
+		limits.dailyCount = sql.NullInt64{Int64: *req.DailyCount, Valid: true}
+	}
+	if req.MonthlyCount != nil {
+		limits.monthlyCount = sql.NullInt64{Int64: *req.MonthlyCount, Valid: true}
+	}
+	return limits, nil
+}
+
+// ANCHOR - setAccountTransferLimits replaces the transfer limits of an account route:PUT: /accounts/:id/limits
+// Amounts are in the currency of the account
+func (server *Server) setAccountTransferLimits(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+
+	req := transferLimitRequest{}
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+
+	account, err := server.store.GetAccount(c.Request().Context(), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return echo.NewHTTPError(http.StatusNotFound, "account not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	limits, err := req.parse(account.Currency)
+	if err != nil {
+		return err
+	}
+
+	limit, err := server.store.UpsertAccountTransferLimit(c.Request().Context(), db.UpsertAccountTransferLimitParams{
+		AccountID:        account.ID,
+		Currency:         account.Currency,
+		MaxPerTransfer:   limits.perTransfer,
+		MaxDailyAmount:   limits.dailyAmount,
+		MaxDailyCount:    limits.dailyCount,
+		MaxMonthlyAmount: limits.monthlyAmount,
+		MaxMonthlyCount:  limits.monthlyCount,
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, newTransferLimitResponse(limit))
+}
+
+// ANCHOR - setUserTransferLimits replaces the transfer limits of a user in a currency route:PUT: /users/:username/limits
+// The limits cover the money leaving all accounts of the user in the currency together
+func (server *Server) setUserTransferLimits(c echo.Context) error {
+	username := c.Param("username")
+
+	req := userTransferLimitRequest{}
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+
+	limits, err := req.parse(req.Currency)
+	if err != nil {
+		return err
+	}
+
+	limit, err := server.store.UpsertUserTransferLimit(c.Request().Context(), db.UpsertUserTransferLimitParams{
+		Username:         username,
+		Currency:         req.Currency,
+		MaxPerTransfer:   limits.perTransfer,
+		MaxDailyAmount:   limits.dailyAmount,
+		MaxDailyCount:    limits.dailyCount,
+		MaxMonthlyAmount: limits.monthlyAmount,
+		MaxMonthlyCount:  limits.monthlyCount,
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "foreign_key_violation" {
+			return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("there is no user with user name %s", username))
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, newTransferLimitResponse(limit))
+}
+
+// limitExceededResponse is the 422 body of a transfer over a limit
+// Max and Remaining are decimal amounts in Currency, or numbers of transfers for the count limits
+type limitExceededResponse struct {
+	Message   string `json:"message"`
+	Scope     string `json:"scope"`
+	Limit     string `json:"limit"`
+	Currency  string `json:"currency"`
+	Max       string `json:"max"`
+	Remaining string `json:"remaining"`
+}
+
+// newLimitExceededResponse converts db limit exceeded error to limitExceededResponse
+func newLimitExceededResponse(err *db.LimitExceededError) limitExceededResponse {
+	response := limitExceededResponse{
+		Message:  err.Error(),
+		Scope:    err.Scope,
+		Limit:    err.Limit,
+		Currency: err.Currency,
+	}
+	if err.IsCountLimit() {
+		response.Max = strconv.FormatInt(err.Max, 10)
+		response.Remaining = strconv.FormatInt(err.Remaining, 10)
+	} else {
+		response.Max = money.Format(err.Max, err.Currency)
+		response.Remaining = money.Format(err.Remaining, err.Currency)
+	}
+	return response
+}
@@ -0,0 +1,244 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mockdb "github.com/T-BO0/bank/db/mock"
+	db "github.com/T-BO0/bank/db/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetAccountTransferLimitsAPI(t *testing.T) {
+	user, _ := getRandomUser(t)
+	account := getRandomAccount(user.Username)
+	account.Currency = "USD"
+
+	//SECTION - Test cases
+	testCases := []struct {
+		name          string
+		operatorKey   string
+		body          map[string]interface{}
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "OK",
+			operatorKey: testOperatorAPIKey,
+			body:        map[string]interface{}{"perTransfer": "500.00", "dailyAmount": "1000", "dailyCount": 5},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpsertAccountTransferLimitParams{
+					AccountID:      account.ID,
+					Currency:       "USD",
+					MaxPerTransfer: sql.NullInt64{Int64: 50000, Valid: true},
+					MaxDailyAmount: sql.NullInt64{Int64: 100000, Valid: true},
+					MaxDailyCount:  sql.NullInt64{Int64: 5, Valid: true},
+				}
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					UpsertAccountTransferLimit(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.TransferLimit{
+						AccountID:      sql.NullInt64{Int64: account.ID, Valid: true},
+						Currency:       "USD",
+						MaxPerTransfer: arg.MaxPerTransfer,
+						MaxDailyAmount: arg.MaxDailyAmount,
+						MaxDailyCount:  arg.MaxDailyCount,
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response transferLimitResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, "500.00", *response.PerTransfer)
+				require.Equal(t, "1000.00", *response.DailyAmount)
+				require.Equal(t, int64(5), *response.DailyCount)
+				require.Nil(t, response.MonthlyAmount)
+				require.Nil(t, response.MonthlyCount)
+			},
+		},
+		{
+			name:        "InvalidAmount",
+			operatorKey: testOperatorAPIKey,
+			body:        map[string]interface{}{"perTransfer": "-1"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().UpsertAccountTransferLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:        "NegativeCount",
+			operatorKey: testOperatorAPIKey,
+			body:        map[string]interface{}{"monthlyCount": -1},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().UpsertAccountTransferLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:        "AccountNotFound",
+			operatorKey: testOperatorAPIKey,
+			body:        map[string]interface{}{"dailyCount": 5},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().UpsertAccountTransferLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:        "NoOperatorKey",
+			operatorKey: "",
+			body:        map[string]interface{}{"dailyCount": 5},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertAccountTransferLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+	//!SECTION
+
+	//SECTION - Test RUN
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			jsonBody, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/accounts/%d/limits", account.ID)
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewBuffer(jsonBody))
+			require.NoError(t, err)
+			request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			if tc.operatorKey != "" {
+				request.Header.Set(operatorKeyHeader, tc.operatorKey)
+			}
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+	//!SECTION
+}
+
+func TestSetUserTransferLimitsAPI(t *testing.T) {
+	user, _ := getRandomUser(t)
+
+	//SECTION - Test cases
+	testCases := []struct {
+		name          string
+		body          map[string]interface{}
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: map[string]interface{}{"currency": "GEL", "monthlyAmount": "20000", "monthlyCount": 100},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpsertUserTransferLimitParams{
+					Username:         user.Username,
+					Currency:         "GEL",
+					MaxMonthlyAmount: sql.NullInt64{Int64: 2000000, Valid: true},
+					MaxMonthlyCount:  sql.NullInt64{Int64: 100, Valid: true},
+				}
+				store.EXPECT().
+					UpsertUserTransferLimit(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.TransferLimit{
+						Username:         sql.NullString{String: user.Username, Valid: true},
+						Currency:         "GEL",
+						MaxMonthlyAmount: arg.MaxMonthlyAmount,
+						MaxMonthlyCount:  arg.MaxMonthlyCount,
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response transferLimitResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, user.Username, *response.Username)
+				require.Equal(t, "20000.00", *response.MonthlyAmount)
+			},
+		},
+		{
+			name: "MissingCurrency",
+			body: map[string]interface{}{"monthlyCount": 100},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertUserTransferLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "UserNotFound",
+			body: map[string]interface{}{"currency": "USD", "dailyCount": 1},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpsertUserTransferLimit(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferLimit{}, &pq.Error{Code: "23503"})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+	//!SECTION
+
+	//SECTION - Test RUN
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			jsonBody, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/users/%s/limits", user.Username)
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewBuffer(jsonBody))
+			require.NoError(t, err)
+			request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			request.Header.Set(operatorKeyHeader, testOperatorAPIKey)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+	//!SECTION
+}
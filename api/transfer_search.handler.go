@@ -0,0 +1,133 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/T-BO0/bank/db/dbx"
+	db "github.com/T-BO0/bank/db/sqlc"
+	"github.com/T-BO0/bank/token"
+	"github.com/T-BO0/bank/util/money"
+	"github.com/labstack/echo/v4"
+)
+
+// searchTransfersRequest filters the transfers of the authenticated user, every given filter has to match
+// from and to are inclusive days on the bank's clock, minAmount and maxAmount are decimal amounts in currency
+type searchTransfersRequest struct {
+	Limit          int32  `query:"limit" validate:"required,numeric,min=1,max=100"`
+	Cursor         string `query:"cursor"`
+	AccountID      int64  `query:"accountId" validate:"omitempty,min=1"`
+	CounterpartyID int64  `query:"counterpartyId" validate:"omitempty,min=1"`
+	From           string `query:"from" validate:"omitempty,datetime=2006-01-02"`
+	To             string `query:"to" validate:"omitempty,datetime=2006-01-02"`
+	MinAmount      string `query:"minAmount"`
+	MaxAmount      string `query:"maxAmount"`
+	Currency       string `query:"currency" validate:"omitempty,oneof=USD EUR GEL"`
+	Reference      string `query:"reference" validate:"omitempty,max=35,reference"`
+	Sort           string `query:"sort" validate:"omitempty,oneof=created_at -created_at amount -amount"`
+}
+
+// parseAmountRange converts the amount range of the request to minor units of its currency
+func (req searchTransfersRequest) parseAmountRange() (sql.NullInt64, sql.NullInt64, error) {
+	var minAmount, maxAmount sql.NullInt64
+	if req.MinAmount == "" && req.MaxAmount == "" {
+		return minAmount, maxAmount, nil
+	}
+	if req.Currency == "" {
+		return minAmount, maxAmount, echo.NewHTTPError(http.StatusBadRequest, "currency is required to search by amount")
+	}
+
+	for _, a := range []struct {
+		name   string
+		amount string
+		target *sql.NullInt64
+	}{
+		{name: "minAmount", amount: req.MinAmount, target: &minAmount},
+		{name: "maxAmount", amount: req.MaxAmount, target: &maxAmount},
+	} {
+		if a.amount == "" {
+			continue
+		}
+		amount, err := money.Parse(a.amount, req.Currency)
+		if err != nil || amount < 0 {
+			return minAmount, maxAmount, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("%s %s is not a valid %s amount", a.name, a.amount, req.Currency))
+		}
+		*a.target = sql.NullInt64{Int64: amount, Valid: true}
+	}
+
+	if minAmount.Valid && maxAmount.Valid && minAmount.Int64 > maxAmount.Int64 {
+		return minAmount, maxAmount, echo.NewHTTPError(http.StatusBadRequest, "minAmount must not be more than maxAmount")
+	}
+	return minAmount, maxAmount, nil
+}
+
+// ANCHOR - searchTransfers searches the transfers in and out of the accounts of the authenticated user route:GET: /transfers/search
+// Newest first unless sort says otherwise, the next page is read with the next_cursor of the response
+func (server *Server) searchTransfers(c echo.Context) error {
+	req := searchTransfersRequest{}
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+
+	fromTime, toTime, err := parseBankDateRange(req.From, req.To)
+	if err != nil {
+		return err
+	}
+
+	minAmount, maxAmount, err := req.parseAmountRange()
+	if err != nil {
+		return err
+	}
+
+	cursor, err := decodePageCursor(req.Cursor)
+	if err != nil {
+		return err
+	}
+
+	authPayload := c.Get(authorizationPayloadKey).(*token.Payload)
+	if req.AccountID != 0 {
+		account, err := server.store.GetAccount(c.Request().Context(), req.AccountID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return echo.NewHTTPError(http.StatusNotFound, "account not found")
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		if account.Owner != authPayload.Username {
+			return echo.NewHTTPError(http.StatusForbidden, "account doesn't belong to the authenticated user")
+		}
+	}
+
+	arg := dbx.SearchTransfersParams{
+		Owner:                 authPayload.Username,
+		AccountID:             sql.NullInt64{Int64: req.AccountID, Valid: req.AccountID != 0},
+		CounterpartyAccountID: sql.NullInt64{Int64: req.CounterpartyID, Valid: req.CounterpartyID != 0},
+		FromTime:              fromTime,
+		ToTime:                toTime,
+		MinAmount:             minAmount,
+		MaxAmount:             maxAmount,
+		Currency:              sql.NullString{String: req.Currency, Valid: req.Currency != ""},
+		Reference:             sql.NullString{String: req.Reference, Valid: req.Reference != ""},
+		Sort:                  req.Sort,
+		AfterID:               cursor.ID,
+		AfterCreatedAt:        cursor.CreatedAt,
+		AfterAmount:           cursor.Amount,
+		Limit:                 req.Limit + 1,
+	}
+	transfers, err := server.store.SearchTransfers(c.Request().Context(), arg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, newPageResponse(transfers, req.Limit, transferSearchCursor, newTransferResponse))
+}
+
+// transferSearchCursor is the page cursor of a transfer in a search, which may be sorted by amount
+func transferSearchCursor(transfer db.Transfer) pageCursor {
+	return pageCursor{CreatedAt: transfer.CreatedAt, ID: transfer.ID, Amount: transfer.Amount}
+}
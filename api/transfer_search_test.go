@@ -0,0 +1,200 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/T-BO0/bank/db/dbx"
+	mockdb "github.com/T-BO0/bank/db/mock"
+	db "github.com/T-BO0/bank/db/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchTransfersAPI(t *testing.T) {
+	user, _ := getRandomUser(t)
+	other, _ := getRandomUser(t)
+	account := getRandomAccount(user.Username)
+	transfer := db.Transfer{
+		ID:            4,
+		FromAccountID: account.ID,
+		ToAccountID:   account.ID + 1,
+		Amount:        2500,
+		Currency:      "USD",
+		ToAmount:      2500,
+		ToCurrency:    "USD",
+		FxRate:        "1",
+		Metadata:      json.RawMessage("{}"),
+		CreatedAt:     time.Date(2024, 3, 5, 12, 30, 0, 0, time.UTC),
+	}
+	cursor := pageCursor{CreatedAt: time.Date(2024, 3, 6, 9, 0, 0, 0, time.UTC), ID: 9, Amount: 5000}
+	accountID := strconv.FormatInt(account.ID, 10)
+
+	//SECTION - Test cases
+	testCases := []struct {
+		name          string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: "?limit=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := dbx.SearchTransfersParams{Owner: user.Username, Limit: 6}
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Eq(arg)).Times(1).Return([]db.Transfer{transfer}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var page pageResponse[transferResponse]
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &page))
+				require.Len(t, page.Items, 1)
+				require.Nil(t, page.NextCursor)
+			},
+		},
+		{
+			name: "AllFilters",
+			query: "?limit=1&accountId=" + accountID + "&counterpartyId=77&from=2024-03-01&to=2024-03-31" +
+				"&minAmount=10&maxAmount=50.50&currency=USD&reference=INV-1&sort=-amount&cursor=" + cursor.encode(),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+
+				arg := dbx.SearchTransfersParams{
+					Owner:                 user.Username,
+					AccountID:             sql.NullInt64{Int64: account.ID, Valid: true},
+					CounterpartyAccountID: sql.NullInt64{Int64: 77, Valid: true},
+					FromTime:              sql.NullTime{Time: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+					ToTime:                sql.NullTime{Time: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+					MinAmount:             sql.NullInt64{Int64: 1000, Valid: true},
+					MaxAmount:             sql.NullInt64{Int64: 5050, Valid: true},
+					Currency:              sql.NullString{String: "USD", Valid: true},
+					Reference:             sql.NullString{String: "INV-1", Valid: true},
+					Sort:                  dbx.TransferSortLargest,
+					AfterID:               cursor.ID,
+					AfterCreatedAt:        cursor.CreatedAt,
+					AfterAmount:           cursor.Amount,
+					Limit:                 2,
+				}
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Eq(arg)).Times(1).Return([]db.Transfer{transfer, transfer}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var page pageResponse[transferResponse]
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &page))
+				require.Len(t, page.Items, 1)
+				require.NotNil(t, page.NextCursor)
+
+				next, err := decodePageCursor(*page.NextCursor)
+				require.NoError(t, err)
+				require.Equal(t, transfer.ID, next.ID)
+				require.Equal(t, transfer.Amount, next.Amount)
+			},
+		},
+		{
+			name:  "AmountWithoutCurrency",
+			query: "?limit=5&minAmount=10",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidAmount",
+			query: "?limit=5&minAmount=10.555&currency=USD",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "MinAboveMax",
+			query: "?limit=5&minAmount=20&maxAmount=10&currency=USD",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidSort",
+			query: "?limit=5&sort=id",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "AccountOfOtherUser",
+			query: "?limit=5&accountId=" + accountID,
+			buildStubs: func(store *mockdb.MockStore) {
+				otherAccount := account
+				otherAccount.Owner = other.Username
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(otherAccount, nil)
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:  "AccountNotFound",
+			query: "?limit=5&accountId=" + accountID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:  "InternalError",
+			query: "?limit=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+	//!SECTION
+
+	//SECTION - Test RUN
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/transfers/search"+tc.query, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+	//!SECTION
+}
@@ -29,6 +29,17 @@ type userResponse struct {
 	CreatedAt         time.Time `json:"createdAt"`
 }
 
+// newUserResponse converts db user to userResponse without the password hash
+func newUserResponse(user db.User) userResponse {
+	return userResponse{
+		Username:          user.Username,
+		FullName:          user.FullName,
+		Email:             user.Email,
+		PasswordChangedAt: user.PasswordChangedAt,
+		CreatedAt:         user.CreatedAt,
+	}
+}
+
 // ANCHOR - createUser is a handler that creates new Account route:POST: /accounts
 func (server *Server) createUser(c echo.Context) error {
 	createUserReq := new(createUserRequest)
@@ -67,15 +78,7 @@ func (server *Server) createUser(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
-	response := userResponse{
-		Username:          user.Username,
-		FullName:          user.FullName,
-		Email:             user.Email,
-		PasswordChangedAt: user.PasswordChangedAt,
-		CreatedAt:         user.CreatedAt,
-	}
-
-	return c.JSON(http.StatusOK, response)
+	return c.JSON(http.StatusOK, newUserResponse(user))
 }
 
 // ANCHOR - getUser will return user with given  user name
@@ -95,13 +98,63 @@ func (server *Server) getUser(c echo.Context) error {
 			fmt.Errorf("something went wrong while getting user with username: %s. error: %w", usernameParam, err))
 	}
 
-	userRes := userResponse{
-		Username:          user.Username,
-		FullName:          user.FullName,
-		Email:             user.Email,
-		PasswordChangedAt: user.PasswordChangedAt,
-		CreatedAt:         user.CreatedAt,
+	return c.JSON(http.StatusOK, newUserResponse(user))
+}
+
+// invalidCredentialsMessage is the answer to a login with an unknown username or a wrong password
+const invalidCredentialsMessage = "invalid username or password"
+
+// loginUserRequest is request json body of login user handler
+type loginUserRequest struct {
+	Username string `json:"userName" validate:"required,alphanum"`
+	Password string `json:"password" validate:"required,min=8"`
+}
+
+// loginUserResponse is respons returned to user from login user handler
+type loginUserResponse struct {
+	AccessToken          string       `json:"accessToken"`
+	AccessTokenExpiresAt time.Time    `json:"accessTokenExpiresAt"`
+	User                 userResponse `json:"user"`
+}
+
+// ANCHOR - loginUser checks user credentials and returns access token route:POST: /users/login
+// An unknown username and a wrong password get the same answer, so logging in doesn't tell which usernames exist
+func (server *Server) loginUser(c echo.Context) error {
+	loginUserReq := new(loginUserRequest)
+
+	// check binding
+	if err := c.Bind(loginUserReq); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	// check validation fileds
+	if err := c.Validate(loginUserReq); err != nil {
+		return err
+	}
+
+	user, err := server.store.GetUser(c.Request().Context(), loginUserReq.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return echo.NewHTTPError(http.StatusUnauthorized, invalidCredentialsMessage)
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
-	return c.JSON(http.StatusOK, userRes)
+	err = util.CheckPassword(loginUserReq.Password, user.PasswordHash)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, invalidCredentialsMessage)
+	}
+
+	accessToken, accessPayload, err := server.tokenMaker.CreateToken(user.Username, server.config.AccessTokenDuration)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	response := loginUserResponse{
+		AccessToken:          accessToken,
+		AccessTokenExpiresAt: accessPayload.ExpiredAt,
+		User:                 newUserResponse(user),
+	}
+
+	return c.JSON(http.StatusOK, response)
 }
@@ -3,6 +3,9 @@ package api
 import (
 	"fmt"
 	"net/http"
+	"reflect"
+	"regexp"
+	"unicode"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
@@ -13,6 +16,33 @@ type CustomValidator struct {
 	validator *validator.Validate
 }
 
+// referenceRegexp is the SWIFT character set, so references survive the payment networks they are sent over
+var referenceRegexp = regexp.MustCompile(`^[A-Za-z0-9/\-?:().,'+ ]*$`)
+
+// metadataKeyRegexp are the characters allowed in the keys of transfer metadata
+var metadataKeyRegexp = regexp.MustCompile(`^[A-Za-z0-9_.-]*$`)
+
+// newCustomValidator returns CustomValidator with the custom tags of the api registered
+func newCustomValidator() *CustomValidator {
+	v := validator.New()
+	// transfertext is free text without control characters
+	v.RegisterValidation("transfertext", func(fl validator.FieldLevel) bool {
+		for _, r := range fl.Field().String() {
+			if !unicode.IsPrint(r) {
+				return false
+			}
+		}
+		return true
+	})
+	v.RegisterValidation("reference", func(fl validator.FieldLevel) bool {
+		return referenceRegexp.MatchString(fl.Field().String())
+	})
+	v.RegisterValidation("metadatakey", func(fl validator.FieldLevel) bool {
+		return metadataKeyRegexp.MatchString(fl.Field().String())
+	})
+	return &CustomValidator{validator: v}
+}
+
 // Custom validate function
 func (cv *CustomValidator) Validate(i interface{}) error {
 	if err := cv.validator.Struct(i); err != nil {
@@ -29,6 +59,18 @@ func (cv *CustomValidator) Validate(i interface{}) error {
 				errorMessages[fieldName] = fmt.Sprintf("%s must be a valid email", fieldName)
 			case "min":
 				errorMessages[fieldName] = fmt.Sprintf("%s must be at least %s characters", fieldName, fieldErr.Param())
+			case "max":
+				if fieldErr.Kind() == reflect.String {
+					errorMessages[fieldName] = fmt.Sprintf("%s must be at most %s characters", fieldName, fieldErr.Param())
+				} else {
+					errorMessages[fieldName] = fmt.Sprintf("%s must have at most %s entries", fieldName, fieldErr.Param())
+				}
+			case "transfertext":
+				errorMessages[fieldName] = fmt.Sprintf("%s must not contain control characters", fieldName)
+			case "reference":
+				errorMessages[fieldName] = fmt.Sprintf("%s may only contain letters, digits, spaces and / - ? : ( ) . , ' +", fieldName)
+			case "metadatakey":
+				errorMessages[fieldName] = fmt.Sprintf("%s keys may only contain letters, digits and _ . -", fieldName)
 			default:
 				errorMessages[fieldName] = fmt.Sprintf("%s is invalid", fieldName)
 			}
@@ -0,0 +1,86 @@
+// Command interest accrues or posts interest outside of the server, for backfills and manual runs
+//
+//	interest accrue [-date 2006-01-02]   accrues one day, yesterday on the bank's clock by default
+//	interest post [-month 2006-01]       posts a month, the previous one by default
+//
+// Both are idempotent, running them for a day or month that is already done changes nothing.
+// The configuration is read from app.env in the working directory like the server does
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	db "github.com/T-BO0/bank/db/sqlc"
+	"github.com/T-BO0/bank/util"
+	_ "github.com/lib/pq"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	today := db.BankDate(time.Now())
+
+	var run func(store db.Store) (db.InterestRunResult, error)
+	switch os.Args[1] {
+	case "accrue":
+		flags := flag.NewFlagSet("accrue", flag.ExitOnError)
+		date := flags.String("date", today.AddDate(0, 0, -1).Format("2006-01-02"), "bank day to accrue")
+		flags.Parse(os.Args[2:])
+
+		day, err := time.Parse("2006-01-02", *date)
+		if err != nil {
+			log.Fatal("invalid date: ", err)
+		}
+		if !day.Before(today) {
+			log.Fatal("only finished days can be accrued")
+		}
+		run = func(store db.Store) (db.InterestRunResult, error) {
+			return store.AccrueInterest(context.Background(), day)
+		}
+	case "post":
+		flags := flag.NewFlagSet("post", flag.ExitOnError)
+		month := flags.String("month", today.AddDate(0, -1, 1-today.Day()).Format("2006-01"), "month to post")
+		flags.Parse(os.Args[2:])
+
+		start, err := time.Parse("2006-01", *month)
+		if err != nil {
+			log.Fatal("invalid month: ", err)
+		}
+		if start.AddDate(0, 1, 0).After(today) {
+			log.Fatal("only finished months can be posted")
+		}
+		run = func(store db.Store) (db.InterestRunResult, error) {
+			return store.PostInterest(context.Background(), start)
+		}
+	default:
+		usage()
+	}
+
+	config, err := util.LoadConfig("./")
+	if err != nil {
+		log.Fatal("cannot load configuration: ", err)
+	}
+	conn, err := sql.Open(config.DBDriver, config.DBSource)
+	if err != nil {
+		log.Fatal("cannot connect to db", err)
+	}
+
+	result, err := run(db.NewStore(conn))
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%s: %d processed, %d skipped, %d failed\n", result.Date.Format("2006-01-02"), result.Processed, result.Skipped, result.Failed)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: interest accrue [-date 2006-01-02] | interest post [-month 2006-01]")
+	os.Exit(2)
+}
@@ -0,0 +1,93 @@
+// Command statement writes the statement of an account for a period, for clients that import statement files
+//
+//	statement -account 42 -from 2006-01-02 -to 2006-01-02 [-format camt053] [-out file]
+//
+// from and to are days on the bank's clock, both inclusive. format is one of camt053, mt940, ofx, csv or json,
+// camt053 by default, and the statement goes to stdout unless -out names a file.
+// The configuration is read from app.env in the working directory like the server does
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/T-BO0/bank/db/dbx"
+	"github.com/T-BO0/bank/statement"
+	"github.com/T-BO0/bank/util"
+	_ "github.com/lib/pq"
+)
+
+func main() {
+	accountID := flag.Int64("account", 0, "id of the account")
+	from := flag.String("from", "", "first day of the period")
+	to := flag.String("to", "", "last day of the period")
+	format := flag.String("format", statement.FormatCamt053, "format of the statement")
+	out := flag.String("out", "", "file to write the statement to, stdout by default")
+	flag.Parse()
+
+	if *accountID <= 0 || *from == "" || *to == "" {
+		usage()
+	}
+	fromDay, err := time.Parse("2006-01-02", *from)
+	if err != nil {
+		log.Fatal("invalid from: ", err)
+	}
+	toDay, err := time.Parse("2006-01-02", *to)
+	if err != nil {
+		log.Fatal("invalid to: ", err)
+	}
+	if fromDay.After(toDay) {
+		log.Fatal("from must not be after to")
+	}
+	if !statement.IsSupportedFormat(*format) {
+		log.Fatal("unsupported format: ", *format)
+	}
+
+	config, err := util.LoadConfig("./")
+	if err != nil {
+		log.Fatal("cannot load configuration: ", err)
+	}
+	conn, err := sql.Open(config.DBDriver, config.DBSource)
+	if err != nil {
+		log.Fatal("cannot connect to db", err)
+	}
+
+	var output io.Writer = os.Stdout
+	var file *os.File
+	if *out != "" {
+		file, err = os.Create(*out)
+		if err != nil {
+			log.Fatal("cannot create output file: ", err)
+		}
+		output = file
+	}
+
+	w, err := statement.NewWriter(*format, output)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = statement.Generate(context.Background(), dbx.NewStore(conn), *accountID, fromDay, toDay, time.Now().UTC(), w)
+	if file != nil {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		// a cut off statement would import as a complete one
+		if err != nil {
+			os.Remove(*out)
+		}
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: statement -account 42 -from 2006-01-02 -to 2006-01-02 [-format camt053|mt940|ofx|csv|json] [-out file]")
+	os.Exit(2)
+}
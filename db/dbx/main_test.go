@@ -0,0 +1,59 @@
+package dbx
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"os"
+	"testing"
+
+	db "github.com/T-BO0/bank/db/sqlc"
+	"github.com/T-BO0/bank/util"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+var testQueries *db.Queries
+var testDB *sql.DB
+
+func TestMain(m *testing.M) {
+	config, err := util.LoadConfig("../..")
+	if err != nil {
+		log.Fatal("cannot load config: ", err)
+	}
+	testDB, err = sql.Open(config.DBDriver, config.DBSource)
+	if err != nil {
+		log.Fatal("cannot connect to db", err)
+	}
+
+	testQueries = db.New(testDB)
+
+	os.Exit(m.Run())
+}
+
+func createRandomUser(t *testing.T) db.User {
+	passwordHash, err := util.HashPassword(util.RandomString(9))
+	require.NoError(t, err)
+
+	user, err := testQueries.CreateUser(context.Background(), db.CreateUserParams{
+		Username:     util.RandomOwner(),
+		PasswordHash: passwordHash,
+		FullName:     util.RandomOwner(),
+		Email:        util.RandomEmail(),
+	})
+	require.NoError(t, err)
+	return user
+}
+
+func createAccountOfType(t *testing.T, accountType string, balance int64) db.Account {
+	user := createRandomUser(t)
+
+	account, err := testQueries.CreateAccount(context.Background(), db.CreateAccountParams{
+		Owner:    user.Username,
+		Balance:  balance,
+		Currency: "USD",
+		Type:     accountType,
+	})
+	require.NoError(t, err)
+	return account
+}
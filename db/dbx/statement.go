@@ -0,0 +1,147 @@
+package dbx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	db "github.com/T-BO0/bank/db/sqlc"
+)
+
+// ErrStatementMismatch is returned when the entries of a statement don't add up to its opening and closing balances
+var ErrStatementMismatch = errors.New("statement entries don't add up to its balances")
+
+// StatementParams selects the entries of an account from FromTime, inclusive, until ToTime, exclusive
+type StatementParams struct {
+	AccountID int64     `json:"accountId"`
+	FromTime  time.Time `json:"fromTime"`
+	ToTime    time.Time `json:"toTime"`
+}
+
+// StatementSummary is the account of a statement with its balances at the start and the end of the period
+type StatementSummary struct {
+	Account db.Account `json:"account"`
+	db.GetStatementBalancesRow
+}
+
+// StatementEntry is an entry of a statement with the details of its transfer and the account on the other side of it
+type StatementEntry struct {
+	ID                    int64          `json:"id"`
+	Amount                int64          `json:"amount"`
+	CreatedAt             time.Time      `json:"createdAt"`
+	TransferID            sql.NullInt64  `json:"transferId"`
+	Description           sql.NullString `json:"description"`
+	Reference             sql.NullString `json:"reference"`
+	CounterpartyAccountID sql.NullInt64  `json:"counterpartyAccountId"`
+	CounterpartyOwner     sql.NullString `json:"counterpartyOwner"`
+}
+
+// statementEntries are the entries of an account in a period, oldest first
+// sqlc only returns whole slices, so the rows are read by eachStatementEntry one at a time instead
+const statementEntries = `SELECT entries.id, entries.amount, entries.created_at, entries.transfer_id,
+  transfers.description, transfers.reference,
+  counterparty.id AS counterparty_account_id, counterparty.owner AS counterparty_owner
+FROM entries
+LEFT JOIN transfers ON transfers.id = entries.transfer_id
+LEFT JOIN accounts AS counterparty ON counterparty.id = CASE
+  WHEN transfers.from_account_id = entries.account_id THEN transfers.to_account_id
+  ELSE transfers.from_account_id
+END
+WHERE entries.account_id = $1
+AND entries.created_at >= $2
+AND entries.created_at < $3
+ORDER BY entries.created_at, entries.id
+`
+
+// statementTotals adds up the rows statementEntries returns, to check them against the balances before any is read
+const statementTotals = `SELECT COALESCE(SUM(amount), 0)::bigint, COUNT(*) FROM (` + statementEntries + `) AS statement_entries`
+
+// eachStatementEntry calls fn for every entry of the statement without holding more than one row in memory
+func eachStatementEntry(ctx context.Context, tx *sql.Tx, arg StatementParams, fn func(StatementEntry) error) error {
+	rows, err := tx.QueryContext(ctx, statementEntries, arg.AccountID, arg.FromTime, arg.ToTime)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var i StatementEntry
+		if err := rows.Scan(
+			&i.ID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.Description,
+			&i.Reference,
+			&i.CounterpartyAccountID,
+			&i.CounterpartyOwner,
+		); err != nil {
+			return err
+		}
+		if err := fn(i); err != nil {
+			return err
+		}
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+	return rows.Err()
+}
+
+// ANCHOR - StatementTx reads the statement of an account for a period within one read only snapshot
+// summary is called once with the balances before any entry, then entry once per entry oldest first,
+// so the balances always add up with the entries even while transfers keep coming in. The entries are
+// added up before summary is called, if they miss the balances it returns ErrStatementMismatch without a callback.
+// It is never retried, the callbacks may already have written part of the statement.
+func (store *SQLStore) StatementTx(ctx context.Context, arg StatementParams, summary func(StatementSummary) error, entry func(StatementEntry) error) error {
+	tx, err := store.conn.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return err
+	}
+
+	err = readStatement(ctx, tx, arg, summary, entry)
+	if err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("tx err: %w, rb err: %v", err, rbErr)
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
+// readStatement reads the summary and the entries of a statement within tx
+func readStatement(ctx context.Context, tx *sql.Tx, arg StatementParams, summary func(StatementSummary) error, entry func(StatementEntry) error) error {
+	q := db.New(tx)
+	account, err := q.GetAccount(ctx, arg.AccountID)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("%w: %d", db.ErrAccountNotFound, arg.AccountID)
+	}
+	if err != nil {
+		return err
+	}
+
+	balances, err := q.GetStatementBalances(ctx, db.GetStatementBalancesParams{
+		ToTime:    arg.ToTime,
+		FromTime:  arg.FromTime,
+		AccountID: arg.AccountID,
+	})
+	if err != nil {
+		return err
+	}
+
+	var total, count int64
+	err = tx.QueryRowContext(ctx, statementTotals, arg.AccountID, arg.FromTime, arg.ToTime).Scan(&total, &count)
+	if err != nil {
+		return err
+	}
+	if balances.OpeningBalance+total != balances.ClosingBalance || count != balances.EntryCount {
+		return fmt.Errorf("%w: entries of account %d add up to %d instead of the closing balance %d",
+			ErrStatementMismatch, arg.AccountID, balances.OpeningBalance+total, balances.ClosingBalance)
+	}
+
+	if err := summary(StatementSummary{Account: account, GetStatementBalancesRow: balances}); err != nil {
+		return err
+	}
+	return eachStatementEntry(ctx, tx, arg, entry)
+}
@@ -0,0 +1,94 @@
+package dbx
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	db "github.com/T-BO0/bank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatementTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createAccountOfType(t, db.AccountChecking, 10000)
+	other := createAccountOfType(t, db.AccountChecking, 10000)
+
+	_, err := store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: account.ID,
+		ToAccountID:   other.ID,
+		Amount:        3000,
+		Currency:      "USD",
+		Reference:     "RENT",
+	})
+	require.NoError(t, err)
+	_, err = store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: other.ID,
+		ToAccountID:   account.ID,
+		Amount:        500,
+		Currency:      "USD",
+	})
+	require.NoError(t, err)
+
+	entries, err := testQueries.ListAccountEntries(context.Background(), db.ListAccountEntriesParams{
+		AccountID:  account.ID,
+		LimitCount: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	// a period around both entries
+	var summary StatementSummary
+	var lines []StatementEntry
+	err = store.StatementTx(context.Background(), StatementParams{
+		AccountID: account.ID,
+		FromTime:  entries[0].CreatedAt.Add(-time.Hour),
+		ToTime:    entries[1].CreatedAt.Add(time.Hour),
+	}, func(s StatementSummary) error {
+		summary = s
+		return nil
+	}, func(entry StatementEntry) error {
+		lines = append(lines, entry)
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, account.ID, summary.Account.ID)
+	require.Equal(t, int64(10000), summary.OpeningBalance)
+	require.Equal(t, int64(7500), summary.ClosingBalance)
+	require.Equal(t, int64(3000), summary.TotalDebits)
+	require.Equal(t, int64(500), summary.TotalCredits)
+	require.Equal(t, int64(2), summary.EntryCount)
+
+	require.Len(t, lines, 2)
+	require.Equal(t, entries[0].ID, lines[0].ID)
+	require.Equal(t, "RENT", lines[0].Reference.String)
+	require.Equal(t, other.ID, lines[0].CounterpartyAccountID.Int64)
+	require.Equal(t, entries[1].ID, lines[1].ID)
+
+	// a period before the first entry has the opening balance all through and no entries
+	lines = nil
+	err = store.StatementTx(context.Background(), StatementParams{
+		AccountID: account.ID,
+		FromTime:  entries[0].CreatedAt.Add(-2 * time.Hour),
+		ToTime:    entries[0].CreatedAt.Add(-time.Hour),
+	}, func(s StatementSummary) error {
+		summary = s
+		return nil
+	}, func(entry StatementEntry) error {
+		lines = append(lines, entry)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(10000), summary.OpeningBalance)
+	require.Equal(t, int64(10000), summary.ClosingBalance)
+	require.Zero(t, summary.EntryCount)
+	require.Empty(t, lines)
+
+	err = store.StatementTx(context.Background(), StatementParams{AccountID: -1}, func(StatementSummary) error {
+		return nil
+	}, func(StatementEntry) error {
+		return nil
+	})
+	require.ErrorIs(t, err, db.ErrAccountNotFound)
+}
@@ -0,0 +1,31 @@
+// Package dbx holds the queries sqlc can't generate, transfer searches composed from the filters that are set
+// and statements read one row at a time. The generated queries and the transactions built on them stay in package db, which is sqlc's own,
+// and Store adds the queries of this package to them
+package dbx
+
+import (
+	"context"
+	"database/sql"
+
+	db "github.com/T-BO0/bank/db/sqlc"
+)
+
+// Store provides all functions of db.Store and the hand written queries of this package
+type Store interface {
+	db.Store
+	SearchTransfers(ctx context.Context, arg SearchTransfersParams) ([]db.Transfer, error)
+	StatementTx(ctx context.Context, arg StatementParams, summary func(StatementSummary) error, entry func(StatementEntry) error) error
+}
+
+// SQLStore wraps the store of package db and runs the queries of this package on the same connection
+type SQLStore struct {
+	db.Store
+	conn *sql.DB
+}
+
+func NewStore(conn *sql.DB) Store {
+	return &SQLStore{
+		Store: db.NewStore(conn),
+		conn:  conn,
+	}
+}
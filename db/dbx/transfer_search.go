@@ -0,0 +1,196 @@
+package dbx
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	db "github.com/T-BO0/bank/db/sqlc"
+)
+
+// Sort orders of SearchTransfers, a leading - sorts descending
+const (
+	TransferSortOldest   = "created_at"
+	TransferSortNewest   = "-created_at"
+	TransferSortSmallest = "amount"
+	TransferSortLargest  = "-amount"
+)
+
+// transferSortColumns maps each sort order to the column it sorts by and whether it is descending
+// Only these columns ever get into the ORDER BY of a search
+var transferSortColumns = map[string]struct {
+	column string
+	desc   bool
+}{
+	TransferSortOldest:   {column: "created_at"},
+	TransferSortNewest:   {column: "created_at", desc: true},
+	TransferSortSmallest: {column: "amount"},
+	TransferSortLargest:  {column: "amount", desc: true},
+}
+
+// SearchTransfersParams filters a search over transfers, every set filter has to match
+// A non empty Owner keeps the transfers in or out of an account of Owner, AccountID matches either side of a transfer
+// and CounterpartyAccountID the other side of AccountID, or either side without it. FromTime is inclusive and ToTime
+// exclusive, MinAmount and MaxAmount are inclusive and in minor units of the transfer currency.
+// Sort defaults to TransferSortNewest. Pages are keyset based, a non zero AfterID starts the page after the transfer
+// with that ID, whose sort key is AfterCreatedAt or AfterAmount
+type SearchTransfersParams struct {
+	Owner                 string         `json:"owner"`
+	AccountID             sql.NullInt64  `json:"accountId"`
+	CounterpartyAccountID sql.NullInt64  `json:"counterpartyAccountId"`
+	FromTime              sql.NullTime   `json:"fromTime"`
+	ToTime                sql.NullTime   `json:"toTime"`
+	MinAmount             sql.NullInt64  `json:"minAmount"`
+	MaxAmount             sql.NullInt64  `json:"maxAmount"`
+	Currency              sql.NullString `json:"currency"`
+	Reference             sql.NullString `json:"reference"`
+	Sort                  string         `json:"sort"`
+	AfterID               int64          `json:"afterId"`
+	AfterCreatedAt        time.Time      `json:"afterCreatedAt"`
+	AfterAmount           int64          `json:"afterAmount"`
+	Limit                 int32          `json:"limit"`
+}
+
+// transferSearchColumns are the columns of a transfer in the order db.Transfer is scanned in
+const transferSearchColumns = `id, from_account_id, to_account_id, amount, created_at, currency, to_amount, to_currency, fx_rate, reversal_of, reversal_reason, fee, fee_account_id, description, reference, metadata`
+
+// transferSearchQuery collects the conditions of a search, values only ever go in as placeholders
+type transferSearchQuery struct {
+	conditions []string
+	args       []interface{}
+}
+
+// arg adds a value to the query and returns its placeholder
+func (query *transferSearchQuery) arg(value interface{}) string {
+	query.args = append(query.args, value)
+	return "$" + strconv.Itoa(len(query.args))
+}
+
+// where adds a condition to the query
+func (query *transferSearchQuery) where(condition string) {
+	query.conditions = append(query.conditions, condition)
+}
+
+// buildSearchTransfers composes the sql and the arguments of a search
+func buildSearchTransfers(arg SearchTransfersParams) (string, []interface{}, error) {
+	if arg.Sort == "" {
+		arg.Sort = TransferSortNewest
+	}
+	sort, ok := transferSortColumns[arg.Sort]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown transfer sort order %q", arg.Sort)
+	}
+
+	query := &transferSearchQuery{}
+	if arg.Owner != "" {
+		query.where(fmt.Sprintf(`EXISTS (
+  SELECT 1
+  FROM accounts
+  WHERE accounts.owner = %s
+  AND accounts.id IN (transfers.from_account_id, transfers.to_account_id)
+)`, query.arg(arg.Owner)))
+	}
+	switch {
+	case arg.AccountID.Valid && arg.CounterpartyAccountID.Valid:
+		account, counterparty := query.arg(arg.AccountID.Int64), query.arg(arg.CounterpartyAccountID.Int64)
+		query.where(fmt.Sprintf("((from_account_id = %[1]s AND to_account_id = %[2]s) OR (from_account_id = %[2]s AND to_account_id = %[1]s))",
+			account, counterparty))
+	case arg.AccountID.Valid:
+		account := query.arg(arg.AccountID.Int64)
+		query.where(fmt.Sprintf("(from_account_id = %[1]s OR to_account_id = %[1]s)", account))
+	case arg.CounterpartyAccountID.Valid:
+		counterparty := query.arg(arg.CounterpartyAccountID.Int64)
+		query.where(fmt.Sprintf("(from_account_id = %[1]s OR to_account_id = %[1]s)", counterparty))
+	}
+	if arg.FromTime.Valid {
+		query.where("created_at >= " + query.arg(arg.FromTime.Time))
+	}
+	if arg.ToTime.Valid {
+		query.where("created_at < " + query.arg(arg.ToTime.Time))
+	}
+	if arg.MinAmount.Valid {
+		query.where("amount >= " + query.arg(arg.MinAmount.Int64))
+	}
+	if arg.MaxAmount.Valid {
+		query.where("amount <= " + query.arg(arg.MaxAmount.Int64))
+	}
+	if arg.Currency.Valid {
+		query.where("currency = " + query.arg(arg.Currency.String))
+	}
+	if arg.Reference.Valid {
+		query.where("reference = " + query.arg(arg.Reference.String))
+	}
+
+	comparison, direction := ">", "ASC"
+	if sort.desc {
+		comparison, direction = "<", "DESC"
+	}
+	if arg.AfterID > 0 {
+		var after string
+		if sort.column == "amount" {
+			after = query.arg(arg.AfterAmount) + "::bigint"
+		} else {
+			after = query.arg(arg.AfterCreatedAt) + "::timestamp"
+		}
+		query.where(fmt.Sprintf("(%s, id) %s (%s, %s::bigint)", sort.column, comparison, after, query.arg(arg.AfterID)))
+	}
+
+	var text strings.Builder
+	text.WriteString("SELECT " + transferSearchColumns + "\nFROM transfers\n")
+	if len(query.conditions) > 0 {
+		text.WriteString("WHERE " + strings.Join(query.conditions, "\nAND ") + "\n")
+	}
+	fmt.Fprintf(&text, "ORDER BY %[1]s %[2]s, id %[2]s\nLIMIT %[3]s", sort.column, direction, query.arg(arg.Limit))
+	return text.String(), query.args, nil
+}
+
+// ANCHOR - SearchTransfers returns a page of the transfers matching all set filters of arg
+// The query is composed from the set filters, every value is passed as a placeholder and the sort column
+// comes from a fixed list, so no input ever ends up in the sql text
+func (store *SQLStore) SearchTransfers(ctx context.Context, arg SearchTransfersParams) ([]db.Transfer, error) {
+	query, args, err := buildSearchTransfers(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := store.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []db.Transfer{}
+	for rows.Next() {
+		var i db.Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.Currency,
+			&i.ToAmount,
+			&i.ToCurrency,
+			&i.FxRate,
+			&i.ReversalOf,
+			&i.ReversalReason,
+			&i.Fee,
+			&i.FeeAccountID,
+			&i.Description,
+			&i.Reference,
+			&i.Metadata,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,118 @@
+package dbx
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	db "github.com/T-BO0/bank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildSearchTransfers(t *testing.T) {
+	query, args, err := buildSearchTransfers(SearchTransfersParams{Limit: 10})
+	require.NoError(t, err)
+	require.NotContains(t, query, "WHERE")
+	require.Contains(t, query, "ORDER BY created_at DESC, id DESC\nLIMIT $1")
+	require.Equal(t, []interface{}{int32(10)}, args)
+
+	// values only ever end up in the arguments
+	owner := "x'; DROP TABLE transfers; --"
+	query, args, err = buildSearchTransfers(SearchTransfersParams{
+		Owner:                 owner,
+		AccountID:             sql.NullInt64{Int64: 1, Valid: true},
+		CounterpartyAccountID: sql.NullInt64{Int64: 2, Valid: true},
+		MinAmount:             sql.NullInt64{Int64: 100, Valid: true},
+		Currency:              sql.NullString{String: "USD", Valid: true},
+		Sort:                  TransferSortLargest,
+		AfterID:               7,
+		AfterAmount:           500,
+		Limit:                 5,
+	})
+	require.NoError(t, err)
+	require.NotContains(t, query, owner)
+	require.Contains(t, query, "accounts.owner = $1")
+	require.Contains(t, query, "((from_account_id = $2 AND to_account_id = $3) OR (from_account_id = $3 AND to_account_id = $2))")
+	require.Contains(t, query, "amount >= $4")
+	require.Contains(t, query, "currency = $5")
+	require.Contains(t, query, "(amount, id) < ($6::bigint, $7::bigint)")
+	require.Contains(t, query, "ORDER BY amount DESC, id DESC\nLIMIT $8")
+	require.Equal(t, []interface{}{owner, int64(1), int64(2), int64(100), "USD", int64(500), int64(7), int32(5)}, args)
+
+	_, _, err = buildSearchTransfers(SearchTransfersParams{Sort: "id; DROP TABLE transfers", Limit: 5})
+	require.Error(t, err)
+}
+
+func TestSearchTransfers(t *testing.T) {
+	store := NewStore(testDB)
+	account := createAccountOfType(t, db.AccountChecking, 100000)
+	other := createAccountOfType(t, db.AccountChecking, 100000)
+	third := createAccountOfType(t, db.AccountChecking, 100000)
+
+	var transfers []db.Transfer
+	for _, leg := range []struct {
+		from, to db.Account
+		amount   int64
+	}{
+		{from: account, to: other, amount: 1000},
+		{from: other, to: account, amount: 3000},
+		{from: account, to: third, amount: 2000},
+	} {
+		result, err := store.TransferTx(context.Background(), db.TransferTxParams{
+			FromAccountID: leg.from.ID,
+			ToAccountID:   leg.to.ID,
+			Amount:        leg.amount,
+			Currency:      "USD",
+		})
+		require.NoError(t, err)
+		transfers = append(transfers, result.Transfer)
+	}
+
+	// both sides of the account, newest first
+	found, err := store.SearchTransfers(context.Background(), SearchTransfersParams{
+		Owner:     account.Owner,
+		AccountID: sql.NullInt64{Int64: account.ID, Valid: true},
+		Limit:     10,
+	})
+	require.NoError(t, err)
+	require.Len(t, found, 3)
+	require.Equal(t, transfers[2].ID, found[0].ID)
+	require.Equal(t, transfers[0].ID, found[2].ID)
+
+	// the counterparty narrows it to the transfers between the two accounts
+	found, err = store.SearchTransfers(context.Background(), SearchTransfersParams{
+		Owner:                 account.Owner,
+		AccountID:             sql.NullInt64{Int64: account.ID, Valid: true},
+		CounterpartyAccountID: sql.NullInt64{Int64: other.ID, Valid: true},
+		MinAmount:             sql.NullInt64{Int64: 1500, Valid: true},
+		Currency:              sql.NullString{String: "USD", Valid: true},
+		FromTime:              sql.NullTime{Time: db.BankDate(time.Now()), Valid: true},
+		Limit:                 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	require.Equal(t, transfers[1].ID, found[0].ID)
+
+	// largest first, paged after the first one
+	found, err = store.SearchTransfers(context.Background(), SearchTransfersParams{
+		Owner:       account.Owner,
+		Sort:        TransferSortLargest,
+		AfterID:     transfers[1].ID,
+		AfterAmount: transfers[1].Amount,
+		Limit:       10,
+	})
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	require.Equal(t, transfers[2].ID, found[0].ID)
+	require.Equal(t, transfers[0].ID, found[1].ID)
+
+	// the transfers of other users stay out
+	found, err = store.SearchTransfers(context.Background(), SearchTransfersParams{
+		Owner:     third.Owner,
+		AccountID: sql.NullInt64{Int64: other.ID, Valid: true},
+		Limit:     10,
+	})
+	require.NoError(t, err)
+	require.Empty(t, found)
+}
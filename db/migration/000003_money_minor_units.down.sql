@@ -0,0 +1,13 @@
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "currency";
+
+ALTER TABLE "transfers" ALTER COLUMN "amount" TYPE DOUBLE PRECISION USING "amount" / 100.0;
+
+ALTER TABLE "entries" ALTER COLUMN "amount" TYPE DOUBLE PRECISION USING "amount" / 100.0;
+
+ALTER TABLE "accounts" ALTER COLUMN "balance" TYPE DOUBLE PRECISION USING "balance" / 100.0;
+
+COMMENT ON COLUMN "accounts"."balance" IS NULL;
+
+COMMENT ON COLUMN "entries"."amount" IS 'can be negative or positive';
+
+COMMENT ON COLUMN "transfers"."amount" IS 'can not be negative';
@@ -0,0 +1,20 @@
+-- every supported currency (USD, EUR, GEL) has two minor unit digits
+ALTER TABLE "accounts" ALTER COLUMN "balance" TYPE BIGINT USING ROUND("balance" * 100)::BIGINT;
+
+ALTER TABLE "entries" ALTER COLUMN "amount" TYPE BIGINT USING ROUND("amount" * 100)::BIGINT;
+
+ALTER TABLE "transfers" ALTER COLUMN "amount" TYPE BIGINT USING ROUND("amount" * 100)::BIGINT;
+
+ALTER TABLE "transfers" ADD COLUMN "currency" varchar;
+
+UPDATE "transfers" SET "currency" = "accounts"."currency"
+FROM "accounts"
+WHERE "accounts"."id" = "transfers"."from_account_id";
+
+ALTER TABLE "transfers" ALTER COLUMN "currency" SET NOT NULL;
+
+COMMENT ON COLUMN "accounts"."balance" IS 'in minor units of the account currency';
+
+COMMENT ON COLUMN "entries"."amount" IS 'in minor units, can be negative or positive';
+
+COMMENT ON COLUMN "transfers"."amount" IS 'in minor units, can not be negative';
@@ -0,0 +1,3 @@
+ALTER TABLE IF EXISTS "transfers" DROP CONSTRAINT IF EXISTS "amount_positive";
+
+ALTER TABLE IF EXISTS "accounts" DROP CONSTRAINT IF EXISTS "balance_non_negative";
@@ -0,0 +1,3 @@
+ALTER TABLE "accounts" ADD CONSTRAINT "balance_non_negative" CHECK ("balance" >= 0);
+
+ALTER TABLE "transfers" ADD CONSTRAINT "amount_positive" CHECK ("amount" > 0);
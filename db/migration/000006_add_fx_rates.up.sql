@@ -0,0 +1,26 @@
+CREATE TABLE "fx_rates" (
+  "base_currency" varchar NOT NULL,
+  "quote_currency" varchar NOT NULL,
+  "rate" NUMERIC(20, 10) NOT NULL,
+  "updated_at" timestamp NOT NULL DEFAULT (now() AT TIME ZONE 'UTC' + INTERVAL '4 hours'),
+  PRIMARY KEY ("base_currency", "quote_currency"),
+  CONSTRAINT "rate_positive" CHECK ("rate" > 0)
+);
+
+COMMENT ON COLUMN "fx_rates"."rate" IS 'units of quote currency for one unit of base currency';
+
+ALTER TABLE "transfers" ADD COLUMN "to_amount" BIGINT;
+
+ALTER TABLE "transfers" ADD COLUMN "to_currency" varchar;
+
+ALTER TABLE "transfers" ADD COLUMN "fx_rate" NUMERIC(20, 10) NOT NULL DEFAULT 1;
+
+UPDATE "transfers" SET "to_amount" = "amount", "to_currency" = "currency";
+
+ALTER TABLE "transfers" ALTER COLUMN "to_amount" SET NOT NULL;
+
+ALTER TABLE "transfers" ALTER COLUMN "to_currency" SET NOT NULL;
+
+COMMENT ON COLUMN "transfers"."to_amount" IS 'in minor units of to_currency, credited to the to account';
+
+COMMENT ON COLUMN "transfers"."fx_rate" IS 'applied rate from currency to to_currency';
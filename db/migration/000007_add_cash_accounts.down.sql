@@ -0,0 +1,12 @@
+ALTER TABLE IF EXISTS "accounts" DROP CONSTRAINT IF EXISTS "balance_non_negative";
+
+DELETE FROM "entries" WHERE "account_id" IN (SELECT "id" FROM "accounts" WHERE "owner" = 'bank_cash');
+
+DELETE FROM "transfers" WHERE "from_account_id" IN (SELECT "id" FROM "accounts" WHERE "owner" = 'bank_cash')
+OR "to_account_id" IN (SELECT "id" FROM "accounts" WHERE "owner" = 'bank_cash');
+
+DELETE FROM "accounts" WHERE "owner" = 'bank_cash';
+
+DELETE FROM "users" WHERE "username" = 'bank_cash';
+
+ALTER TABLE "accounts" ADD CONSTRAINT "balance_non_negative" CHECK ("balance" >= 0);
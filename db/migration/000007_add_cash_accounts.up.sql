@@ -0,0 +1,10 @@
+-- internal owners contain an underscore, which usernames created through the API can not
+INSERT INTO "users" ("username", "password_hash", "full_name", "email")
+VALUES ('bank_cash', '!', 'Bank cash and suspense', 'cash@bank.internal');
+
+INSERT INTO "accounts" ("owner", "balance", "currency")
+VALUES ('bank_cash', 0, 'USD'), ('bank_cash', 0, 'EUR'), ('bank_cash', 0, 'GEL');
+
+ALTER TABLE "accounts" DROP CONSTRAINT "balance_non_negative";
+
+ALTER TABLE "accounts" ADD CONSTRAINT "balance_non_negative" CHECK ("balance" >= 0 OR "owner" LIKE 'bank\_%');
@@ -0,0 +1,9 @@
+ALTER TABLE "transfers" ADD COLUMN "reversal_of" bigint REFERENCES "transfers" ("id");
+
+ALTER TABLE "transfers" ADD COLUMN "reversal_reason" varchar;
+
+CREATE UNIQUE INDEX ON "transfers" ("reversal_of");
+
+ALTER TABLE "transfers" ADD CONSTRAINT "reversal_has_reason" CHECK ("reversal_of" IS NULL OR "reversal_reason" IS NOT NULL);
+
+COMMENT ON COLUMN "transfers"."reversal_of" IS 'the transfer this one compensates, a transfer can be reversed only once';
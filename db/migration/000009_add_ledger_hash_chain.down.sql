@@ -0,0 +1,15 @@
+DROP TRIGGER IF EXISTS "transfers_no_truncate" ON "transfers";
+
+DROP TRIGGER IF EXISTS "transfers_immutable" ON "transfers";
+
+DROP TRIGGER IF EXISTS "entries_no_truncate" ON "entries";
+
+DROP TRIGGER IF EXISTS "entries_immutable" ON "entries";
+
+DROP FUNCTION IF EXISTS "ledger_immutable"();
+
+ALTER TABLE IF EXISTS "entries" DROP COLUMN IF EXISTS "hash";
+
+ALTER TABLE IF EXISTS "entries" DROP COLUMN IF EXISTS "prev_hash";
+
+ALTER TABLE IF EXISTS "entries" DROP COLUMN IF EXISTS "transfer_id";
@@ -0,0 +1,35 @@
+ALTER TABLE "entries" ADD COLUMN "transfer_id" bigint REFERENCES "transfers" ("id");
+
+ALTER TABLE "entries" ADD COLUMN "prev_hash" bytea;
+
+ALTER TABLE "entries" ADD COLUMN "hash" bytea;
+
+-- entries written before the chain existed stay unhashed, NOT VALID only enforces it on new rows
+ALTER TABLE "entries" ADD CONSTRAINT "entry_chained" CHECK ("transfer_id" IS NOT NULL AND "hash" IS NOT NULL) NOT VALID;
+
+CREATE UNIQUE INDEX ON "entries" ("hash");
+
+CREATE INDEX ON "entries" ("transfer_id");
+
+COMMENT ON COLUMN "entries"."prev_hash" IS 'hash of the previous chained entry of the account, null for the first one';
+
+COMMENT ON COLUMN "entries"."hash" IS 'sha256 over prev_hash, the entry and its transfer';
+
+CREATE FUNCTION "ledger_immutable"() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'ledger table % is append only', TG_TABLE_NAME
+    USING ERRCODE = 'restrict_violation';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "entries_immutable" BEFORE UPDATE OR DELETE ON "entries"
+  FOR EACH ROW EXECUTE FUNCTION "ledger_immutable"();
+
+CREATE TRIGGER "entries_no_truncate" BEFORE TRUNCATE ON "entries"
+  FOR EACH STATEMENT EXECUTE FUNCTION "ledger_immutable"();
+
+CREATE TRIGGER "transfers_immutable" BEFORE UPDATE OR DELETE ON "transfers"
+  FOR EACH ROW EXECUTE FUNCTION "ledger_immutable"();
+
+CREATE TRIGGER "transfers_no_truncate" BEFORE TRUNCATE ON "transfers"
+  FOR EACH STATEMENT EXECUTE FUNCTION "ledger_immutable"();
@@ -0,0 +1,42 @@
+ALTER TABLE "accounts" ADD COLUMN "held_amount" BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE "accounts" ADD COLUMN "available_balance" BIGINT GENERATED ALWAYS AS ("balance" - "held_amount") STORED;
+
+ALTER TABLE "accounts" ADD CONSTRAINT "held_non_negative" CHECK ("held_amount" >= 0);
+
+ALTER TABLE "accounts" ADD CONSTRAINT "available_non_negative" CHECK ("balance" - "held_amount" >= 0 OR "owner" LIKE 'bank\_%');
+
+COMMENT ON COLUMN "accounts"."held_amount" IS 'sum of the authorized holds against the account, in minor units';
+
+COMMENT ON COLUMN "accounts"."available_balance" IS 'balance minus held_amount, what a new transfer can spend';
+
+CREATE TABLE "holds" (
+  "id" BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" BIGINT NOT NULL,
+  "currency" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'authorized',
+  "captured_amount" BIGINT NOT NULL DEFAULT 0,
+  "transfer_id" bigint,
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT (now() AT TIME ZONE 'UTC' + INTERVAL '4 hours'),
+  CONSTRAINT "hold_amount_positive" CHECK ("amount" > 0),
+  CONSTRAINT "captured_within_hold" CHECK ("captured_amount" >= 0 AND "captured_amount" <= "amount")
+);
+
+CREATE INDEX ON "holds" ("from_account_id");
+
+CREATE INDEX ON "holds" ("expires_at") WHERE "status" = 'authorized';
+
+COMMENT ON COLUMN "holds"."amount" IS 'authorized amount in minor units, reserved on the from account while authorized';
+
+COMMENT ON COLUMN "holds"."status" IS 'authorized, captured, voided or expired';
+
+COMMENT ON COLUMN "holds"."transfer_id" IS 'the transfer a capture created';
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
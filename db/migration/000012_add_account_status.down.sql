@@ -0,0 +1,13 @@
+DROP TRIGGER IF EXISTS "accounts_no_delete" ON "accounts";
+
+DROP FUNCTION IF EXISTS "accounts_not_deleted"();
+
+ALTER TABLE IF EXISTS "accounts" DROP CONSTRAINT IF EXISTS "closed_account_empty";
+
+ALTER TABLE IF EXISTS "accounts" DROP CONSTRAINT IF EXISTS "account_status_valid";
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "status_changed_at";
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "status_reason";
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "status";
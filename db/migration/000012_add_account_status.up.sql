@@ -0,0 +1,23 @@
+ALTER TABLE "accounts" ADD COLUMN "status" varchar NOT NULL DEFAULT 'active';
+
+ALTER TABLE "accounts" ADD COLUMN "status_reason" varchar;
+
+ALTER TABLE "accounts" ADD COLUMN "status_changed_at" timestamptz;
+
+ALTER TABLE "accounts" ADD CONSTRAINT "account_status_valid" CHECK ("status" IN ('active', 'frozen', 'closed'));
+
+ALTER TABLE "accounts" ADD CONSTRAINT "closed_account_empty" CHECK ("status" <> 'closed' OR ("balance" = 0 AND "held_amount" = 0));
+
+COMMENT ON COLUMN "accounts"."status" IS 'active, frozen or closed, only active accounts send and receive money';
+
+COMMENT ON COLUMN "accounts"."status_reason" IS 'why the status was last changed';
+
+CREATE FUNCTION "accounts_not_deleted"() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'accounts are closed, not deleted'
+    USING ERRCODE = 'restrict_violation';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "accounts_no_delete" BEFORE DELETE ON "accounts"
+  FOR EACH ROW EXECUTE FUNCTION "accounts_not_deleted"();
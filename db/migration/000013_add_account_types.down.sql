@@ -0,0 +1,15 @@
+ALTER TABLE IF EXISTS "accounts" DROP CONSTRAINT IF EXISTS "available_non_negative";
+
+ALTER TABLE IF EXISTS "accounts" ADD CONSTRAINT "available_non_negative" CHECK ("balance" - "held_amount" >= 0 OR "owner" LIKE 'bank\_%');
+
+ALTER TABLE IF EXISTS "accounts" DROP CONSTRAINT IF EXISTS "balance_non_negative";
+
+ALTER TABLE IF EXISTS "accounts" ADD CONSTRAINT "balance_non_negative" CHECK ("balance" >= 0 OR "owner" LIKE 'bank\_%');
+
+ALTER TABLE IF EXISTS "accounts" DROP CONSTRAINT IF EXISTS "owner_currency_type_unique";
+
+ALTER TABLE IF EXISTS "accounts" ADD CONSTRAINT "owner_currency_unique" UNIQUE ("owner", "currency");
+
+ALTER TABLE IF EXISTS "accounts" DROP CONSTRAINT IF EXISTS "account_type_valid";
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "type";
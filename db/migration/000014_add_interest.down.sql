@@ -0,0 +1,16 @@
+DROP TABLE IF EXISTS "interest_accruals";
+
+-- interest accounts with ledger history stay, the ledger is append only
+ALTER TABLE "accounts" DISABLE TRIGGER "accounts_no_delete";
+
+DELETE FROM "accounts" a WHERE a."owner" = 'bank_interest'
+AND NOT EXISTS (SELECT 1 FROM "entries" e WHERE e."account_id" = a."id");
+
+ALTER TABLE "accounts" ENABLE TRIGGER "accounts_no_delete";
+
+DELETE FROM "users" u WHERE u."username" = 'bank_interest'
+AND NOT EXISTS (SELECT 1 FROM "accounts" a WHERE a."owner" = u."username");
+
+ALTER TABLE IF EXISTS "accounts" DROP CONSTRAINT IF EXISTS "interest_rate_valid";
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "interest_rate";
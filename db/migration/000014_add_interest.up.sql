@@ -0,0 +1,38 @@
+ALTER TABLE "accounts" ADD COLUMN "interest_rate" NUMERIC(9, 6) NOT NULL DEFAULT 0;
+
+ALTER TABLE "accounts" ADD CONSTRAINT "interest_rate_valid" CHECK ("interest_rate" >= 0 AND "interest_rate" < 1);
+
+COMMENT ON COLUMN "accounts"."interest_rate" IS 'annual interest rate as a fraction, 0.025 is 2.5%';
+
+CREATE TABLE "interest_accruals" (
+  "id" BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "accrual_date" date NOT NULL,
+  "balance" BIGINT NOT NULL,
+  "rate" NUMERIC(9, 6) NOT NULL,
+  "day_count" varchar NOT NULL,
+  "amount" NUMERIC(24, 8) NOT NULL,
+  "transfer_id" bigint,
+  "posted_at" timestamptz,
+  "created_at" timestamp NOT NULL DEFAULT (now() AT TIME ZONE 'UTC' + INTERVAL '4 hours'),
+  CONSTRAINT "interest_accrued_once" UNIQUE ("account_id", "accrual_date")
+);
+
+CREATE INDEX ON "interest_accruals" ("account_id") WHERE "posted_at" IS NULL;
+
+COMMENT ON COLUMN "interest_accruals"."balance" IS 'end of day balance the interest accrued on, in minor units';
+
+COMMENT ON COLUMN "interest_accruals"."amount" IS 'interest of the day in fractional minor units, rounded once the month is posted';
+
+COMMENT ON COLUMN "interest_accruals"."transfer_id" IS 'the posting transfer, null when the month rounded to nothing';
+
+ALTER TABLE "interest_accruals" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "interest_accruals" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+-- interest is paid out of the bank's interest expense accounts, which go below zero as it is posted
+INSERT INTO "users" ("username", "password_hash", "full_name", "email")
+VALUES ('bank_interest', '!', 'Bank interest expense', 'interest@bank.internal');
+
+INSERT INTO "accounts" ("owner", "balance", "currency")
+VALUES ('bank_interest', 0, 'USD'), ('bank_interest', 0, 'EUR'), ('bank_interest', 0, 'GEL');
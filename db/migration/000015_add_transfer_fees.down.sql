@@ -0,0 +1,18 @@
+ALTER TABLE IF EXISTS "transfers" DROP CONSTRAINT IF EXISTS "fee_has_account";
+
+ALTER TABLE IF EXISTS "transfers" DROP CONSTRAINT IF EXISTS "fee_non_negative";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "fee_account_id";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "fee";
+
+-- revenue accounts with ledger history stay, the ledger is append only
+ALTER TABLE "accounts" DISABLE TRIGGER "accounts_no_delete";
+
+DELETE FROM "accounts" a WHERE a."owner" = 'bank_revenue'
+AND NOT EXISTS (SELECT 1 FROM "entries" e WHERE e."account_id" = a."id");
+
+ALTER TABLE "accounts" ENABLE TRIGGER "accounts_no_delete";
+
+DELETE FROM "users" u WHERE u."username" = 'bank_revenue'
+AND NOT EXISTS (SELECT 1 FROM "accounts" a WHERE a."owner" = u."username");
@@ -0,0 +1,20 @@
+ALTER TABLE "transfers" ADD COLUMN "fee" BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE "transfers" ADD COLUMN "fee_account_id" bigint;
+
+ALTER TABLE "transfers" ADD CONSTRAINT "fee_non_negative" CHECK ("fee" >= 0);
+
+ALTER TABLE "transfers" ADD CONSTRAINT "fee_has_account" CHECK ("fee" = 0 OR "fee_account_id" IS NOT NULL);
+
+COMMENT ON COLUMN "transfers"."fee" IS 'in minor units of currency, debited from the from account on top of amount';
+
+COMMENT ON COLUMN "transfers"."fee_account_id" IS 'the revenue account credited with the fee';
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("fee_account_id") REFERENCES "accounts" ("id");
+
+-- fees are credited to the bank's revenue accounts
+INSERT INTO "users" ("username", "password_hash", "full_name", "email")
+VALUES ('bank_revenue', '!', 'Bank fee revenue', 'revenue@bank.internal');
+
+INSERT INTO "accounts" ("owner", "balance", "currency")
+VALUES ('bank_revenue', 0, 'USD'), ('bank_revenue', 0, 'EUR'), ('bank_revenue', 0, 'GEL');
@@ -0,0 +1,29 @@
+CREATE TABLE "transfer_limits" (
+  "id" BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "username" varchar,
+  "account_id" bigint,
+  "currency" varchar NOT NULL,
+  "max_per_transfer" BIGINT,
+  "max_daily_amount" BIGINT,
+  "max_daily_count" BIGINT,
+  "max_monthly_amount" BIGINT,
+  "max_monthly_count" BIGINT,
+  "updated_at" timestamp NOT NULL DEFAULT (now() AT TIME ZONE 'UTC' + INTERVAL '4 hours'),
+  CONSTRAINT "limit_has_one_scope" CHECK (("username" IS NULL) <> ("account_id" IS NULL)),
+  CONSTRAINT "account_limit_unique" UNIQUE ("account_id"),
+  CONSTRAINT "user_limit_unique" UNIQUE ("username", "currency"),
+  CONSTRAINT "limits_non_negative" CHECK (
+    "max_per_transfer" >= 0 AND "max_daily_amount" >= 0 AND "max_daily_count" >= 0
+    AND "max_monthly_amount" >= 0 AND "max_monthly_count" >= 0
+  )
+);
+
+COMMENT ON COLUMN "transfer_limits"."max_per_transfer" IS 'in minor units of currency, null means no limit like every max_ column';
+
+COMMENT ON COLUMN "transfer_limits"."max_daily_count" IS 'outgoing transfers per bank day, reversals do not count';
+
+ALTER TABLE "transfer_limits" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "transfer_limits" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+CREATE INDEX ON "transfers" ("from_account_id", "created_at");
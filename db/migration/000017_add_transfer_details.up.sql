@@ -0,0 +1,19 @@
+ALTER TABLE "transfers" ADD COLUMN "description" varchar;
+
+ALTER TABLE "transfers" ADD COLUMN "reference" varchar;
+
+ALTER TABLE "transfers" ADD COLUMN "metadata" jsonb NOT NULL DEFAULT '{}';
+
+ALTER TABLE "transfers" ADD CONSTRAINT "description_length" CHECK (char_length("description") <= 140);
+
+ALTER TABLE "transfers" ADD CONSTRAINT "reference_length" CHECK (char_length("reference") <= 35);
+
+ALTER TABLE "transfers" ADD CONSTRAINT "metadata_is_object" CHECK (jsonb_typeof("metadata") = 'object');
+
+CREATE INDEX ON "transfers" ("reference") WHERE "reference" IS NOT NULL;
+
+COMMENT ON COLUMN "transfers"."description" IS 'free text shown to both parties, what the payment is for';
+
+COMMENT ON COLUMN "transfers"."reference" IS 'reference of the payer, like an invoice number, searchable';
+
+COMMENT ON COLUMN "transfers"."metadata" IS 'string keys and values the client attached to the transfer';
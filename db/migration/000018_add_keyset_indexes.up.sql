@@ -0,0 +1,7 @@
+CREATE INDEX ON "accounts" ("owner", "created_at", "id");
+
+CREATE INDEX ON "transfers" ("created_at", "id");
+
+CREATE INDEX ON "transfers" ("reference", "created_at", "id") WHERE "reference" IS NOT NULL;
+
+CREATE INDEX ON "entries" ("account_id", "created_at", "id");
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "transfers_amount_id_idx";
+
+DROP INDEX IF EXISTS "transfers_to_account_id_created_at_idx";
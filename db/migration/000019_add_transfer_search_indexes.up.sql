@@ -0,0 +1,3 @@
+CREATE INDEX ON "transfers" ("to_account_id", "created_at");
+
+CREATE INDEX ON "transfers" ("amount", "id");
@@ -0,0 +1 @@
+CREATE INDEX ON "holds" ("transfer_id");
@@ -2,9 +2,10 @@
 INSERT INTO accounts (
   owner,
   balance,
-  currency
+  currency,
+  type
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4
 )
 RETURNING *;
 
@@ -12,6 +13,11 @@ RETURNING *;
 SELECT * FROM accounts
 WHERE id = $1 LIMIT 1;
 
+-- name: GetAccountByOwnerAndCurrency :one
+SELECT * FROM accounts
+WHERE owner = $1 AND currency = $2
+LIMIT 1;
+
 -- name: GetAccountForUpdate :one
 SELECT * FROM accounts
 WHERE id = $1 LIMIT 1
@@ -23,11 +29,20 @@ ORDER BY id
 LIMIT $1
 OFFSET $2;
 
--- name: UpdateAccount :one
-UPDATE accounts 
-SET balance = $2
-WHERE id = $1
-RETURNING *;
+-- name: ListAccountByOwner :many
+SELECT * FROM accounts
+WHERE owner = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
+
+-- name: ListAccountByOwnerAfter :many
+-- keyset page of the accounts of owner that come after the cursor in (created_at, id) order
+SELECT * FROM accounts
+WHERE owner = sqlc.arg(owner)
+AND (created_at, id) > (sqlc.arg(after_created_at)::timestamp, sqlc.arg(after_id)::bigint)
+ORDER BY created_at, id
+LIMIT sqlc.arg(limit_count);
 
 -- name: AddAccountBalance :one
 UPDATE accounts 
@@ -35,6 +50,41 @@ SET balance = balance + sqlc.arg(amount)
 WHERE id = sqlc.arg(id)
 RETURNING *;
 
--- name: DeleteAccount :exec
-DELETE FROM accounts
-WHERE id = $1;
\ No newline at end of file
+-- name: UpdateAccountStatus :one
+UPDATE accounts
+SET status = $2,
+  status_reason = $3,
+  status_changed_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: CountAccounts :one
+SELECT COUNT(*) FROM accounts;
+
+-- name: AddAccountHeld :one
+UPDATE accounts
+SET held_amount = held_amount + sqlc.arg(amount)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: UpdateAccountInterestRate :one
+UPDATE accounts
+SET interest_rate = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: ListInterestBearingAccounts :many
+SELECT * FROM accounts
+WHERE interest_rate > 0
+  AND status <> 'closed'
+  AND owner NOT LIKE 'bank\_%'
+  AND id > $1
+ORDER BY id
+LIMIT $2;
+
+-- name: GetEndOfDayBalance :one
+SELECT (a.balance - COALESCE(SUM(e.amount), 0))::bigint AS balance
+FROM accounts a
+LEFT JOIN entries e ON e.account_id = a.id AND e.created_at >= sqlc.arg(end_of_day)
+WHERE a.id = sqlc.arg(account_id)
+GROUP BY a.id, a.balance;
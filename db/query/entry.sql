@@ -1,9 +1,12 @@
 -- name: CreateEntry :one
 INSERT INTO entries (
   account_id,
-  amount
+  amount,
+  transfer_id,
+  prev_hash,
+  hash
 ) VALUES (
-  $1, $2
+  $1, $2, $3, $4, $5
 )
 RETURNING *;
 
@@ -23,9 +26,43 @@ LIMIT 1;
 SELECT * 
 FROM entries
 WHERE account_id = $1
+ORDER BY id
 LIMIT $2
 OFFSET $3;
 
+-- name: ListAccountEntries :many
+-- balance_after is the balance of the account right after the entry, taken back from the current balance
+-- so it also holds for accounts opened with a balance. It is computed over all entries before filtering,
+-- in the (created_at, id) order the keyset pages follow
+WITH history AS (
+  SELECT entries.id, entries.account_id, entries.amount, entries.created_at, entries.transfer_id,
+    (accounts.balance - COALESCE(SUM(entries.amount) OVER (
+      ORDER BY entries.created_at, entries.id ROWS BETWEEN 1 FOLLOWING AND UNBOUNDED FOLLOWING
+    ), 0))::bigint AS balance_after
+  FROM entries
+  JOIN accounts ON accounts.id = entries.account_id
+  WHERE entries.account_id = sqlc.arg(account_id)
+)
+SELECT history.id, history.account_id, history.amount, history.created_at, history.transfer_id, history.balance_after,
+  transfers.description, transfers.reference,
+  counterparty.id AS counterparty_account_id, counterparty.owner AS counterparty_owner
+FROM history
+LEFT JOIN transfers ON transfers.id = history.transfer_id
+LEFT JOIN accounts AS counterparty ON counterparty.id = CASE
+  WHEN transfers.from_account_id = history.account_id THEN transfers.to_account_id
+  ELSE transfers.from_account_id
+END
+WHERE (sqlc.narg(from_time)::timestamp IS NULL OR history.created_at >= sqlc.narg(from_time))
+AND (sqlc.narg(to_time)::timestamp IS NULL OR history.created_at < sqlc.narg(to_time))
+AND (
+  sqlc.narg(direction)::varchar IS NULL
+  OR (sqlc.narg(direction) = 'debit' AND history.amount < 0)
+  OR (sqlc.narg(direction) = 'credit' AND history.amount > 0)
+)
+AND (history.created_at, history.id) > (sqlc.arg(after_created_at)::timestamp, sqlc.arg(after_id)::bigint)
+ORDER BY history.created_at, history.id
+LIMIT sqlc.arg(limit_count);
+
 -- name: ListEntry :many
 SELECT * 
 FROM entries
@@ -33,14 +70,19 @@ ORDER BY id
 LIMIT $1
 OFFSET $2;
 
--- name: UpdateEntry :one
-UPDATE entries 
-SET amount = $2
-WHERE id = $1
-RETURNING *;
-
--- name: DeleteEntry :exec
-DELETE 
+-- name: GetLastChainedEntry :one
+SELECT * 
 FROM entries
-WHERE id = $1;
+WHERE account_id = $1
+AND hash IS NOT NULL
+ORDER BY id DESC
+LIMIT 1;
 
+-- name: ListLedgerEntries :many
+SELECT sqlc.embed(entries), sqlc.embed(transfers)
+FROM entries
+JOIN transfers ON transfers.id = entries.transfer_id
+WHERE entries.account_id = $1
+AND entries.id > $2
+ORDER BY entries.id
+LIMIT $3;
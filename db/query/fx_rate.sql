@@ -0,0 +1,20 @@
+-- name: UpsertFxRate :one
+INSERT INTO fx_rates (
+  base_currency,
+  quote_currency,
+  rate
+) VALUES (
+  $1, $2, $3
+)
+ON CONFLICT (base_currency, quote_currency)
+DO UPDATE SET rate = EXCLUDED.rate, updated_at = (now() AT TIME ZONE 'UTC' + INTERVAL '4 hours')
+RETURNING *;
+
+-- name: GetFxRate :one
+SELECT * FROM fx_rates
+WHERE base_currency = $1 AND quote_currency = $2
+LIMIT 1;
+
+-- name: ListFxRates :many
+SELECT * FROM fx_rates
+ORDER BY base_currency, quote_currency;
@@ -0,0 +1,35 @@
+-- name: CreateHold :one
+INSERT INTO holds (
+  from_account_id,
+  to_account_id,
+  amount,
+  currency,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING *;
+
+-- name: GetHold :one
+SELECT * FROM holds
+WHERE id = $1 LIMIT 1;
+
+-- name: GetHoldForUpdate :one
+SELECT * FROM holds
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: UpdateHoldStatus :one
+UPDATE holds
+SET status = $2,
+  captured_amount = $3,
+  transfer_id = $4
+WHERE id = $1
+RETURNING *;
+
+-- name: ClaimExpiredHolds :many
+SELECT * FROM holds
+WHERE status = 'authorized' AND expires_at <= $1
+ORDER BY expires_at
+LIMIT $2
+FOR NO KEY UPDATE SKIP LOCKED;
@@ -0,0 +1,35 @@
+-- name: CreateInterestAccrual :one
+INSERT INTO interest_accruals (
+  account_id,
+  accrual_date,
+  balance,
+  rate,
+  day_count,
+  amount
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (account_id, accrual_date) DO NOTHING
+RETURNING *;
+
+-- name: GetLastInterestAccrualDate :one
+SELECT accrual_date FROM interest_accruals
+ORDER BY accrual_date DESC
+LIMIT 1;
+
+-- name: ListAccountsWithUnpostedInterest :many
+SELECT DISTINCT account_id FROM interest_accruals
+WHERE posted_at IS NULL AND accrual_date < $1
+ORDER BY account_id;
+
+-- name: ClaimUnpostedInterestAccruals :many
+SELECT * FROM interest_accruals
+WHERE account_id = $1 AND posted_at IS NULL AND accrual_date < $2
+ORDER BY accrual_date
+FOR UPDATE;
+
+-- name: MarkInterestAccrualsPosted :exec
+UPDATE interest_accruals
+SET transfer_id = sqlc.narg(transfer_id),
+  posted_at = now()
+WHERE id = ANY(sqlc.arg(ids)::bigint[]);
@@ -0,0 +1,36 @@
+-- name: ListBalanceMismatches :many
+SELECT
+  accounts.id,
+  accounts.owner,
+  accounts.currency,
+  accounts.balance,
+  COALESCE(SUM(entries.amount), 0)::bigint AS entries_total
+FROM accounts
+LEFT JOIN entries ON entries.account_id = accounts.id
+GROUP BY accounts.id
+HAVING accounts.balance <> COALESCE(SUM(entries.amount), 0)
+ORDER BY accounts.id;
+
+-- name: ListUnbalancedTransfers :many
+SELECT
+  transfers.id,
+  transfers.from_account_id,
+  transfers.to_account_id,
+  COUNT(entries.id) AS entry_count
+FROM transfers
+LEFT JOIN entries ON entries.transfer_id = transfers.id
+GROUP BY transfers.id
+HAVING COUNT(entries.id) <> CASE WHEN transfers.fee > 0 THEN 3 ELSE 2 END
+OR NOT bool_or(entries.account_id = transfers.from_account_id AND entries.amount = -(transfers.amount + transfers.fee))
+OR NOT bool_or(entries.account_id = transfers.to_account_id AND entries.amount = transfers.to_amount)
+OR (transfers.fee > 0 AND NOT bool_or(entries.account_id = transfers.fee_account_id AND entries.amount = transfers.fee))
+ORDER BY transfers.id;
+
+-- name: ListOrphanedEntries :many
+SELECT entries.*
+FROM entries
+LEFT JOIN transfers ON transfers.id = entries.transfer_id
+WHERE transfers.id IS NULL
+OR (entries.account_id NOT IN (transfers.from_account_id, transfers.to_account_id)
+  AND entries.account_id IS DISTINCT FROM transfers.fee_account_id)
+ORDER BY entries.id;
@@ -0,0 +1,13 @@
+-- name: GetStatementBalances :one
+-- the balances of an account at the start and the end of a period, taken back from the current balance,
+-- and the totals of the entries in the period
+SELECT
+  (accounts.balance - COALESCE(SUM(entries.amount), 0))::bigint AS opening_balance,
+  (accounts.balance - COALESCE(SUM(entries.amount) FILTER (WHERE entries.created_at >= sqlc.arg(to_time)::timestamp), 0))::bigint AS closing_balance,
+  COALESCE(-SUM(entries.amount) FILTER (WHERE entries.amount < 0 AND entries.created_at < sqlc.arg(to_time)), 0)::bigint AS total_debits,
+  COALESCE(SUM(entries.amount) FILTER (WHERE entries.amount > 0 AND entries.created_at < sqlc.arg(to_time)), 0)::bigint AS total_credits,
+  COUNT(entries.id) FILTER (WHERE entries.created_at < sqlc.arg(to_time)) AS entry_count
+FROM accounts
+LEFT JOIN entries ON entries.account_id = accounts.id AND entries.created_at >= sqlc.arg(from_time)::timestamp
+WHERE accounts.id = sqlc.arg(account_id)
+GROUP BY accounts.id;
@@ -1,19 +1,105 @@
+-- name: CountMonthlyWithdrawals :one
+-- a hold counts from its authorization, the transfer its capture booked is not counted again
+SELECT COUNT(*) FROM (
+  SELECT transfers.id FROM transfers
+  WHERE transfers.from_account_id = $1
+    AND transfers.reversal_of IS NULL
+    AND transfers.created_at >= date_trunc('month', now() AT TIME ZONE 'UTC' + INTERVAL '4 hours')
+    AND NOT EXISTS (SELECT 1 FROM holds WHERE holds.transfer_id = transfers.id)
+  UNION ALL
+  SELECT holds.id FROM holds
+  WHERE holds.from_account_id = $1
+    AND holds.status IN ('authorized', 'captured')
+    AND holds.created_at >= date_trunc('month', now() AT TIME ZONE 'UTC' + INTERVAL '4 hours')
+) AS withdrawals;
+
 -- name: CreateTransfer :one
 INSERT INTO transfers (
   from_account_id,
   to_account_id,
-  amount
+  amount,
+  currency,
+  to_amount,
+  to_currency,
+  fx_rate,
+  reversal_of,
+  reversal_reason,
+  fee,
+  fee_account_id,
+  description,
+  reference,
+  metadata
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
 )
 RETURNING *;
 
+-- name: GetAccountTransferUsage :one
+-- authorized and captured holds count from their authorization with what they reserved or captured,
+-- the transfers captures booked are not counted again
+WITH outgoing AS (
+  SELECT transfers.amount, transfers.created_at FROM transfers
+  WHERE transfers.from_account_id = $1
+    AND transfers.reversal_of IS NULL
+    AND transfers.created_at >= date_trunc('month', now() AT TIME ZONE 'UTC' + INTERVAL '4 hours')
+    AND NOT EXISTS (SELECT 1 FROM holds WHERE holds.transfer_id = transfers.id)
+  UNION ALL
+  SELECT CASE WHEN holds.status = 'captured' THEN holds.captured_amount ELSE holds.amount END, holds.created_at FROM holds
+  WHERE holds.from_account_id = $1
+    AND holds.status IN ('authorized', 'captured')
+    AND holds.created_at >= date_trunc('month', now() AT TIME ZONE 'UTC' + INTERVAL '4 hours')
+)
+SELECT
+  COUNT(*) FILTER (WHERE created_at >= date_trunc('day', now() AT TIME ZONE 'UTC' + INTERVAL '4 hours')) AS daily_count,
+  COALESCE(SUM(amount) FILTER (WHERE created_at >= date_trunc('day', now() AT TIME ZONE 'UTC' + INTERVAL '4 hours')), 0)::bigint AS daily_amount,
+  COUNT(*) AS monthly_count,
+  COALESCE(SUM(amount), 0)::bigint AS monthly_amount
+FROM outgoing;
+
+-- name: GetOwnerTransferUsage :one
+-- the usage of all accounts of owner in currency, holds count like in GetAccountTransferUsage
+WITH outgoing AS (
+  SELECT transfers.amount, transfers.created_at FROM transfers
+  JOIN accounts ON accounts.id = transfers.from_account_id
+  WHERE accounts.owner = $1
+    AND transfers.currency = $2
+    AND transfers.reversal_of IS NULL
+    AND transfers.created_at >= date_trunc('month', now() AT TIME ZONE 'UTC' + INTERVAL '4 hours')
+    AND NOT EXISTS (SELECT 1 FROM holds WHERE holds.transfer_id = transfers.id)
+  UNION ALL
+  SELECT CASE WHEN holds.status = 'captured' THEN holds.captured_amount ELSE holds.amount END, holds.created_at FROM holds
+  JOIN accounts ON accounts.id = holds.from_account_id
+  WHERE accounts.owner = $1
+    AND holds.currency = $2
+    AND holds.status IN ('authorized', 'captured')
+    AND holds.created_at >= date_trunc('month', now() AT TIME ZONE 'UTC' + INTERVAL '4 hours')
+)
+SELECT
+  COUNT(*) FILTER (WHERE created_at >= date_trunc('day', now() AT TIME ZONE 'UTC' + INTERVAL '4 hours')) AS daily_count,
+  COALESCE(SUM(amount) FILTER (WHERE created_at >= date_trunc('day', now() AT TIME ZONE 'UTC' + INTERVAL '4 hours')), 0)::bigint AS daily_amount,
+  COUNT(*) AS monthly_count,
+  COALESCE(SUM(amount), 0)::bigint AS monthly_amount
+FROM outgoing;
+
 -- name: GetTransfer :one
 SELECT * 
 FROM transfers
 WHERE id = $1 
 LIMIT 1;
 
+-- name: GetTransferForUpdate :one
+SELECT * 
+FROM transfers
+WHERE id = $1 
+LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: GetTransferReversal :one
+SELECT * 
+FROM transfers
+WHERE reversal_of = $1 
+LIMIT 1;
+
 -- name: GetTransferByToAccountId :one
 SELECT * 
 FROM transfers
@@ -55,21 +141,38 @@ AND to_account_id = $2
 LIMIT $3
 OFFSET $4;
 
+-- name: ListTransferByOwnerAfter :many
+-- keyset page of the transfers in or out of an account of owner, after the cursor in (created_at, id) order
+SELECT * 
+FROM transfers
+WHERE EXISTS (
+  SELECT 1
+  FROM accounts
+  WHERE accounts.owner = sqlc.arg(owner)::varchar
+  AND accounts.id IN (transfers.from_account_id, transfers.to_account_id)
+)
+AND (created_at, id) > (sqlc.arg(after_created_at)::timestamp, sqlc.arg(after_id)::bigint)
+ORDER BY created_at, id
+LIMIT sqlc.arg(limit_count);
+
+-- name: ListTransferByReference :many
+-- keyset page of the transfers with reference in or out of an account of owner, after the cursor in (created_at, id) order
+SELECT * 
+FROM transfers
+WHERE reference = sqlc.arg(reference)::varchar
+AND EXISTS (
+  SELECT 1
+  FROM accounts
+  WHERE accounts.owner = sqlc.arg(owner)::varchar
+  AND accounts.id IN (transfers.from_account_id, transfers.to_account_id)
+)
+AND (created_at, id) > (sqlc.arg(after_created_at)::timestamp, sqlc.arg(after_id)::bigint)
+ORDER BY created_at, id
+LIMIT sqlc.arg(limit_count);
+
 -- name: ListTransfer :many
 SELECT * 
 FROM transfers
 ORDER BY id
 LIMIT $1
 OFFSET $2;
-
--- name: UpdateTransfer :one
-UPDATE transfers 
-SET amount = $2
-WHERE id = $1
-RETURNING *;
-
--- name: DeleteTransfer :exec
-DELETE 
-FROM transfers
-WHERE id = $1;
-
@@ -0,0 +1,54 @@
+-- name: GetAccountTransferLimit :one
+SELECT * FROM transfer_limits
+WHERE account_id = $1 LIMIT 1;
+
+-- name: GetUserTransferLimit :one
+SELECT * FROM transfer_limits
+WHERE username = $1 AND currency = $2 LIMIT 1;
+
+-- name: GetUserTransferLimitForUpdate :one
+SELECT * FROM transfer_limits
+WHERE username = $1 AND currency = $2 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: UpsertAccountTransferLimit :one
+INSERT INTO transfer_limits (
+  account_id,
+  currency,
+  max_per_transfer,
+  max_daily_amount,
+  max_daily_count,
+  max_monthly_amount,
+  max_monthly_count
+) VALUES (
+  sqlc.arg(account_id)::bigint, $2, $3, $4, $5, $6, $7
+)
+ON CONFLICT ON CONSTRAINT account_limit_unique DO UPDATE
+SET max_per_transfer = EXCLUDED.max_per_transfer,
+  max_daily_amount = EXCLUDED.max_daily_amount,
+  max_daily_count = EXCLUDED.max_daily_count,
+  max_monthly_amount = EXCLUDED.max_monthly_amount,
+  max_monthly_count = EXCLUDED.max_monthly_count,
+  updated_at = now() AT TIME ZONE 'UTC' + INTERVAL '4 hours'
+RETURNING *;
+
+-- name: UpsertUserTransferLimit :one
+INSERT INTO transfer_limits (
+  username,
+  currency,
+  max_per_transfer,
+  max_daily_amount,
+  max_daily_count,
+  max_monthly_amount,
+  max_monthly_count
+) VALUES (
+  sqlc.arg(username)::varchar, $2, $3, $4, $5, $6, $7
+)
+ON CONFLICT ON CONSTRAINT user_limit_unique DO UPDATE
+SET max_per_transfer = EXCLUDED.max_per_transfer,
+  max_daily_amount = EXCLUDED.max_daily_amount,
+  max_daily_count = EXCLUDED.max_daily_count,
+  max_monthly_amount = EXCLUDED.max_monthly_amount,
+  max_monthly_count = EXCLUDED.max_monthly_count,
+  updated_at = now() AT TIME ZONE 'UTC' + INTERVAL '4 hours'
+RETURNING *;
@@ -7,18 +7,20 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
 const addAccountBalance = `-- name: AddAccountBalance :one
 UPDATE accounts 
 SET balance = balance + $1
 WHERE id = $2
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, held_amount, available_balance, status, status_reason, status_changed_at, type, interest_rate
 `
 
 type AddAccountBalanceParams struct {
-	Amount float64 `json:"amount"`
-	ID     int64   `json:"id"`
+	Amount int64 `json:"amount"`
+	ID     int64 `json:"id"`
 }
 
 func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error) {
@@ -30,29 +32,86 @@ func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalancePa
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.Type,
+		&i.InterestRate,
 	)
 	return i, err
 }
 
+const addAccountHeld = `-- name: AddAccountHeld :one
+UPDATE accounts
+SET held_amount = held_amount + $1
+WHERE id = $2
+RETURNING id, owner, balance, currency, created_at, held_amount, available_balance, status, status_reason, status_changed_at, type, interest_rate
+`
+
+type AddAccountHeldParams struct {
+	Amount int64 `json:"amount"`
+	ID     int64 `json:"id"`
+}
+
+func (q *Queries) AddAccountHeld(ctx context.Context, arg AddAccountHeldParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, addAccountHeld, arg.Amount, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.Type,
+		&i.InterestRate,
+	)
+	return i, err
+}
+
+const countAccounts = `-- name: CountAccounts :one
+SELECT COUNT(*) FROM accounts
+`
+
+func (q *Queries) CountAccounts(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countAccounts)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createAccount = `-- name: CreateAccount :one
 INSERT INTO accounts (
   owner,
   balance,
-  currency
+  currency,
+  type
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4
 )
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, held_amount, available_balance, status, status_reason, status_changed_at, type, interest_rate
 `
 
 type CreateAccountParams struct {
-	Owner    string  `json:"owner"`
-	Balance  float64 `json:"balance"`
-	Currency string  `json:"currency"`
+	Owner    string `json:"owner"`
+	Balance  int64  `json:"balance"`
+	Currency string `json:"currency"`
+	Type     string `json:"type"`
 }
 
 func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
-	row := q.db.QueryRowContext(ctx, createAccount, arg.Owner, arg.Balance, arg.Currency)
+	row := q.db.QueryRowContext(ctx, createAccount,
+		arg.Owner,
+		arg.Balance,
+		arg.Currency,
+		arg.Type,
+	)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -60,22 +119,19 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.Type,
+		&i.InterestRate,
 	)
 	return i, err
 }
 
-const deleteAccount = `-- name: DeleteAccount :exec
-DELETE FROM accounts
-WHERE id = $1
-`
-
-func (q *Queries) DeleteAccount(ctx context.Context, id int64) error {
-	_, err := q.db.ExecContext(ctx, deleteAccount, id)
-	return err
-}
-
 const getAccount = `-- name: GetAccount :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, held_amount, available_balance, status, status_reason, status_changed_at, type, interest_rate FROM accounts
 WHERE id = $1 LIMIT 1
 `
 
@@ -88,12 +144,50 @@ func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.Type,
+		&i.InterestRate,
+	)
+	return i, err
+}
+
+const getAccountByOwnerAndCurrency = `-- name: GetAccountByOwnerAndCurrency :one
+SELECT id, owner, balance, currency, created_at, held_amount, available_balance, status, status_reason, status_changed_at, type, interest_rate FROM accounts
+WHERE owner = $1 AND currency = $2
+LIMIT 1
+`
+
+type GetAccountByOwnerAndCurrencyParams struct {
+	Owner    string `json:"owner"`
+	Currency string `json:"currency"`
+}
+
+func (q *Queries) GetAccountByOwnerAndCurrency(ctx context.Context, arg GetAccountByOwnerAndCurrencyParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getAccountByOwnerAndCurrency, arg.Owner, arg.Currency)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.Type,
+		&i.InterestRate,
 	)
 	return i, err
 }
 
 const getAccountForUpdate = `-- name: GetAccountForUpdate :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, held_amount, available_balance, status, status_reason, status_changed_at, type, interest_rate FROM accounts
 WHERE id = $1 LIMIT 1
 FOR NO KEY UPDATE
 `
@@ -107,12 +201,39 @@ func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, e
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.Type,
+		&i.InterestRate,
 	)
 	return i, err
 }
 
+const getEndOfDayBalance = `-- name: GetEndOfDayBalance :one
+SELECT (a.balance - COALESCE(SUM(e.amount), 0))::bigint AS balance
+FROM accounts a
+LEFT JOIN entries e ON e.account_id = a.id AND e.created_at >= $1
+WHERE a.id = $2
+GROUP BY a.id, a.balance
+`
+
+type GetEndOfDayBalanceParams struct {
+	EndOfDay  time.Time `json:"end_of_day"`
+	AccountID int64     `json:"account_id"`
+}
+
+func (q *Queries) GetEndOfDayBalance(ctx context.Context, arg GetEndOfDayBalanceParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getEndOfDayBalance, arg.EndOfDay, arg.AccountID)
+	var balance int64
+	err := row.Scan(&balance)
+	return balance, err
+}
+
 const listAccount = `-- name: ListAccount :many
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, held_amount, available_balance, status, status_reason, status_changed_at, type, interest_rate FROM accounts
 ORDER BY id
 LIMIT $1
 OFFSET $2
@@ -138,6 +259,13 @@ func (q *Queries) ListAccount(ctx context.Context, arg ListAccountParams) ([]Acc
 			&i.Balance,
 			&i.Currency,
 			&i.CreatedAt,
+			&i.HeldAmount,
+			&i.AvailableBalance,
+			&i.Status,
+			&i.StatusReason,
+			&i.StatusChangedAt,
+			&i.Type,
+			&i.InterestRate,
 		); err != nil {
 			return nil, err
 		}
@@ -152,20 +280,213 @@ func (q *Queries) ListAccount(ctx context.Context, arg ListAccountParams) ([]Acc
 	return items, nil
 }
 
-const updateAccount = `-- name: UpdateAccount :one
-UPDATE accounts 
-SET balance = $2
+const listAccountByOwner = `-- name: ListAccountByOwner :many
+SELECT id, owner, balance, currency, created_at, held_amount, available_balance, status, status_reason, status_changed_at, type, interest_rate FROM accounts
+WHERE owner = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3
+`
+
+type ListAccountByOwnerParams struct {
+	Owner  string `json:"owner"`
+	Limit  int32  `json:"limit"`
+	Offset int32  `json:"offset"`
+}
+
+func (q *Queries) ListAccountByOwner(ctx context.Context, arg ListAccountByOwnerParams) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountByOwner, arg.Owner, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.HeldAmount,
+			&i.AvailableBalance,
+			&i.Status,
+			&i.StatusReason,
+			&i.StatusChangedAt,
+			&i.Type,
+			&i.InterestRate,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountByOwnerAfter = `-- name: ListAccountByOwnerAfter :many
+SELECT id, owner, balance, currency, created_at, held_amount, available_balance, status, status_reason, status_changed_at, type, interest_rate FROM accounts
+WHERE owner = $1
+AND (created_at, id) > ($2::timestamp, $3::bigint)
+ORDER BY created_at, id
+LIMIT $4
+`
+
+type ListAccountByOwnerAfterParams struct {
+	Owner          string    `json:"owner"`
+	AfterCreatedAt time.Time `json:"after_created_at"`
+	AfterID        int64     `json:"after_id"`
+	LimitCount     int32     `json:"limit_count"`
+}
+
+// keyset page of the accounts of owner that come after the cursor in (created_at, id) order
+func (q *Queries) ListAccountByOwnerAfter(ctx context.Context, arg ListAccountByOwnerAfterParams) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountByOwnerAfter,
+		arg.Owner,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.HeldAmount,
+			&i.AvailableBalance,
+			&i.Status,
+			&i.StatusReason,
+			&i.StatusChangedAt,
+			&i.Type,
+			&i.InterestRate,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listInterestBearingAccounts = `-- name: ListInterestBearingAccounts :many
+SELECT id, owner, balance, currency, created_at, held_amount, available_balance, status, status_reason, status_changed_at, type, interest_rate FROM accounts
+WHERE interest_rate > 0
+  AND status <> 'closed'
+  AND owner NOT LIKE 'bank\_%'
+  AND id > $1
+ORDER BY id
+LIMIT $2
+`
+
+type ListInterestBearingAccountsParams struct {
+	ID    int64 `json:"id"`
+	Limit int32 `json:"limit"`
+}
+
+func (q *Queries) ListInterestBearingAccounts(ctx context.Context, arg ListInterestBearingAccountsParams) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, listInterestBearingAccounts, arg.ID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.HeldAmount,
+			&i.AvailableBalance,
+			&i.Status,
+			&i.StatusReason,
+			&i.StatusChangedAt,
+			&i.Type,
+			&i.InterestRate,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateAccountInterestRate = `-- name: UpdateAccountInterestRate :one
+UPDATE accounts
+SET interest_rate = $2
+WHERE id = $1
+RETURNING id, owner, balance, currency, created_at, held_amount, available_balance, status, status_reason, status_changed_at, type, interest_rate
+`
+
+type UpdateAccountInterestRateParams struct {
+	ID           int64  `json:"id"`
+	InterestRate string `json:"interest_rate"`
+}
+
+func (q *Queries) UpdateAccountInterestRate(ctx context.Context, arg UpdateAccountInterestRateParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, updateAccountInterestRate, arg.ID, arg.InterestRate)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.Type,
+		&i.InterestRate,
+	)
+	return i, err
+}
+
+const updateAccountStatus = `-- name: UpdateAccountStatus :one
+UPDATE accounts
+SET status = $2,
+  status_reason = $3,
+  status_changed_at = now()
 WHERE id = $1
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, held_amount, available_balance, status, status_reason, status_changed_at, type, interest_rate
 `
 
-type UpdateAccountParams struct {
-	ID      int64   `json:"id"`
-	Balance float64 `json:"balance"`
+type UpdateAccountStatusParams struct {
+	ID           int64          `json:"id"`
+	Status       string         `json:"status"`
+	StatusReason sql.NullString `json:"status_reason"`
 }
 
-func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error) {
-	row := q.db.QueryRowContext(ctx, updateAccount, arg.ID, arg.Balance)
+func (q *Queries) UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, updateAccountStatus, arg.ID, arg.Status, arg.StatusReason)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -173,6 +494,13 @@ func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.Type,
+		&i.InterestRate,
 	)
 	return i, err
 }
@@ -0,0 +1,77 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Statuses of an account, money only moves between active accounts
+const (
+	AccountActive = "active"
+	AccountFrozen = "frozen"
+	AccountClosed = "closed"
+)
+
+// accountStatusTransitions lists the statuses an account can move to from each status, closed is final
+var accountStatusTransitions = map[string][]string{
+	AccountActive: {AccountFrozen, AccountClosed},
+	AccountFrozen: {AccountActive},
+}
+
+// UpdateAccountStatusTxParams contains the inputs of an account status change, Reason is kept on the account
+type UpdateAccountStatusTxParams struct {
+	AccountID int64  `json:"accountId"`
+	Status    string `json:"status"`
+	Reason    string `json:"reason"`
+}
+
+// ANCHOR - UpdateAccountStatusTx moves the account to arg.Status if its current status allows it
+// An account is only closed once its balance is zero and no hold reserves money on it
+// It fails with ErrAccountNotFound, ErrInvalidStatusTransition or ErrAccountNotEmpty
+func (store *SQLStore) UpdateAccountStatusTx(ctx context.Context, arg UpdateAccountStatusTxParams) (Account, error) {
+	var account Account
+
+	_, err := store.execTx(ctx, nil, func(q *Queries) error {
+		current, err := lockAccount(ctx, q, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		if !canChangeAccountStatus(current.Status, arg.Status) {
+			return fmt.Errorf("%w: %s to %s", ErrInvalidStatusTransition, current.Status, arg.Status)
+		}
+
+		if arg.Status == AccountClosed && (current.Balance != 0 || current.HeldAmount != 0) {
+			return fmt.Errorf("%w: %d", ErrAccountNotEmpty, current.ID)
+		}
+
+		account, err = q.UpdateAccountStatus(ctx, UpdateAccountStatusParams{
+			ID:           arg.AccountID,
+			Status:       arg.Status,
+			StatusReason: sql.NullString{String: arg.Reason, Valid: true},
+		})
+		return err
+	})
+	return account, err
+}
+
+// canChangeAccountStatus reports whether an account in status from can move to status to
+func canChangeAccountStatus(from string, to string) bool {
+	for _, status := range accountStatusTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// checkAccountsActive fails with ErrAccountNotActive unless every account is active
+func checkAccountsActive(accounts ...Account) error {
+	for _, account := range accounts {
+		if account.Status != AccountActive {
+			return fmt.Errorf("%w: %d is %s", ErrAccountNotActive, account.ID, account.Status)
+		}
+	}
+	return nil
+}
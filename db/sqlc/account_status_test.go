@@ -0,0 +1,96 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateAccountStatusTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithCurrency(t, "USD")
+	account2 := createRandomAccountWithCurrency(t, "USD")
+	require.Equal(t, AccountActive, account1.Status)
+
+	frozen, err := store.UpdateAccountStatusTx(context.Background(), UpdateAccountStatusTxParams{
+		AccountID: account1.ID,
+		Status:    AccountFrozen,
+		Reason:    "suspected fraud",
+	})
+	require.NoError(t, err)
+	require.Equal(t, AccountFrozen, frozen.Status)
+	require.Equal(t, "suspected fraud", frozen.StatusReason.String)
+	require.True(t, frozen.StatusChangedAt.Valid)
+
+	// a frozen account neither sends nor receives
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+		Currency:      "USD",
+	})
+	require.ErrorIs(t, err, ErrAccountNotActive)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account2.ID,
+		ToAccountID:   account1.ID,
+		Amount:        1,
+		Currency:      "USD",
+	})
+	require.ErrorIs(t, err, ErrAccountNotActive)
+
+	// frozen accounts can't be closed without being unfrozen first
+	_, err = store.UpdateAccountStatusTx(context.Background(), UpdateAccountStatusTxParams{
+		AccountID: account1.ID,
+		Status:    AccountClosed,
+		Reason:    "customer request",
+	})
+	require.ErrorIs(t, err, ErrInvalidStatusTransition)
+
+	active, err := store.UpdateAccountStatusTx(context.Background(), UpdateAccountStatusTxParams{
+		AccountID: account1.ID,
+		Status:    AccountActive,
+		Reason:    "cleared",
+	})
+	require.NoError(t, err)
+	require.Equal(t, AccountActive, active.Status)
+}
+
+func TestCloseAccountTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccountWithCurrency(t, "USD")
+	require.NotZero(t, account.Balance)
+
+	_, err := store.UpdateAccountStatusTx(context.Background(), UpdateAccountStatusTxParams{
+		AccountID: account.ID,
+		Status:    AccountClosed,
+		Reason:    "customer request",
+	})
+	require.ErrorIs(t, err, ErrAccountNotEmpty)
+
+	_, err = store.WithdrawTx(context.Background(), CashTxParams{
+		AccountID: account.ID,
+		Amount:    account.Balance,
+		Currency:  "USD",
+	})
+	require.NoError(t, err)
+
+	closed, err := store.UpdateAccountStatusTx(context.Background(), UpdateAccountStatusTxParams{
+		AccountID: account.ID,
+		Status:    AccountClosed,
+		Reason:    "customer request",
+	})
+	require.NoError(t, err)
+	require.Equal(t, AccountClosed, closed.Status)
+
+	// closed is final
+	_, err = store.UpdateAccountStatusTx(context.Background(), UpdateAccountStatusTxParams{
+		AccountID: account.ID,
+		Status:    AccountActive,
+		Reason:    "reopen",
+	})
+	require.ErrorIs(t, err, ErrInvalidStatusTransition)
+}
@@ -2,7 +2,6 @@ package db
 
 import (
 	"context"
-	"database/sql"
 	"testing"
 	"time"
 
@@ -11,12 +10,17 @@ import (
 )
 
 func createRandomAccount(t *testing.T) Account {
+	return createRandomAccountWithCurrency(t, util.RandomCurrency())
+}
+
+func createRandomAccountWithCurrency(t *testing.T, currency string) Account {
 	user := createRandomUser(t)
 
 	arg := CreateAccountParams{
 		Owner:    user.Username,
 		Balance:  util.RandomMoney(),
-		Currency: util.RandomCurrency(),
+		Currency: currency,
+		Type:     AccountChecking,
 	}
 
 	account, err := testQueries.CreateAccount(context.Background(), arg)
@@ -27,6 +31,7 @@ func createRandomAccount(t *testing.T) Account {
 	require.Equal(t, arg.Balance, account.Balance)
 	require.Equal(t, arg.Owner, account.Owner)
 	require.Equal(t, arg.Currency, account.Currency)
+	require.Equal(t, arg.Type, account.Type)
 
 	require.NotZero(t, account.ID)
 	require.NotZero(t, account.CreatedAt)
@@ -53,50 +58,109 @@ func TestGetAccount(t *testing.T) {
 	require.WithinDuration(t, account1.CreatedAt, account2.CreatedAt, time.Second)
 }
 
-func TestUpdateAccount(t *testing.T) {
-	account1 := createRandomAccount(t)
+func TestUpdateAccountBalance(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccountWithCurrency(t, "USD")
+	amount := util.RandomMoney()
+
+	// a balance only changes through a transaction that books it in the ledger
+	result, err := store.DepositTx(context.Background(), CashTxParams{
+		AccountID: account1.ID,
+		Amount:    amount,
+		Currency:  "USD",
+	})
+	require.NoError(t, err)
+
+	account2, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance+amount, account2.Balance)
+	require.Equal(t, result.Account.Balance, account2.Balance)
+	require.Equal(t, account2.ID, result.Entry.AccountID)
+	require.Equal(t, amount, result.Entry.Amount)
+
+	verification, err := store.VerifyLedger(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.True(t, verification.Valid)
+}
 
-	args := UpdateAccountParams{
-		ID:      account1.ID,
-		Balance: util.RandomMoney(),
+func TestListAccount(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		createRandomAccount(t)
 	}
 
-	account2, err := testQueries.UpdateAccount(context.Background(), args)
+	args := ListAccountParams{Limit: 5, Offset: 5}
 
+	accounts, err := testQueries.ListAccount(context.Background(), args)
 	require.NoError(t, err)
-	require.NotEmpty(t, account2)
+	require.Len(t, accounts, 5)
 
-	require.Equal(t, account1.ID, account2.ID)
-	require.Equal(t, account1.Owner, account2.Owner)
-	require.Equal(t, args.Balance, account2.Balance)
-	require.Equal(t, account1.Currency, account2.Currency)
-	require.WithinDuration(t, account1.CreatedAt, account2.CreatedAt, time.Second)
+	for _, account := range accounts {
+		require.NotEmpty(t, account)
+	}
 }
 
-func TestDeleteAccount(t *testing.T) {
-	account1 := createRandomAccount(t)
+func TestListAccountByOwnerAfter(t *testing.T) {
+	user := createRandomUser(t)
+	var created []Account
+	for _, currency := range []string{"USD", "EUR", "GEL"} {
+		account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+			Owner:    user.Username,
+			Balance:  util.RandomMoney(),
+			Currency: currency,
+			Type:     AccountChecking,
+		})
+		require.NoError(t, err)
+		created = append(created, account)
+	}
 
-	err := testQueries.DeleteAccount(context.Background(), account1.ID)
+	first, err := testQueries.ListAccountByOwnerAfter(context.Background(), ListAccountByOwnerAfterParams{
+		Owner:      user.Username,
+		LimitCount: 2,
+	})
 	require.NoError(t, err)
+	require.Len(t, first, 2)
+	require.Equal(t, created[0].ID, first[0].ID)
+	require.Equal(t, created[1].ID, first[1].ID)
 
-	account2, err := testQueries.GetAccount(context.Background(), account1.ID)
-	require.Error(t, err)
-	require.EqualError(t, err, sql.ErrNoRows.Error())
-	require.Empty(t, account2)
+	// an account opened between the pages doesn't shift the next page
+	_, err = testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Balance:  util.RandomMoney(),
+		Currency: "USD",
+		Type:     AccountSavings,
+	})
+	require.NoError(t, err)
+
+	last := first[len(first)-1]
+	second, err := testQueries.ListAccountByOwnerAfter(context.Background(), ListAccountByOwnerAfterParams{
+		Owner:          user.Username,
+		AfterCreatedAt: last.CreatedAt,
+		AfterID:        last.ID,
+		LimitCount:     2,
+	})
+	require.NoError(t, err)
+	require.Len(t, second, 2)
+	require.Equal(t, created[2].ID, second[0].ID)
 }
 
-func TestListAccount(t *testing.T) {
+func TestListAccountByOwner(t *testing.T) {
+	var lastAccount Account
 	for i := 0; i < 10; i++ {
-		createRandomAccount(t)
+		lastAccount = createRandomAccount(t)
 	}
 
-	args := ListAccountParams{Limit: 5, Offset: 5}
+	args := ListAccountByOwnerParams{
+		Owner:  lastAccount.Owner,
+		Limit:  5,
+		Offset: 0,
+	}
 
-	accounts, err := testQueries.ListAccount(context.Background(), args)
+	accounts, err := testQueries.ListAccountByOwner(context.Background(), args)
 	require.NoError(t, err)
-	require.Len(t, accounts, 5)
+	require.NotEmpty(t, accounts)
 
 	for _, account := range accounts {
 		require.NotEmpty(t, account)
+		require.Equal(t, lastAccount.Owner, account.Owner)
 	}
 }
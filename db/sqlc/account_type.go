@@ -0,0 +1,92 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/T-BO0/bank/util/money"
+)
+
+// Types of an account, each with the rules in accountTypes
+const (
+	AccountChecking  = "checking"
+	AccountSavings   = "savings"
+	AccountOverdraft = "overdraft"
+)
+
+// AccountType holds the rules a debit from an account of the type has to follow
+// Amounts are in major units of the account currency, a zero value means no such rule
+type AccountType struct {
+	Name string `json:"name"`
+	// MinBalance is the lowest available balance a debit can leave
+	MinBalance int64 `json:"minBalance"`
+	// OverdraftLimit is how far below MinBalance the available balance may go
+	OverdraftLimit int64 `json:"overdraftLimit"`
+	// MaxWithdrawalsPerMonth bounds the outgoing transfers per calendar month, reversals don't count
+	MaxWithdrawalsPerMonth int64 `json:"maxWithdrawalsPerMonth"`
+}
+
+// accountTypes is the registry of account types by name
+var accountTypes = map[string]AccountType{
+	AccountChecking: {
+		Name: AccountChecking,
+	},
+	AccountSavings: {
+		Name:                   AccountSavings,
+		MinBalance:             10,
+		MaxWithdrawalsPerMonth: 6,
+	},
+	AccountOverdraft: {
+		Name:           AccountOverdraft,
+		OverdraftLimit: 500,
+	},
+}
+
+// GetAccountType returns the rules of the named account type and false if there is no such type
+func GetAccountType(name string) (AccountType, bool) {
+	accountType, ok := accountTypes[name]
+	return accountType, ok
+}
+
+// floor returns the lowest available balance a debit can leave, in minor units of currency
+func (t AccountType) floor(currency string) (int64, error) {
+	exp, err := money.Exponent(currency)
+	if err != nil {
+		return 0, err
+	}
+	return (t.MinBalance - t.OverdraftLimit) * int64(math.Pow10(exp)), nil
+}
+
+// checkDebit checks that the rules of the account type allow debiting amount from the locked account
+// withdrawal counts the debit against the monthly withdrawals, reversals pass false
+// Internal bank accounts follow no rules and may go below zero
+func checkDebit(ctx context.Context, q *Queries, account Account, amount int64, withdrawal bool) error {
+	if IsInternalAccount(account) {
+		return nil
+	}
+
+	accountType, ok := GetAccountType(account.Type)
+	if !ok {
+		return fmt.Errorf("account %d has unknown type %s", account.ID, account.Type)
+	}
+
+	floor, err := accountType.floor(account.Currency)
+	if err != nil {
+		return err
+	}
+	if account.AvailableBalance-amount < floor {
+		return ErrInsufficientFunds
+	}
+
+	if withdrawal && accountType.MaxWithdrawalsPerMonth > 0 {
+		count, err := q.CountMonthlyWithdrawals(ctx, account.ID)
+		if err != nil {
+			return err
+		}
+		if count >= accountType.MaxWithdrawalsPerMonth {
+			return fmt.Errorf("%w: %d of %d this month", ErrWithdrawalLimitReached, count, accountType.MaxWithdrawalsPerMonth)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createAccountOfType(t *testing.T, accountType string, balance int64) Account {
+	user := createRandomUser(t)
+
+	account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Balance:  balance,
+		Currency: "USD",
+		Type:     accountType,
+	})
+	require.NoError(t, err)
+	require.Equal(t, accountType, account.Type)
+	return account
+}
+
+func TestAccountTypeFloor(t *testing.T) {
+	testCases := []struct {
+		name      string
+		typeName  string
+		currency  string
+		wantFloor int64
+	}{
+		{name: "Checking", typeName: AccountChecking, currency: "USD", wantFloor: 0},
+		{name: "Savings", typeName: AccountSavings, currency: "EUR", wantFloor: 1000},
+		{name: "Overdraft", typeName: AccountOverdraft, currency: "GEL", wantFloor: -50000},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			accountType, ok := GetAccountType(tc.typeName)
+			require.True(t, ok)
+
+			floor, err := accountType.floor(tc.currency)
+			require.NoError(t, err)
+			require.Equal(t, tc.wantFloor, floor)
+		})
+	}
+
+	_, ok := GetAccountType("brokerage")
+	require.False(t, ok)
+}
+
+func TestTransferTxOverdraft(t *testing.T) {
+	store := NewStore(testDB)
+
+	overdraft := createAccountOfType(t, AccountOverdraft, 0)
+	other := createRandomAccountWithCurrency(t, "USD")
+
+	// the fee counts against the overdraft limit too
+	fee := QuoteFee(overdraft, 40000)
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: overdraft.ID,
+		ToAccountID:   other.ID,
+		Amount:        40000,
+		Currency:      "USD",
+	})
+	require.NoError(t, err)
+	require.Equal(t, -(40000 + fee), result.FromAccount.Balance)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: overdraft.ID,
+		ToAccountID:   other.ID,
+		Amount:        50000 - 40000 - fee,
+		Currency:      "USD",
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func TestTransferTxSavingsRules(t *testing.T) {
+	store := NewStore(testDB)
+
+	savings := createAccountOfType(t, AccountSavings, 10000)
+	other := createRandomAccountWithCurrency(t, "USD")
+
+	// the minimum balance of 10.00 can't be spent
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: savings.ID,
+		ToAccountID:   other.ID,
+		Amount:        9001,
+		Currency:      "USD",
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	savingsType, _ := GetAccountType(AccountSavings)
+	for i := int64(0); i < savingsType.MaxWithdrawalsPerMonth; i++ {
+		_, err = store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: savings.ID,
+			ToAccountID:   other.ID,
+			Amount:        1,
+			Currency:      "USD",
+		})
+		require.NoError(t, err)
+	}
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: savings.ID,
+		ToAccountID:   other.ID,
+		Amount:        1,
+		Currency:      "USD",
+	})
+	require.ErrorIs(t, err, ErrWithdrawalLimitReached)
+}
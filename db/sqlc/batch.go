@@ -0,0 +1,150 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+)
+
+// Statuses of a leg of a batch transfer
+const (
+	BatchLegSucceeded = "succeeded"
+	BatchLegFailed    = "failed"
+	// BatchLegRolledBack is a leg that went through but was undone with the rest of a failed all or nothing batch
+	BatchLegRolledBack = "rolled_back"
+)
+
+// BatchTransferLeg is one transfer of a batch, Amount is in minor units of Currency,
+// which has to be the currency of both accounts
+type BatchTransferLeg struct {
+	FromAccountID int64  `json:"fromAccountId"`
+	ToAccountID   int64  `json:"toAccountId"`
+	Amount        int64  `json:"amount"`
+	Currency      string `json:"currency"`
+}
+
+// BatchTransferTxParams contains the legs of a batch transfer
+// Without BestEffort the batch is all or nothing, with it the legs that can go through are kept
+type BatchTransferTxParams struct {
+	Legs       []BatchTransferLeg `json:"legs"`
+	BestEffort bool               `json:"bestEffort"`
+}
+
+// BatchLegResult is the outcome of the leg at Index of the batch
+// Transfer is only set on a succeeded leg and Err only on a failed one
+type BatchLegResult struct {
+	Index    int               `json:"index"`
+	Status   string            `json:"status"`
+	Transfer *TransferTxResult `json:"transfer,omitempty"`
+	Err      error             `json:"-"`
+}
+
+// BatchTransferTxResult is the result of a batch transfer with one result per leg in the order of the legs
+type BatchTransferTxResult struct {
+	Legs      []BatchLegResult `json:"legs"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	// Retries is the number of times the transaction was run again after a serialization failure or deadlock
+	Retries int `json:"-"`
+}
+
+// ANCHOR - BatchTransferTx runs all legs of the batch in a single database transaction
+// Every account of the batch, the revenue accounts the fees of the legs go to included, is locked up front
+// in ascending ID order, the order lockAccounts uses, so batches and single transfers never wait on each other
+// in a cycle. Each leg is charged and limited like TransferTx.
+// A leg the transfer path rejects is reported in its BatchLegResult and the next leg runs anyway, so every
+// failing leg is reported. Without BestEffort one failed leg rolls the whole batch back and BatchTransferTx fails
+// with ErrBatchFailed next to the per leg results. Any other error fails the whole batch in both modes
+func (store *SQLStore) BatchTransferTx(ctx context.Context, arg BatchTransferTxParams) (BatchTransferTxResult, error) {
+	var result BatchTransferTxResult
+
+	retries, err := store.execTx(ctx, nil, func(q *Queries) error {
+		result = BatchTransferTxResult{Legs: make([]BatchLegResult, len(arg.Legs))}
+
+		// the fees are known before any leg runs, so their accounts are locked with the others
+		transfers := make([]CreateTransferParams, len(arg.Legs))
+		feeErrs := make([]error, len(arg.Legs))
+		for i, leg := range arg.Legs {
+			transfers[i], feeErrs[i] = withFee(ctx, q, CreateTransferParams{
+				FromAccountID: leg.FromAccountID,
+				ToAccountID:   leg.ToAccountID,
+				Amount:        leg.Amount,
+				Currency:      leg.Currency,
+				ToAmount:      leg.Amount,
+				ToCurrency:    leg.Currency,
+				FxRate:        "1",
+			})
+			if feeErrs[i] != nil && !isTransferRejection(feeErrs[i]) {
+				return feeErrs[i]
+			}
+		}
+
+		if err := lockBatchAccounts(ctx, q, transfers); err != nil {
+			return err
+		}
+
+		for i := range transfers {
+			err := feeErrs[i]
+			var transferResult TransferTxResult
+			if err == nil {
+				transferResult, err = transfer(ctx, q, transfers[i])
+			}
+			switch {
+			case err == nil:
+				result.Legs[i] = BatchLegResult{Index: i, Status: BatchLegSucceeded, Transfer: &transferResult}
+				result.Succeeded++
+			case isTransferRejection(err):
+				// rejections happen before anything of the leg is written, so the transaction can go on
+				result.Legs[i] = BatchLegResult{Index: i, Status: BatchLegFailed, Err: err}
+				result.Failed++
+			default:
+				return err
+			}
+		}
+
+		if result.Failed > 0 && !arg.BestEffort {
+			return ErrBatchFailed
+		}
+		return nil
+	})
+	result.Retries = retries
+
+	if errors.Is(err, ErrBatchFailed) {
+		for i := range result.Legs {
+			if result.Legs[i].Status == BatchLegSucceeded {
+				result.Legs[i].Status = BatchLegRolledBack
+				result.Legs[i].Transfer = nil
+			}
+		}
+		result.Succeeded = 0
+	}
+	return result, err
+}
+
+// lockBatchAccounts locks every account of the transfers once in ascending ID order, fee accounts included
+// A missing account is left to the leg using it, which reports ErrAccountNotFound
+func lockBatchAccounts(ctx context.Context, q *Queries, transfers []CreateTransferParams) error {
+	seen := make(map[int64]bool, 2*len(transfers)+1)
+	ids := make([]int64, 0, 2*len(transfers)+1)
+	for _, transfer := range transfers {
+		accountIDs := []int64{transfer.FromAccountID, transfer.ToAccountID}
+		if transfer.FeeAccountID.Valid {
+			accountIDs = append(accountIDs, transfer.FeeAccountID.Int64)
+		}
+		for _, id := range accountIDs {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	for _, id := range ids {
+		if _, err := q.GetAccountForUpdate(ctx, id); err != nil && err != sql.ErrNoRows {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	source := createRandomAccountWithCurrency(t, "USD")
+	var legs []BatchTransferLeg
+	for i := 0; i < 5; i++ {
+		account := createRandomAccountWithCurrency(t, "USD")
+		legs = append(legs, BatchTransferLeg{
+			FromAccountID: source.ID,
+			ToAccountID:   account.ID,
+			Amount:        1,
+			Currency:      "USD",
+		})
+	}
+
+	result, err := store.BatchTransferTx(context.Background(), BatchTransferTxParams{Legs: legs})
+	require.NoError(t, err)
+	require.Equal(t, len(legs), result.Succeeded)
+	require.Zero(t, result.Failed)
+
+	for i, leg := range result.Legs {
+		require.Equal(t, i, leg.Index)
+		require.Equal(t, BatchLegSucceeded, leg.Status)
+		require.NotNil(t, leg.Transfer)
+		require.Equal(t, legs[i].ToAccountID, leg.Transfer.Transfer.ToAccountID)
+	}
+
+	updated, err := testQueries.GetAccount(context.Background(), source.ID)
+	require.NoError(t, err)
+	require.Equal(t, source.Balance-int64(len(legs)), updated.Balance)
+}
+
+func TestBatchTransferTxAllOrNothing(t *testing.T) {
+	store := NewStore(testDB)
+
+	source := createRandomAccountWithCurrency(t, "USD")
+	account1 := createRandomAccountWithCurrency(t, "USD")
+	account2 := createRandomAccountWithCurrency(t, "EUR")
+
+	legs := []BatchTransferLeg{
+		{FromAccountID: source.ID, ToAccountID: account1.ID, Amount: 1, Currency: "USD"},
+		{FromAccountID: source.ID, ToAccountID: account2.ID, Amount: 1, Currency: "USD"},
+		{FromAccountID: source.ID, ToAccountID: account1.ID, Amount: source.Balance + 1, Currency: "USD"},
+	}
+
+	result, err := store.BatchTransferTx(context.Background(), BatchTransferTxParams{Legs: legs})
+	require.ErrorIs(t, err, ErrBatchFailed)
+	require.Zero(t, result.Succeeded)
+	require.Equal(t, 2, result.Failed)
+
+	// every failing leg is reported, the leg that went through is undone
+	require.Equal(t, BatchLegRolledBack, result.Legs[0].Status)
+	require.Nil(t, result.Legs[0].Transfer)
+	require.Equal(t, BatchLegFailed, result.Legs[1].Status)
+	require.ErrorIs(t, result.Legs[1].Err, ErrCurrencyMismatch)
+	require.Equal(t, BatchLegFailed, result.Legs[2].Status)
+	require.ErrorIs(t, result.Legs[2].Err, ErrInsufficientFunds)
+
+	updated, err := testQueries.GetAccount(context.Background(), source.ID)
+	require.NoError(t, err)
+	require.Equal(t, source.Balance, updated.Balance)
+
+	// the same batch in best effort mode keeps the leg that can go through
+	result, err = store.BatchTransferTx(context.Background(), BatchTransferTxParams{Legs: legs, BestEffort: true})
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Succeeded)
+	require.Equal(t, 2, result.Failed)
+	require.Equal(t, BatchLegSucceeded, result.Legs[0].Status)
+
+	updated, err = testQueries.GetAccount(context.Background(), source.ID)
+	require.NoError(t, err)
+	require.Equal(t, source.Balance-1, updated.Balance)
+}
+
+func TestBatchTransferTxFeeDeadlock(t *testing.T) {
+	store := NewStore(testDB)
+
+	overdraft := createAccountOfType(t, AccountOverdraft, 100000)
+	account1 := createRandomAccountWithCurrency(t, "USD")
+	account2 := createRandomAccountWithCurrency(t, "USD")
+
+	revenue, err := testQueries.GetAccountByOwnerAndCurrency(context.Background(), GetAccountByOwnerAndCurrencyParams{
+		Owner:    RevenueAccountOwner,
+		Currency: "USD",
+	})
+	require.NoError(t, err)
+
+	n := 10
+	errs := make(chan error)
+
+	// charged batches out of the overdraft account need the revenue account too,
+	// while free transfers out of the revenue account lock it and the overdraft account
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			if i%2 == 1 {
+				_, err := store.TransferTx(context.Background(), TransferTxParams{
+					FromAccountID: revenue.ID,
+					ToAccountID:   overdraft.ID,
+					Amount:        100,
+					Currency:      "USD",
+				})
+				errs <- err
+				return
+			}
+
+			_, err := store.BatchTransferTx(context.Background(), BatchTransferTxParams{Legs: []BatchTransferLeg{
+				{FromAccountID: overdraft.ID, ToAccountID: account1.ID, Amount: 100, Currency: "USD"},
+				{FromAccountID: overdraft.ID, ToAccountID: account2.ID, Amount: 100, Currency: "USD"},
+			}})
+			errs <- err
+		}(i)
+	}
+
+	for i := 0; i < n; i++ {
+		require.NoError(t, <-errs)
+	}
+
+	// the revenue account was locked up front in ID order with the rest, so nothing deadlocked
+	stats := store.TxStats()
+	require.Equal(t, int64(n), stats.Transactions)
+	require.Zero(t, stats.Retries)
+}
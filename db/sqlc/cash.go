@@ -0,0 +1,96 @@
+package db
+
+import "context"
+
+// CashTxParams contains the inputs of a deposit or withdrawal
+// Amount is in minor units of Currency, which has to be the currency of the account
+type CashTxParams struct {
+	AccountID int64  `json:"accountId"`
+	Amount    int64  `json:"amount"`
+	Currency  string `json:"currency"`
+}
+
+// CashTxResult is the result of a deposit or withdrawal
+// The money is booked as a transfer against the bank's cash account of the same currency
+type CashTxResult struct {
+	Transfer    Transfer `json:"transfer"`
+	Account     Account  `json:"account"`
+	Entry       Entry    `json:"entry"`
+	CashAccount Account  `json:"cashAccount"`
+	CashEntry   Entry    `json:"cashEntry"`
+	// Retries is the number of times the transaction was run again after a serialization failure or deadlock
+	Retries int `json:"-"`
+}
+
+// ANCHOR - DepositTx credits the account and debits the bank's cash account within a single database transaction
+func (store *SQLStore) DepositTx(ctx context.Context, arg CashTxParams) (CashTxResult, error) {
+	var result CashTxResult
+
+	retries, err := store.execTx(ctx, nil, func(q *Queries) error {
+		cashAccount, err := getInternalAccount(ctx, q, CashAccountOwner, arg.Currency)
+		if err != nil {
+			return err
+		}
+
+		transferResult, err := transfer(ctx, q, CreateTransferParams{
+			FromAccountID: cashAccount.ID,
+			ToAccountID:   arg.AccountID,
+			Amount:        arg.Amount,
+			Currency:      arg.Currency,
+			ToAmount:      arg.Amount,
+			ToCurrency:    arg.Currency,
+			FxRate:        "1",
+		})
+		if err != nil {
+			return err
+		}
+
+		result = CashTxResult{
+			Transfer:    transferResult.Transfer,
+			Account:     transferResult.ToAccount,
+			Entry:       transferResult.ToEntry,
+			CashAccount: transferResult.FromAccount,
+			CashEntry:   transferResult.FromEntry,
+		}
+		return nil
+	})
+	result.Retries = retries
+	return result, err
+}
+
+// ANCHOR - WithdrawTx debits the account and credits the bank's cash account within a single database transaction
+// It fails with ErrInsufficientFunds if the account balance does not cover the amount
+func (store *SQLStore) WithdrawTx(ctx context.Context, arg CashTxParams) (CashTxResult, error) {
+	var result CashTxResult
+
+	retries, err := store.execTx(ctx, nil, func(q *Queries) error {
+		cashAccount, err := getInternalAccount(ctx, q, CashAccountOwner, arg.Currency)
+		if err != nil {
+			return err
+		}
+
+		transferResult, err := transfer(ctx, q, CreateTransferParams{
+			FromAccountID: arg.AccountID,
+			ToAccountID:   cashAccount.ID,
+			Amount:        arg.Amount,
+			Currency:      arg.Currency,
+			ToAmount:      arg.Amount,
+			ToCurrency:    arg.Currency,
+			FxRate:        "1",
+		})
+		if err != nil {
+			return err
+		}
+
+		result = CashTxResult{
+			Transfer:    transferResult.Transfer,
+			Account:     transferResult.FromAccount,
+			Entry:       transferResult.FromEntry,
+			CashAccount: transferResult.ToAccount,
+			CashEntry:   transferResult.ToEntry,
+		}
+		return nil
+	})
+	result.Retries = retries
+	return result, err
+}
@@ -0,0 +1,58 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDepositTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccountWithCurrency(t, "USD")
+
+	result, err := store.DepositTx(context.Background(), CashTxParams{
+		AccountID: account.ID,
+		Amount:    500,
+		Currency:  "USD",
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, account.Balance+500, result.Account.Balance)
+	require.Equal(t, int64(500), result.Entry.Amount)
+	require.Equal(t, int64(-500), result.CashEntry.Amount)
+	require.Equal(t, CashAccountOwner, result.CashAccount.Owner)
+	require.True(t, IsInternalAccount(result.CashAccount))
+	require.Equal(t, result.CashAccount.ID, result.Transfer.FromAccountID)
+	require.Equal(t, account.ID, result.Transfer.ToAccountID)
+}
+
+func TestWithdrawTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccountWithCurrency(t, "EUR")
+
+	result, err := store.WithdrawTx(context.Background(), CashTxParams{
+		AccountID: account.ID,
+		Amount:    account.Balance,
+		Currency:  "EUR",
+	})
+	require.NoError(t, err)
+
+	require.Zero(t, result.Account.Balance)
+	require.Equal(t, -account.Balance, result.Entry.Amount)
+	require.Equal(t, account.Balance, result.CashEntry.Amount)
+
+	_, err = store.WithdrawTx(context.Background(), CashTxParams{
+		AccountID: account.ID,
+		Amount:    1,
+		Currency:  "EUR",
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = store.WithdrawTx(context.Background(), CashTxParams{
+		AccountID: account.ID,
+		Amount:    1,
+		Currency:  "USD",
+	})
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+}
@@ -13,28 +13,22 @@ import (
 func createRandomEntry(t *testing.T) Entry {
 	account := createRandomAccount(t)
 
-	args := CreateEntryParams{AccountID: account.ID, Amount: util.RandomMoney()}
-
-	entry, err := testQueries.CreateEntry(context.Background(), args)
-	require.NoError(t, err)
-	require.NotEmpty(t, entry)
-
-	require.Equal(t, entry.AccountID, account.ID)
-	require.Equal(t, entry.Amount, args.Amount)
-
-	return entry
+	return createRandomEntryForAccount(t, account)
 }
 
 func createRandomEntryForAccount(t *testing.T, account Account) Entry {
+	other := createRandomAccount(t)
+	transfer := createRandomTransferForAccounts(t, account.ID, other.ID)
+	amount := util.RandomMoney()
 
-	args := CreateEntryParams{AccountID: account.ID, Amount: util.RandomMoney()}
-
-	entry, err := testQueries.CreateEntry(context.Background(), args)
+	entry, err := createLedgerEntry(context.Background(), testQueries, account.ID, amount, transfer)
 	require.NoError(t, err)
 	require.NotEmpty(t, entry)
 
 	require.Equal(t, entry.AccountID, account.ID)
-	require.Equal(t, entry.Amount, args.Amount)
+	require.Equal(t, entry.Amount, amount)
+	require.Equal(t, transfer.ID, entry.TransferID.Int64)
+	require.NotEmpty(t, entry.Hash)
 
 	return entry
 }
@@ -67,18 +61,6 @@ func TestGetEntryByAccountId(t *testing.T) {
 	compareTwoEntries(t, err, entry2, entry1)
 }
 
-func TestDeleteEntrie(t *testing.T) {
-	entry1 := createRandomEntry(t)
-
-	err := testQueries.DeleteEntry(context.Background(), entry1.ID)
-	require.NoError(t, err)
-
-	entry2, err := testQueries.GetEntry(context.Background(), entry1.ID)
-	require.Error(t, err)
-	require.EqualError(t, err, sql.ErrNoRows.Error())
-	require.Empty(t, entry2)
-}
-
 func TestListEntry(t *testing.T) {
 	for i := 0; i < 10; i++ {
 		createRandomEntry(t)
@@ -117,14 +99,60 @@ func TestListEntryByAccountId(t *testing.T) {
 	}
 }
 
-func TestUpdateEntry(t *testing.T) {
-	entry1 := createRandomEntry(t)
-
-	args := UpdateEntryParams{ID: entry1.ID, Amount: util.RandomMoney()}
-
-	entry2, err := testQueries.UpdateEntry(context.Background(), args)
+func TestListAccountEntries(t *testing.T) {
+	store := NewStore(testDB)
+	account := createAccountOfType(t, AccountChecking, 10000)
+	other := createAccountOfType(t, AccountChecking, 10000)
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account.ID,
+		ToAccountID:   other.ID,
+		Amount:        3000,
+		Currency:      "USD",
+		Reference:     "RENT",
+	})
+	require.NoError(t, err)
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: other.ID,
+		ToAccountID:   account.ID,
+		Amount:        500,
+		Currency:      "USD",
+	})
 	require.NoError(t, err)
-	require.NotEmpty(t, entry2)
 
-	require.Equal(t, entry2.Amount, args.Amount)
+	entries, err := testQueries.ListAccountEntries(context.Background(), ListAccountEntriesParams{
+		AccountID:  account.ID,
+		LimitCount: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	// oldest first, with the balance after each entry counted from the opening balance
+	require.Equal(t, int64(-3000), entries[0].Amount)
+	require.Equal(t, int64(7000), entries[0].BalanceAfter)
+	require.Equal(t, "RENT", entries[0].Reference.String)
+	require.Equal(t, other.ID, entries[0].CounterpartyAccountID.Int64)
+	require.Equal(t, other.Owner, entries[0].CounterpartyOwner.String)
+	require.Equal(t, int64(500), entries[1].Amount)
+	require.Equal(t, int64(7500), entries[1].BalanceAfter)
+	require.Equal(t, other.ID, entries[1].CounterpartyAccountID.Int64)
+
+	// filtering keeps the running balance of the whole history
+	credits, err := testQueries.ListAccountEntries(context.Background(), ListAccountEntriesParams{
+		AccountID:  account.ID,
+		Direction:  sql.NullString{String: "credit", Valid: true},
+		LimitCount: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, credits, 1)
+	require.Equal(t, entries[1].ID, credits[0].ID)
+	require.Equal(t, int64(7500), credits[0].BalanceAfter)
+
+	future, err := testQueries.ListAccountEntries(context.Background(), ListAccountEntriesParams{
+		AccountID:  account.ID,
+		FromTime:   sql.NullTime{Time: entries[1].CreatedAt.Add(time.Hour), Valid: true},
+		LimitCount: 10,
+	})
+	require.NoError(t, err)
+	require.Empty(t, future)
 }
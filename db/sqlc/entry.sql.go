@@ -7,48 +7,54 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
 const createEntry = `-- name: CreateEntry :one
 INSERT INTO entries (
   account_id,
-  amount
+  amount,
+  transfer_id,
+  prev_hash,
+  hash
 ) VALUES (
-  $1, $2
+  $1, $2, $3, $4, $5
 )
-RETURNING id, account_id, amount, created_at
+RETURNING id, account_id, amount, created_at, transfer_id, prev_hash, hash
 `
 
 type CreateEntryParams struct {
-	AccountID int64   `json:"account_id"`
-	Amount    float64 `json:"amount"`
+	AccountID  int64         `json:"account_id"`
+	Amount     int64         `json:"amount"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	PrevHash   []byte        `json:"prev_hash"`
+	Hash       []byte        `json:"hash"`
 }
 
 func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
-	row := q.db.QueryRowContext(ctx, createEntry, arg.AccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createEntry,
+		arg.AccountID,
+		arg.Amount,
+		arg.TransferID,
+		arg.PrevHash,
+		arg.Hash,
+	)
 	var i Entry
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.PrevHash,
+		&i.Hash,
 	)
 	return i, err
 }
 
-const deleteEntry = `-- name: DeleteEntry :exec
-DELETE 
-FROM entries
-WHERE id = $1
-`
-
-func (q *Queries) DeleteEntry(ctx context.Context, id int64) error {
-	_, err := q.db.ExecContext(ctx, deleteEntry, id)
-	return err
-}
-
 const getEntry = `-- name: GetEntry :one
-SELECT id, account_id, amount, created_at 
+SELECT id, account_id, amount, created_at, transfer_id, prev_hash, hash 
 FROM entries
 WHERE id = $1 
 LIMIT 1
@@ -62,12 +68,15 @@ func (q *Queries) GetEntry(ctx context.Context, id int64) (Entry, error) {
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.PrevHash,
+		&i.Hash,
 	)
 	return i, err
 }
 
 const getEntryByAccountId = `-- name: GetEntryByAccountId :one
-SELECT id, account_id, amount, created_at 
+SELECT id, account_id, amount, created_at, transfer_id, prev_hash, hash 
 FROM entries
 WHERE account_id = $1 
 LIMIT 1
@@ -81,12 +90,138 @@ func (q *Queries) GetEntryByAccountId(ctx context.Context, accountID int64) (Ent
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.PrevHash,
+		&i.Hash,
+	)
+	return i, err
+}
+
+const getLastChainedEntry = `-- name: GetLastChainedEntry :one
+SELECT id, account_id, amount, created_at, transfer_id, prev_hash, hash 
+FROM entries
+WHERE account_id = $1
+AND hash IS NOT NULL
+ORDER BY id DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLastChainedEntry(ctx context.Context, accountID int64) (Entry, error) {
+	row := q.db.QueryRowContext(ctx, getLastChainedEntry, accountID)
+	var i Entry
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.TransferID,
+		&i.PrevHash,
+		&i.Hash,
 	)
 	return i, err
 }
 
+const listAccountEntries = `-- name: ListAccountEntries :many
+WITH history AS (
+  SELECT entries.id, entries.account_id, entries.amount, entries.created_at, entries.transfer_id,
+    (accounts.balance - COALESCE(SUM(entries.amount) OVER (
+      ORDER BY entries.created_at, entries.id ROWS BETWEEN 1 FOLLOWING AND UNBOUNDED FOLLOWING
+    ), 0))::bigint AS balance_after
+  FROM entries
+  JOIN accounts ON accounts.id = entries.account_id
+  WHERE entries.account_id = $1
+)
+SELECT history.id, history.account_id, history.amount, history.created_at, history.transfer_id, history.balance_after,
+  transfers.description, transfers.reference,
+  counterparty.id AS counterparty_account_id, counterparty.owner AS counterparty_owner
+FROM history
+LEFT JOIN transfers ON transfers.id = history.transfer_id
+LEFT JOIN accounts AS counterparty ON counterparty.id = CASE
+  WHEN transfers.from_account_id = history.account_id THEN transfers.to_account_id
+  ELSE transfers.from_account_id
+END
+WHERE ($2::timestamp IS NULL OR history.created_at >= $2)
+AND ($3::timestamp IS NULL OR history.created_at < $3)
+AND (
+  $4::varchar IS NULL
+  OR ($4 = 'debit' AND history.amount < 0)
+  OR ($4 = 'credit' AND history.amount > 0)
+)
+AND (history.created_at, history.id) > ($5::timestamp, $6::bigint)
+ORDER BY history.created_at, history.id
+LIMIT $7
+`
+
+type ListAccountEntriesParams struct {
+	AccountID      int64          `json:"account_id"`
+	FromTime       sql.NullTime   `json:"from_time"`
+	ToTime         sql.NullTime   `json:"to_time"`
+	Direction      sql.NullString `json:"direction"`
+	AfterCreatedAt time.Time      `json:"after_created_at"`
+	AfterID        int64          `json:"after_id"`
+	LimitCount     int32          `json:"limit_count"`
+}
+
+type ListAccountEntriesRow struct {
+	ID                    int64          `json:"id"`
+	AccountID             int64          `json:"account_id"`
+	Amount                int64          `json:"amount"`
+	CreatedAt             time.Time      `json:"created_at"`
+	TransferID            sql.NullInt64  `json:"transfer_id"`
+	BalanceAfter          int64          `json:"balance_after"`
+	Description           sql.NullString `json:"description"`
+	Reference             sql.NullString `json:"reference"`
+	CounterpartyAccountID sql.NullInt64  `json:"counterparty_account_id"`
+	CounterpartyOwner     sql.NullString `json:"counterparty_owner"`
+}
+
+// balance_after is the balance of the account right after the entry, taken back from the current balance
+// so it also holds for accounts opened with a balance. It is computed over all entries before filtering,
+// in the (created_at, id) order the keyset pages follow
+func (q *Queries) ListAccountEntries(ctx context.Context, arg ListAccountEntriesParams) ([]ListAccountEntriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountEntries,
+		arg.AccountID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.Direction,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListAccountEntriesRow{}
+	for rows.Next() {
+		var i ListAccountEntriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.BalanceAfter,
+			&i.Description,
+			&i.Reference,
+			&i.CounterpartyAccountID,
+			&i.CounterpartyOwner,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listEntry = `-- name: ListEntry :many
-SELECT id, account_id, amount, created_at 
+SELECT id, account_id, amount, created_at, transfer_id, prev_hash, hash 
 FROM entries
 ORDER BY id
 LIMIT $1
@@ -112,6 +247,9 @@ func (q *Queries) ListEntry(ctx context.Context, arg ListEntryParams) ([]Entry,
 			&i.AccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.TransferID,
+			&i.PrevHash,
+			&i.Hash,
 		); err != nil {
 			return nil, err
 		}
@@ -127,9 +265,10 @@ func (q *Queries) ListEntry(ctx context.Context, arg ListEntryParams) ([]Entry,
 }
 
 const listEntryByAccountId = `-- name: ListEntryByAccountId :many
-SELECT id, account_id, amount, created_at 
+SELECT id, account_id, amount, created_at, transfer_id, prev_hash, hash 
 FROM entries
 WHERE account_id = $1
+ORDER BY id
 LIMIT $2
 OFFSET $3
 `
@@ -154,6 +293,9 @@ func (q *Queries) ListEntryByAccountId(ctx context.Context, arg ListEntryByAccou
 			&i.AccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.TransferID,
+			&i.PrevHash,
+			&i.Hash,
 		); err != nil {
 			return nil, err
 		}
@@ -168,26 +310,70 @@ func (q *Queries) ListEntryByAccountId(ctx context.Context, arg ListEntryByAccou
 	return items, nil
 }
 
-const updateEntry = `-- name: UpdateEntry :one
-UPDATE entries 
-SET amount = $2
-WHERE id = $1
-RETURNING id, account_id, amount, created_at
+const listLedgerEntries = `-- name: ListLedgerEntries :many
+SELECT entries.id, entries.account_id, entries.amount, entries.created_at, entries.transfer_id, entries.prev_hash, entries.hash, transfers.id, transfers.from_account_id, transfers.to_account_id, transfers.amount, transfers.created_at, transfers.currency, transfers.to_amount, transfers.to_currency, transfers.fx_rate, transfers.reversal_of, transfers.reversal_reason, transfers.fee, transfers.fee_account_id, transfers.description, transfers.reference, transfers.metadata
+FROM entries
+JOIN transfers ON transfers.id = entries.transfer_id
+WHERE entries.account_id = $1
+AND entries.id > $2
+ORDER BY entries.id
+LIMIT $3
 `
 
-type UpdateEntryParams struct {
-	ID     int64   `json:"id"`
-	Amount float64 `json:"amount"`
+type ListLedgerEntriesParams struct {
+	AccountID int64 `json:"account_id"`
+	ID        int64 `json:"id"`
+	Limit     int32 `json:"limit"`
 }
 
-func (q *Queries) UpdateEntry(ctx context.Context, arg UpdateEntryParams) (Entry, error) {
-	row := q.db.QueryRowContext(ctx, updateEntry, arg.ID, arg.Amount)
-	var i Entry
-	err := row.Scan(
-		&i.ID,
-		&i.AccountID,
-		&i.Amount,
-		&i.CreatedAt,
-	)
-	return i, err
+type ListLedgerEntriesRow struct {
+	Entry    Entry    `json:"entry"`
+	Transfer Transfer `json:"transfer"`
+}
+
+func (q *Queries) ListLedgerEntries(ctx context.Context, arg ListLedgerEntriesParams) ([]ListLedgerEntriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listLedgerEntries, arg.AccountID, arg.ID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListLedgerEntriesRow{}
+	for rows.Next() {
+		var i ListLedgerEntriesRow
+		if err := rows.Scan(
+			&i.Entry.ID,
+			&i.Entry.AccountID,
+			&i.Entry.Amount,
+			&i.Entry.CreatedAt,
+			&i.Entry.TransferID,
+			&i.Entry.PrevHash,
+			&i.Entry.Hash,
+			&i.Transfer.ID,
+			&i.Transfer.FromAccountID,
+			&i.Transfer.ToAccountID,
+			&i.Transfer.Amount,
+			&i.Transfer.CreatedAt,
+			&i.Transfer.Currency,
+			&i.Transfer.ToAmount,
+			&i.Transfer.ToCurrency,
+			&i.Transfer.FxRate,
+			&i.Transfer.ReversalOf,
+			&i.Transfer.ReversalReason,
+			&i.Transfer.Fee,
+			&i.Transfer.FeeAccountID,
+			&i.Transfer.Description,
+			&i.Transfer.Reference,
+			&i.Transfer.Metadata,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
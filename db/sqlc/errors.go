@@ -0,0 +1,38 @@
+package db
+
+import "errors"
+
+// Different types of error returned by the Store transactions
+var (
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	ErrAccountNotFound   = errors.New("account not found")
+	ErrCurrencyMismatch  = errors.New("currency mismatch")
+	// ErrIdempotencyKeyReused is returned when a key comes with a different transfer or while its first use is in flight
+	ErrIdempotencyKeyReused = errors.New("idempotency key already used")
+	ErrTransferNotFound     = errors.New("transfer not found")
+	// ErrTransferAlreadyReversed is returned when a transfer already has a reversal linked to it
+	ErrTransferAlreadyReversed = errors.New("transfer already reversed")
+	// ErrTransferIsReversal is returned when reversing a reversal, which would just replay the original transfer
+	ErrTransferIsReversal = errors.New("transfer is a reversal")
+	// ErrNoScheduledTransferDue is returned when no scheduled transfer is due or every due one is claimed by another worker
+	ErrNoScheduledTransferDue = errors.New("no scheduled transfer due")
+	ErrHoldNotFound           = errors.New("hold not found")
+	// ErrHoldNotAuthorized is returned when capturing or voiding a hold that was already captured, voided or expired
+	ErrHoldNotAuthorized = errors.New("hold is not authorized")
+	// ErrHoldExpired is returned when capturing a hold past its expiry that the expiry worker didn't release yet
+	ErrHoldExpired = errors.New("hold expired")
+	// ErrCaptureExceedsHold is returned when a capture asks for more than the authorized amount
+	ErrCaptureExceedsHold = errors.New("capture exceeds hold")
+	// ErrAccountNotActive is returned when money would move from or to a frozen or closed account
+	ErrAccountNotActive = errors.New("account is not active")
+	// ErrInvalidStatusTransition is returned when the current status of an account can't move to the requested one
+	ErrInvalidStatusTransition = errors.New("invalid account status transition")
+	// ErrAccountNotEmpty is returned when closing an account that still has a balance or held money
+	ErrAccountNotEmpty = errors.New("account is not empty")
+	// ErrWithdrawalLimitReached is returned when the account type allows no more withdrawals this month
+	ErrWithdrawalLimitReached = errors.New("monthly withdrawal limit reached")
+	// ErrLimitExceeded is matched by the *LimitExceededError of a transfer that would go over a transfer limit
+	ErrLimitExceeded = errors.New("transfer limit exceeded")
+	// ErrBatchFailed is returned when a leg of an all or nothing batch transfer failed and the batch was rolled back
+	ErrBatchFailed = errors.New("batch transfer failed")
+)
@@ -0,0 +1,101 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/big"
+)
+
+// RevenueAccountOwner owns the bank's revenue accounts transfer fees are credited to
+const RevenueAccountOwner = "bank_revenue"
+
+// FeeRule is the fee charged on a transfer, amounts are in minor units of the currency the rule is for
+// The fee is Flat plus BasisPoints of the amount, rounded half up, and then kept between Min and Max
+// A zero Max means no upper bound, a zero rule means no fee
+type FeeRule struct {
+	Flat        int64 `json:"flat"`
+	BasisPoints int64 `json:"basisPoints"`
+	Min         int64 `json:"min"`
+	Max         int64 `json:"max"`
+}
+
+// feeSchedule is the fee rule of each account type by currency, a missing rule means no fee
+var feeSchedule = map[string]map[string]FeeRule{
+	AccountSavings: {
+		"USD": {Flat: 100},
+		"EUR": {Flat: 100},
+		"GEL": {Flat: 200},
+	},
+	AccountOverdraft: {
+		"USD": {BasisPoints: 100, Min: 50, Max: 2500},
+		"EUR": {BasisPoints: 100, Min: 50, Max: 2500},
+		"GEL": {BasisPoints: 100, Min: 100, Max: 5000},
+	},
+}
+
+// GetFeeRule returns the fee rule for transfers out of an account of the type in currency
+func GetFeeRule(accountType string, currency string) FeeRule {
+	return feeSchedule[accountType][currency]
+}
+
+// Fee returns the fee of the rule for amount
+func (r FeeRule) Fee(amount int64) int64 {
+	if r == (FeeRule{}) {
+		return 0
+	}
+
+	// amount * basis points / 10000 rounded half up, in big.Int so large amounts can't overflow
+	percentage := new(big.Int).Mul(big.NewInt(amount), big.NewInt(r.BasisPoints))
+	percentage.Add(percentage, big.NewInt(5000))
+	percentage.Quo(percentage, big.NewInt(10000))
+
+	fee := r.Flat + percentage.Int64()
+	if fee < r.Min {
+		fee = r.Min
+	}
+	if r.Max > 0 && fee > r.Max {
+		fee = r.Max
+	}
+	return fee
+}
+
+// QuoteFee returns the fee of transferring amount out of the account, transfers out of internal accounts are free
+func QuoteFee(account Account, amount int64) int64 {
+	if IsInternalAccount(account) {
+		return 0
+	}
+	return GetFeeRule(account.Type, account.Currency).Fee(amount)
+}
+
+// chargedTransfer runs transfer with the fee of the from account added to arg
+// Only transfers customers make are charged, reversals, cash, captures and interest postings call transfer directly
+func chargedTransfer(ctx context.Context, q *Queries, arg CreateTransferParams) (TransferTxResult, error) {
+	arg, err := withFee(ctx, q, arg)
+	if err != nil {
+		return TransferTxResult{}, err
+	}
+	return transfer(ctx, q, arg)
+}
+
+// withFee returns arg with the fee of its from account and the revenue account the fee is credited to
+// Type and currency of an account never change, so the from account is read without locking it
+func withFee(ctx context.Context, q *Queries, arg CreateTransferParams) (CreateTransferParams, error) {
+	fromAccount, err := q.GetAccount(ctx, arg.FromAccountID)
+	if err == sql.ErrNoRows {
+		return arg, fmt.Errorf("%w: %d", ErrAccountNotFound, arg.FromAccountID)
+	}
+	if err != nil {
+		return arg, err
+	}
+
+	arg.Fee = QuoteFee(fromAccount, arg.Amount)
+	if arg.Fee > 0 {
+		revenueAccount, err := getInternalAccount(ctx, q, RevenueAccountOwner, arg.Currency)
+		if err != nil {
+			return arg, err
+		}
+		arg.FeeAccountID = sql.NullInt64{Int64: revenueAccount.ID, Valid: true}
+	}
+	return arg, nil
+}
@@ -0,0 +1,138 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeeRule(t *testing.T) {
+	testCases := []struct {
+		name    string
+		rule    FeeRule
+		amount  int64
+		wantFee int64
+	}{
+		{name: "NoRule", rule: FeeRule{}, amount: 100000, wantFee: 0},
+		{name: "Flat", rule: FeeRule{Flat: 100}, amount: 100000, wantFee: 100},
+		{name: "Percentage", rule: FeeRule{BasisPoints: 100}, amount: 123456, wantFee: 1235},
+		{name: "RoundsHalfUp", rule: FeeRule{BasisPoints: 100}, amount: 150, wantFee: 2},
+		{name: "Min", rule: FeeRule{BasisPoints: 100, Min: 50}, amount: 1000, wantFee: 50},
+		{name: "Max", rule: FeeRule{BasisPoints: 100, Max: 2500}, amount: 10000000, wantFee: 2500},
+		{name: "FlatAndPercentage", rule: FeeRule{Flat: 25, BasisPoints: 50}, amount: 10000, wantFee: 75},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.wantFee, tc.rule.Fee(tc.amount))
+		})
+	}
+}
+
+func TestQuoteFee(t *testing.T) {
+	require.Equal(t, int64(0), QuoteFee(Account{Type: AccountChecking, Currency: "USD"}, 10000))
+	require.Equal(t, int64(100), QuoteFee(Account{Type: AccountSavings, Currency: "USD"}, 10000))
+	require.Equal(t, int64(200), QuoteFee(Account{Type: AccountSavings, Currency: "GEL"}, 10000))
+	require.Equal(t, int64(0), QuoteFee(Account{Owner: CashAccountOwner, Type: AccountSavings, Currency: "USD"}, 10000))
+}
+
+func TestTransferTxFee(t *testing.T) {
+	store := NewStore(testDB)
+
+	savings := createAccountOfType(t, AccountSavings, 100000)
+	other := createRandomAccountWithCurrency(t, "USD")
+
+	revenue, err := testQueries.GetAccountByOwnerAndCurrency(context.Background(), GetAccountByOwnerAndCurrencyParams{
+		Owner:    RevenueAccountOwner,
+		Currency: "USD",
+	})
+	require.NoError(t, err)
+
+	fee := QuoteFee(savings, 1000)
+	require.Positive(t, fee)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: savings.ID,
+		ToAccountID:   other.ID,
+		Amount:        1000,
+		Currency:      "USD",
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, fee, result.Transfer.Fee)
+	require.Equal(t, revenue.ID, result.Transfer.FeeAccountID.Int64)
+	require.Equal(t, -(1000 + fee), result.FromEntry.Amount)
+	require.Equal(t, int64(1000), result.ToEntry.Amount)
+	require.NotNil(t, result.FeeEntry)
+	require.Equal(t, revenue.ID, result.FeeEntry.AccountID)
+	require.Equal(t, fee, result.FeeEntry.Amount)
+
+	require.Equal(t, savings.Balance-1000-fee, result.FromAccount.Balance)
+	require.Equal(t, other.Balance+1000, result.ToAccount.Balance)
+
+	updatedRevenue, err := testQueries.GetAccount(context.Background(), revenue.ID)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, updatedRevenue.Balance, revenue.Balance+fee)
+
+	// the fee entry is chained like any other entry
+	verification, err := store.VerifyLedger(context.Background(), savings.ID)
+	require.NoError(t, err)
+	require.True(t, verification.Valid)
+
+	// a transfer from a checking account is free
+	result, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: other.ID,
+		ToAccountID:   savings.ID,
+		Amount:        1000,
+		Currency:      "USD",
+	})
+	require.NoError(t, err)
+	require.Zero(t, result.Transfer.Fee)
+	require.Nil(t, result.FeeEntry)
+}
+
+func TestTransferTxFeeDeadlock(t *testing.T) {
+	store := NewStore(testDB)
+
+	overdraft := createAccountOfType(t, AccountOverdraft, 100000)
+	other := createRandomAccountWithCurrency(t, "USD")
+
+	revenue, err := testQueries.GetAccountByOwnerAndCurrency(context.Background(), GetAccountByOwnerAndCurrencyParams{
+		Owner:    RevenueAccountOwner,
+		Currency: "USD",
+	})
+	require.NoError(t, err)
+
+	n := 10
+	errs := make(chan error)
+
+	// charged transfers out of the overdraft account lock the revenue account with their own accounts,
+	// while free transfers out of the revenue account lock it and the overdraft account
+	for i := 0; i < n; i++ {
+		arg := TransferTxParams{
+			FromAccountID: overdraft.ID,
+			ToAccountID:   other.ID,
+			Amount:        100,
+			Currency:      "USD",
+		}
+		if i%2 == 1 {
+			arg.FromAccountID = revenue.ID
+			arg.ToAccountID = overdraft.ID
+		}
+
+		go func() {
+			_, err := store.TransferTx(context.Background(), arg)
+			errs <- err
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		require.NoError(t, <-errs)
+	}
+
+	// all accounts are locked in one ascending order, so none of the transactions deadlocked
+	stats := store.TxStats()
+	require.Equal(t, int64(n), stats.Transactions)
+	require.Zero(t, stats.Retries)
+}
@@ -0,0 +1,33 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpsertFxRate(t *testing.T) {
+	arg := UpsertFxRateParams{
+		BaseCurrency:  "EUR",
+		QuoteCurrency: "GEL",
+		Rate:          "2.9",
+	}
+
+	rate1, err := testQueries.UpsertFxRate(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, "2.9000000000", rate1.Rate)
+
+	arg.Rate = "2.95"
+	rate2, err := testQueries.UpsertFxRate(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, "2.9500000000", rate2.Rate)
+	require.False(t, rate2.UpdatedAt.Before(rate1.UpdatedAt))
+
+	rate3, err := testQueries.GetFxRate(context.Background(), GetFxRateParams{
+		BaseCurrency:  arg.BaseCurrency,
+		QuoteCurrency: arg.QuoteCurrency,
+	})
+	require.NoError(t, err)
+	require.Equal(t, rate2.Rate, rate3.Rate)
+}
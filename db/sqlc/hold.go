@@ -0,0 +1,260 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Statuses of a hold, only an authorized hold reserves money on its from account
+const (
+	HoldAuthorized = "authorized"
+	HoldCaptured   = "captured"
+	HoldVoided     = "voided"
+	HoldExpired    = "expired"
+)
+
+// AuthorizeTxParams contains the inputs of a transfer authorization
+// Amount is in minor units of Currency, which has to be the currency of both accounts
+type AuthorizeTxParams struct {
+	FromAccountID int64     `json:"fromAccountId"`
+	ToAccountID   int64     `json:"toAccountId"`
+	Amount        int64     `json:"amount"`
+	Currency      string    `json:"currency"`
+	ExpiresAt     time.Time `json:"expiresAt"`
+}
+
+// CaptureTxParams contains the inputs of a hold capture, a zero Amount captures the whole hold
+type CaptureTxParams struct {
+	HoldID int64 `json:"holdId"`
+	Amount int64 `json:"amount"`
+}
+
+// HoldTxResult is the result of authorizing, capturing or voiding a hold
+// FromAccount is the from account after its held amount changed, Transfer is only set by a capture
+type HoldTxResult struct {
+	Hold        Hold              `json:"hold"`
+	FromAccount Account           `json:"fromAccount"`
+	Transfer    *TransferTxResult `json:"transfer,omitempty"`
+	// Retries is the number of times the transaction was run again after a serialization failure or deadlock
+	Retries int `json:"-"`
+}
+
+// ANCHOR - AuthorizeTx reserves Amount on the from account as a hold payable to the to account
+// The hold lowers the available balance of the from account but not its ledger balance, nothing is booked until capture
+// The debit rules of the from account type and the transfer limits apply to the authorization instead of its capture,
+// the hold counts against the monthly withdrawals and the limits from now on until it is voided or expires
+// It fails with ErrAccountNotFound, ErrAccountNotActive, ErrCurrencyMismatch, ErrInsufficientFunds,
+// ErrWithdrawalLimitReached or a *LimitExceededError before anything is written
+func (store *SQLStore) AuthorizeTx(ctx context.Context, arg AuthorizeTxParams) (HoldTxResult, error) {
+	var result HoldTxResult
+
+	retries, err := store.execTx(ctx, nil, func(q *Queries) error {
+		accounts, err := lockAccounts(ctx, q, arg.FromAccountID, arg.ToAccountID)
+		if err != nil {
+			return err
+		}
+		fromAccount, toAccount := accounts[arg.FromAccountID], accounts[arg.ToAccountID]
+
+		if err := checkAccountsActive(fromAccount, toAccount); err != nil {
+			return err
+		}
+
+		if fromAccount.Currency != arg.Currency || toAccount.Currency != arg.Currency {
+			return ErrCurrencyMismatch
+		}
+
+		if err := checkDebit(ctx, q, fromAccount, arg.Amount, true); err != nil {
+			return err
+		}
+
+		if err := checkLimits(ctx, q, fromAccount, arg.Amount); err != nil {
+			return err
+		}
+
+		result.Hold, err = q.CreateHold(ctx, CreateHoldParams{
+			FromAccountID: arg.FromAccountID,
+			ToAccountID:   arg.ToAccountID,
+			Amount:        arg.Amount,
+			Currency:      arg.Currency,
+			ExpiresAt:     arg.ExpiresAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.FromAccount, err = q.AddAccountHeld(ctx, AddAccountHeldParams{
+			Amount: arg.Amount,
+			ID:     arg.FromAccountID,
+		})
+		return err
+	})
+	result.Retries = retries
+	return result, err
+}
+
+// ANCHOR - CaptureTx books an authorized hold as a transfer and releases it
+// The whole hold is released even on a partial capture, the part not captured becomes available again
+// The authorization already checked the debit and the limits and reserved the money for the hold,
+// so the capture only moves it and doesn't count against the limits a second time.
+// An account can be frozen or closed after the authorization, so both have to still be active
+// It fails with ErrHoldNotFound, ErrHoldNotAuthorized, ErrHoldExpired, ErrCaptureExceedsHold or ErrAccountNotActive
+// before anything is written
+func (store *SQLStore) CaptureTx(ctx context.Context, arg CaptureTxParams) (HoldTxResult, error) {
+	var result HoldTxResult
+
+	retries, err := store.execTx(ctx, nil, func(q *Queries) error {
+		result = HoldTxResult{}
+
+		hold, err := lockAuthorizedHold(ctx, q, arg.HoldID)
+		if err != nil {
+			return err
+		}
+
+		if !hold.ExpiresAt.After(time.Now()) {
+			return fmt.Errorf("%w: %d", ErrHoldExpired, hold.ID)
+		}
+
+		amount := arg.Amount
+		if amount == 0 {
+			amount = hold.Amount
+		}
+		if amount < 0 || amount > hold.Amount {
+			return fmt.Errorf("%w: %d of %d", ErrCaptureExceedsHold, amount, hold.Amount)
+		}
+
+		// the accounts are locked in the order transfer locks them before the held amount is released
+		accounts, err := lockAccounts(ctx, q, hold.FromAccountID, hold.ToAccountID)
+		if err != nil {
+			return err
+		}
+		if err = checkAccountsActive(accounts[hold.FromAccountID], accounts[hold.ToAccountID]); err != nil {
+			return err
+		}
+		if _, err = releaseHold(ctx, q, hold); err != nil {
+			return err
+		}
+
+		transferResult, err := bookTransfer(ctx, q, CreateTransferParams{
+			FromAccountID: hold.FromAccountID,
+			ToAccountID:   hold.ToAccountID,
+			Amount:        amount,
+			Currency:      hold.Currency,
+			ToAmount:      amount,
+			ToCurrency:    hold.Currency,
+			FxRate:        "1",
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Hold, err = q.UpdateHoldStatus(ctx, UpdateHoldStatusParams{
+			ID:             hold.ID,
+			Status:         HoldCaptured,
+			CapturedAmount: amount,
+			TransferID:     sql.NullInt64{Int64: transferResult.Transfer.ID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		result.FromAccount = transferResult.FromAccount
+		result.Transfer = &transferResult
+		return nil
+	})
+	result.Retries = retries
+	return result, err
+}
+
+// ANCHOR - VoidTx cancels an authorized hold and makes its amount available again
+// An expired hold the expiry worker didn't release yet can still be voided
+// It fails with ErrHoldNotFound or ErrHoldNotAuthorized
+func (store *SQLStore) VoidTx(ctx context.Context, holdID int64) (HoldTxResult, error) {
+	var result HoldTxResult
+
+	retries, err := store.execTx(ctx, nil, func(q *Queries) error {
+		hold, err := lockAuthorizedHold(ctx, q, holdID)
+		if err != nil {
+			return err
+		}
+
+		result.FromAccount, err = releaseHold(ctx, q, hold)
+		if err != nil {
+			return err
+		}
+
+		result.Hold, err = q.UpdateHoldStatus(ctx, UpdateHoldStatusParams{
+			ID:     hold.ID,
+			Status: HoldVoided,
+		})
+		return err
+	})
+	result.Retries = retries
+	return result, err
+}
+
+// ANCHOR - ExpireHoldsTx releases up to limit authorized holds that expired at now and returns them
+// Holds locked by a concurrent capture or void are skipped, the next run picks them up if they are still authorized
+func (store *SQLStore) ExpireHoldsTx(ctx context.Context, now time.Time, limit int32) ([]Hold, error) {
+	var expired []Hold
+
+	_, err := store.execTx(ctx, nil, func(q *Queries) error {
+		expired = nil
+
+		holds, err := q.ClaimExpiredHolds(ctx, ClaimExpiredHoldsParams{
+			ExpiresAt: now,
+			Limit:     limit,
+		})
+		if err != nil {
+			return err
+		}
+
+		// accounts are locked in ascending ID order like everywhere else
+		sort.Slice(holds, func(i, j int) bool {
+			return holds[i].FromAccountID < holds[j].FromAccountID
+		})
+
+		for _, hold := range holds {
+			if _, err := releaseHold(ctx, q, hold); err != nil {
+				return err
+			}
+
+			hold, err = q.UpdateHoldStatus(ctx, UpdateHoldStatusParams{
+				ID:     hold.ID,
+				Status: HoldExpired,
+			})
+			if err != nil {
+				return err
+			}
+			expired = append(expired, hold)
+		}
+		return nil
+	})
+	return expired, err
+}
+
+// lockAuthorizedHold locks the hold row and fails unless the hold still reserves money
+func lockAuthorizedHold(ctx context.Context, q *Queries, holdID int64) (Hold, error) {
+	hold, err := q.GetHoldForUpdate(ctx, holdID)
+	if err == sql.ErrNoRows {
+		return hold, fmt.Errorf("%w: %d", ErrHoldNotFound, holdID)
+	}
+	if err != nil {
+		return hold, err
+	}
+
+	if hold.Status != HoldAuthorized {
+		return hold, fmt.Errorf("%w: %d is %s", ErrHoldNotAuthorized, hold.ID, hold.Status)
+	}
+	return hold, nil
+}
+
+// releaseHold takes the amount of the hold off the held amount of its from account
+func releaseHold(ctx context.Context, q *Queries, hold Hold) (Account, error) {
+	return q.AddAccountHeld(ctx, AddAccountHeldParams{
+		Amount: -hold.Amount,
+		ID:     hold.FromAccountID,
+	})
+}
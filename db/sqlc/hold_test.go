@@ -0,0 +1,295 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func authorizeRandomHold(t *testing.T, store Store, from Account, to Account, amount int64, expiresAt time.Time) HoldTxResult {
+	result, err := store.AuthorizeTx(context.Background(), AuthorizeTxParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        amount,
+		Currency:      from.Currency,
+		ExpiresAt:     expiresAt,
+	})
+	require.NoError(t, err)
+	return result
+}
+
+func TestAuthorizeTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithCurrency(t, "USD")
+	account2 := createRandomAccountWithCurrency(t, "USD")
+
+	result := authorizeRandomHold(t, store, account1, account2, 10, time.Now().Add(time.Hour))
+	require.Equal(t, HoldAuthorized, result.Hold.Status)
+	require.Equal(t, int64(10), result.Hold.Amount)
+
+	// the ledger balance is untouched, only the available balance drops
+	require.Equal(t, account1.Balance, result.FromAccount.Balance)
+	require.Equal(t, account1.HeldAmount+10, result.FromAccount.HeldAmount)
+	require.Equal(t, account1.AvailableBalance-10, result.FromAccount.AvailableBalance)
+
+	// held money can't be transferred away
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        result.FromAccount.AvailableBalance + 1,
+		Currency:      "USD",
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = store.AuthorizeTx(context.Background(), AuthorizeTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        result.FromAccount.AvailableBalance + 1,
+		Currency:      "USD",
+		ExpiresAt:     time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func TestCaptureTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithCurrency(t, "USD")
+	account2 := createRandomAccountWithCurrency(t, "USD")
+
+	authorized := authorizeRandomHold(t, store, account1, account2, 10, time.Now().Add(time.Hour))
+
+	_, err := store.CaptureTx(context.Background(), CaptureTxParams{HoldID: authorized.Hold.ID, Amount: 11})
+	require.ErrorIs(t, err, ErrCaptureExceedsHold)
+
+	result, err := store.CaptureTx(context.Background(), CaptureTxParams{HoldID: authorized.Hold.ID, Amount: 4})
+	require.NoError(t, err)
+	require.Equal(t, HoldCaptured, result.Hold.Status)
+	require.Equal(t, int64(4), result.Hold.CapturedAmount)
+	require.NotNil(t, result.Transfer)
+	require.Equal(t, result.Transfer.Transfer.ID, result.Hold.TransferID.Int64)
+
+	// the rest of the hold is released with the capture
+	require.Equal(t, account1.Balance-4, result.FromAccount.Balance)
+	require.Equal(t, account1.HeldAmount, result.FromAccount.HeldAmount)
+	require.Equal(t, account2.Balance+4, result.Transfer.ToAccount.Balance)
+
+	_, err = store.CaptureTx(context.Background(), CaptureTxParams{HoldID: authorized.Hold.ID})
+	require.ErrorIs(t, err, ErrHoldNotAuthorized)
+
+	_, err = store.CaptureTx(context.Background(), CaptureTxParams{HoldID: authorized.Hold.ID + 1000000})
+	require.ErrorIs(t, err, ErrHoldNotFound)
+}
+
+func TestCaptureTxAfterLimitsFilled(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createAccountOfType(t, AccountChecking, 10000)
+	account2 := createAccountOfType(t, AccountChecking, 10000)
+
+	_, err := testQueries.UpsertAccountTransferLimit(context.Background(), UpsertAccountTransferLimitParams{
+		AccountID:      account1.ID,
+		Currency:       "USD",
+		MaxDailyAmount: limitOf(15),
+		MaxDailyCount:  limitOf(2),
+	})
+	require.NoError(t, err)
+
+	transfer := func(amount int64) error {
+		_, err := store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        amount,
+			Currency:      "USD",
+		})
+		return err
+	}
+
+	authorized := authorizeRandomHold(t, store, account1, account2, 10, time.Now().Add(time.Hour))
+
+	// the open hold already counts against the limits
+	err = transfer(6)
+	var limitErr *LimitExceededError
+	require.True(t, errors.As(err, &limitErr))
+	require.Equal(t, LimitDailyAmount, limitErr.Limit)
+	require.Equal(t, int64(5), limitErr.Remaining)
+
+	require.NoError(t, transfer(5))
+
+	// the hold was within the limits when it was authorized, capturing it is not a second transfer
+	result, err := store.CaptureTx(context.Background(), CaptureTxParams{HoldID: authorized.Hold.ID, Amount: 4})
+	require.NoError(t, err)
+	require.Equal(t, HoldCaptured, result.Hold.Status)
+
+	// only the captured part of the hold stays counted, its transfer is not counted again
+	usage, err := testQueries.GetAccountTransferUsage(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), usage.DailyCount)
+	require.Equal(t, int64(9), usage.DailyAmount)
+
+	err = transfer(1)
+	require.True(t, errors.As(err, &limitErr))
+	require.Equal(t, LimitDailyCount, limitErr.Limit)
+}
+
+func TestCaptureTxAfterWithdrawalsUsed(t *testing.T) {
+	store := NewStore(testDB)
+
+	savings := createAccountOfType(t, AccountSavings, 100000)
+	other := createAccountOfType(t, AccountChecking, 10000)
+
+	authorized := authorizeRandomHold(t, store, savings, other, 100, time.Now().Add(time.Hour))
+
+	// the hold is one of the monthly withdrawals of the savings account
+	accountType, _ := GetAccountType(AccountSavings)
+	for i := int64(1); i < accountType.MaxWithdrawalsPerMonth; i++ {
+		_, err := store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: savings.ID,
+			ToAccountID:   other.ID,
+			Amount:        100,
+			Currency:      "USD",
+		})
+		require.NoError(t, err)
+	}
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: savings.ID,
+		ToAccountID:   other.ID,
+		Amount:        100,
+		Currency:      "USD",
+	})
+	require.ErrorIs(t, err, ErrWithdrawalLimitReached)
+
+	result, err := store.CaptureTx(context.Background(), CaptureTxParams{HoldID: authorized.Hold.ID})
+	require.NoError(t, err)
+	require.Equal(t, savings.Balance-100*accountType.MaxWithdrawalsPerMonth, result.FromAccount.Balance)
+
+	count, err := testQueries.CountMonthlyWithdrawals(context.Background(), savings.ID)
+	require.NoError(t, err)
+	require.Equal(t, accountType.MaxWithdrawalsPerMonth, count)
+}
+
+func TestCaptureTxAccountNotActive(t *testing.T) {
+	store := NewStore(testDB)
+
+	testCases := []struct {
+		name string
+		// deactivate freezes or closes an account of the hold after it was authorized
+		deactivate func(t *testing.T, from Account, to Account)
+	}{
+		{
+			name: "FromAccountFrozen",
+			deactivate: func(t *testing.T, from Account, to Account) {
+				_, err := store.UpdateAccountStatusTx(context.Background(), UpdateAccountStatusTxParams{
+					AccountID: from.ID,
+					Status:    AccountFrozen,
+					Reason:    "fraud review",
+				})
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "ToAccountFrozen",
+			deactivate: func(t *testing.T, from Account, to Account) {
+				_, err := store.UpdateAccountStatusTx(context.Background(), UpdateAccountStatusTxParams{
+					AccountID: to.ID,
+					Status:    AccountFrozen,
+					Reason:    "fraud review",
+				})
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "ToAccountClosed",
+			deactivate: func(t *testing.T, from Account, to Account) {
+				_, err := store.UpdateAccountStatusTx(context.Background(), UpdateAccountStatusTxParams{
+					AccountID: to.ID,
+					Status:    AccountClosed,
+					Reason:    "customer request",
+				})
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			from := createAccountOfType(t, AccountChecking, 10000)
+			// an empty account can be closed with the hold still open
+			to := createAccountOfType(t, AccountChecking, 0)
+
+			authorized := authorizeRandomHold(t, store, from, to, 10, time.Now().Add(time.Hour))
+			tc.deactivate(t, from, to)
+
+			_, err := store.CaptureTx(context.Background(), CaptureTxParams{HoldID: authorized.Hold.ID})
+			require.ErrorIs(t, err, ErrAccountNotActive)
+
+			hold, err := testQueries.GetHold(context.Background(), authorized.Hold.ID)
+			require.NoError(t, err)
+			require.Equal(t, HoldAuthorized, hold.Status)
+			require.False(t, hold.TransferID.Valid)
+		})
+	}
+}
+
+func TestCaptureTxExpired(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithCurrency(t, "USD")
+	account2 := createRandomAccountWithCurrency(t, "USD")
+
+	authorized := authorizeRandomHold(t, store, account1, account2, 10, time.Now().Add(-time.Minute))
+
+	_, err := store.CaptureTx(context.Background(), CaptureTxParams{HoldID: authorized.Hold.ID})
+	require.ErrorIs(t, err, ErrHoldExpired)
+}
+
+func TestVoidTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithCurrency(t, "USD")
+	account2 := createRandomAccountWithCurrency(t, "USD")
+
+	authorized := authorizeRandomHold(t, store, account1, account2, 10, time.Now().Add(time.Hour))
+
+	result, err := store.VoidTx(context.Background(), authorized.Hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, HoldVoided, result.Hold.Status)
+	require.Equal(t, account1.Balance, result.FromAccount.Balance)
+	require.Equal(t, account1.AvailableBalance, result.FromAccount.AvailableBalance)
+
+	_, err = store.VoidTx(context.Background(), authorized.Hold.ID)
+	require.ErrorIs(t, err, ErrHoldNotAuthorized)
+}
+
+func TestExpireHoldsTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithCurrency(t, "USD")
+	account2 := createRandomAccountWithCurrency(t, "USD")
+
+	now := time.Now()
+	expiring := authorizeRandomHold(t, store, account1, account2, 10, now.Add(-time.Minute))
+	pending := authorizeRandomHold(t, store, account1, account2, 5, now.Add(time.Hour))
+
+	expired, err := store.ExpireHoldsTx(context.Background(), now, 1000)
+	require.NoError(t, err)
+
+	var found bool
+	for _, hold := range expired {
+		require.Equal(t, HoldExpired, hold.Status)
+		require.NotEqual(t, pending.Hold.ID, hold.ID)
+		if hold.ID == expiring.Hold.ID {
+			found = true
+		}
+	}
+	require.True(t, found)
+
+	account, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), account.HeldAmount)
+	require.Equal(t, account1.Balance-5, account.AvailableBalance)
+}
@@ -0,0 +1,69 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// requestHash returns a hash of the transfer itself, so the same key can only be replayed with the same transfer
+func (ttx *TransferTxParams) requestHash() string {
+	request := fmt.Sprintf("%d|%d|%d|%s", ttx.FromAccountID, ttx.ToAccountID, ttx.Amount, ttx.Currency)
+	// the details came later, leaving them out of transfers without any keeps keys stored before valid
+	if ttx.Description != "" || ttx.Reference != "" || len(ttx.Metadata) > 0 {
+		request += fmt.Sprintf("|%q|%q|%s", ttx.Description, ttx.Reference, ttx.metadataJSON())
+	}
+	sum := sha256.Sum256([]byte(request))
+	return hex.EncodeToString(sum[:])
+}
+
+// replayTransfer loads the stored result of an already used idempotency key into result
+// It reports whether the key was found and fails with ErrIdempotencyKeyReused if it was used for another transfer
+func replayTransfer(ctx context.Context, q *Queries, arg TransferTxParams, result *TransferTxResult) (bool, error) {
+	key, err := q.GetIdempotencyKey(ctx, GetIdempotencyKeyParams{
+		Username: arg.Username,
+		Key:      arg.IdempotencyKey,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if key.RequestHash != arg.requestHash() {
+		return false, ErrIdempotencyKeyReused
+	}
+
+	err = json.Unmarshal(key.Response, result)
+	if err != nil {
+		return false, fmt.Errorf("cannot decode stored response of idempotency key %s: %w", arg.IdempotencyKey, err)
+	}
+	result.Replayed = true
+	return true, nil
+}
+
+// saveTransferResult stores result under the idempotency key of arg
+// A concurrent request that stored the same key first makes it fail with ErrIdempotencyKeyReused
+func saveTransferResult(ctx context.Context, q *Queries, arg TransferTxParams, result TransferTxResult) error {
+	response, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.CreateIdempotencyKey(ctx, CreateIdempotencyKeyParams{
+		Username:    arg.Username,
+		Key:         arg.IdempotencyKey,
+		RequestHash: arg.requestHash(),
+		Response:    response,
+	})
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+		return ErrIdempotencyKeyReused
+	}
+	return err
+}
@@ -0,0 +1,240 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/T-BO0/bank/util/daycount"
+)
+
+// InterestAccountOwner owns the bank's interest expense accounts interest is paid out of
+const InterestAccountOwner = "bank_interest"
+
+// BankClockOffset is how far the clock created_at columns are recorded in is ahead of UTC, see BankDate
+const BankClockOffset = 4 * time.Hour
+
+// interestBatchSize bounds the accounts loaded at once while accruing
+const interestBatchSize = 100
+
+// InterestRunResult is the outcome of an accrual or posting run
+// Skipped counts accounts that were already done or had nothing to do, Failed counts postings
+// the transfer path rejected, a frozen account for example, which stay unposted for the next run
+type InterestRunResult struct {
+	Date      time.Time `json:"date"`
+	Processed int       `json:"processed"`
+	Skipped   int       `json:"skipped"`
+	Failed    int       `json:"failed"`
+}
+
+// BankDate returns the bank's calendar day t falls on as midnight UTC
+// Ledger timestamps are recorded on the bank's clock, four hours ahead of UTC, so days are cut on it too
+func BankDate(t time.Time) time.Time {
+	t = t.UTC().Add(BankClockOffset)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// ANCHOR - AccrueInterest accrues one day of interest on every account with a rate, based on its end of day balance
+// The interest of the day is kept in fractional minor units with the day count convention of the account currency.
+// Each account is accrued in its own transaction and at most once per day, so a run can be repeated safely.
+// Accounts with a balance of zero or below accrue nothing
+func (store *SQLStore) AccrueInterest(ctx context.Context, day time.Time) (InterestRunResult, error) {
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	result := InterestRunResult{Date: day}
+
+	var lastID int64
+	for {
+		accounts, err := store.ListInterestBearingAccounts(ctx, ListInterestBearingAccountsParams{
+			ID:    lastID,
+			Limit: interestBatchSize,
+		})
+		if err != nil {
+			return result, err
+		}
+
+		for _, account := range accounts {
+			accrued, err := store.accrueAccountInterest(ctx, account, day)
+			if err != nil {
+				return result, fmt.Errorf("accrue interest of account %d: %w", account.ID, err)
+			}
+			if accrued {
+				result.Processed++
+			} else {
+				result.Skipped++
+			}
+			lastID = account.ID
+		}
+
+		if len(accounts) < interestBatchSize {
+			return result, nil
+		}
+	}
+}
+
+// accrueAccountInterest records the interest of day on the account and reports false if there was nothing to record
+func (store *SQLStore) accrueAccountInterest(ctx context.Context, account Account, day time.Time) (bool, error) {
+	endOfDay := day.AddDate(0, 0, 1)
+	if !account.CreatedAt.Before(endOfDay) {
+		return false, nil
+	}
+
+	var accrued bool
+	_, err := store.execTx(ctx, nil, func(q *Queries) error {
+		balance, err := q.GetEndOfDayBalance(ctx, GetEndOfDayBalanceParams{
+			EndOfDay:  endOfDay,
+			AccountID: account.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		accrued = false
+		if balance <= 0 {
+			return nil
+		}
+
+		convention := daycount.ForCurrency(account.Currency)
+		amount, err := dailyInterest(balance, account.InterestRate, convention)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.CreateInterestAccrual(ctx, CreateInterestAccrualParams{
+			AccountID:   account.ID,
+			AccrualDate: day,
+			Balance:     balance,
+			Rate:        account.InterestRate,
+			DayCount:    string(convention),
+			Amount:      amount,
+		})
+		if err == sql.ErrNoRows {
+			// the day was accrued by an earlier run
+			return nil
+		}
+		accrued = err == nil
+		return err
+	})
+	return accrued, err
+}
+
+// ANCHOR - PostInterest pays the interest accrued up to the end of month into each account
+// The month is given by any time in it, accruals of earlier months that could not be posted are included.
+// The accrued amounts are summed and rounded half up to minor units once, then booked as a transfer from
+// the interest expense account of the currency. Posted accruals are marked, so a run can be repeated safely
+func (store *SQLStore) PostInterest(ctx context.Context, month time.Time) (InterestRunResult, error) {
+	monthStart := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	periodEnd := monthStart.AddDate(0, 1, 0)
+	result := InterestRunResult{Date: monthStart}
+
+	accountIDs, err := store.ListAccountsWithUnpostedInterest(ctx, periodEnd)
+	if err != nil {
+		return result, err
+	}
+
+	for _, accountID := range accountIDs {
+		posted, err := store.postAccountInterest(ctx, accountID, periodEnd)
+		switch {
+		case isTransferRejection(err):
+			result.Failed++
+		case err != nil:
+			return result, fmt.Errorf("post interest of account %d: %w", accountID, err)
+		case posted:
+			result.Processed++
+		default:
+			result.Skipped++
+		}
+	}
+	return result, nil
+}
+
+// postAccountInterest books the unposted accruals of the account before periodEnd
+// and reports false if another run posted them first or they rounded to nothing
+func (store *SQLStore) postAccountInterest(ctx context.Context, accountID int64, periodEnd time.Time) (bool, error) {
+	var posted bool
+	_, err := store.execTx(ctx, nil, func(q *Queries) error {
+		posted = false
+
+		accruals, err := q.ClaimUnpostedInterestAccruals(ctx, ClaimUnpostedInterestAccrualsParams{
+			AccountID:   accountID,
+			AccrualDate: periodEnd,
+		})
+		if err != nil || len(accruals) == 0 {
+			return err
+		}
+
+		total, err := sumInterest(accruals)
+		if err != nil {
+			return err
+		}
+
+		mark := MarkInterestAccrualsPostedParams{}
+		for _, accrual := range accruals {
+			mark.Ids = append(mark.Ids, accrual.ID)
+		}
+
+		if total > 0 {
+			account, err := q.GetAccount(ctx, accountID)
+			if err != nil {
+				return err
+			}
+
+			interestAccount, err := getInternalAccount(ctx, q, InterestAccountOwner, account.Currency)
+			if err != nil {
+				return err
+			}
+
+			transferResult, err := transfer(ctx, q, CreateTransferParams{
+				FromAccountID: interestAccount.ID,
+				ToAccountID:   account.ID,
+				Amount:        total,
+				Currency:      account.Currency,
+				ToAmount:      total,
+				ToCurrency:    account.Currency,
+				FxRate:        "1",
+			})
+			if err != nil {
+				return err
+			}
+			mark.TransferID = sql.NullInt64{Int64: transferResult.Transfer.ID, Valid: true}
+			posted = true
+		}
+
+		return q.MarkInterestAccrualsPosted(ctx, mark)
+	})
+	return posted, err
+}
+
+// dailyInterest returns balance * rate * the day fraction of convention as a decimal string of minor units
+func dailyInterest(balance int64, rate string, convention daycount.Convention) (string, error) {
+	annualRate, ok := new(big.Rat).SetString(rate)
+	if !ok {
+		return "", fmt.Errorf("invalid interest rate %q", rate)
+	}
+
+	amount := new(big.Rat).SetInt64(balance)
+	amount.Mul(amount, annualRate)
+	amount.Mul(amount, convention.DayFraction())
+	return amount.FloatString(8), nil
+}
+
+// sumInterest adds up the accrued amounts and rounds the sum half up to whole minor units
+func sumInterest(accruals []InterestAccrual) (int64, error) {
+	total := new(big.Rat)
+	for _, accrual := range accruals {
+		amount, ok := new(big.Rat).SetString(accrual.Amount)
+		if !ok {
+			return 0, fmt.Errorf("invalid accrued amount %q of accrual %d", accrual.Amount, accrual.ID)
+		}
+		total.Add(total, amount)
+	}
+
+	if total.Sign() < 0 {
+		return 0, errors.New("accrued interest is negative")
+	}
+	// FloatString rounds half away from zero, which is half up for a positive sum
+	return strconv.ParseInt(total.FloatString(0), 10, 64)
+}
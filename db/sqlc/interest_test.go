@@ -0,0 +1,87 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/T-BO0/bank/util/daycount"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDailyInterest(t *testing.T) {
+	// 1000.00 at 3.65% for one day
+	amount, err := dailyInterest(100000, "0.0365", daycount.Actual365Fixed)
+	require.NoError(t, err)
+	require.Equal(t, "10.00000000", amount)
+
+	amount, err = dailyInterest(100000, "0.0365", daycount.Actual360)
+	require.NoError(t, err)
+	require.Equal(t, "10.13888889", amount)
+
+	_, err = dailyInterest(100000, "abc", daycount.Actual360)
+	require.Error(t, err)
+}
+
+func TestSumInterest(t *testing.T) {
+	total, err := sumInterest([]InterestAccrual{{Amount: "0.25000000"}, {Amount: "0.25000000"}})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), total)
+
+	total, err = sumInterest([]InterestAccrual{{Amount: "0.24999999"}, {Amount: "0.25000000"}})
+	require.NoError(t, err)
+	require.Equal(t, int64(0), total)
+
+	_, err = sumInterest([]InterestAccrual{{ID: 1, Amount: "x"}})
+	require.Error(t, err)
+}
+
+func TestBankDate(t *testing.T) {
+	require.Equal(t, time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC),
+		BankDate(time.Date(2024, time.February, 29, 20, 0, 0, 0, time.UTC)))
+	require.Equal(t, time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC),
+		BankDate(time.Date(2024, time.February, 29, 19, 59, 0, 0, time.UTC)))
+}
+
+func TestAccrueAndPostInterest(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccountWithCurrency(t, "GEL")
+	account, err := testQueries.UpdateAccountInterestRate(context.Background(), UpdateAccountInterestRateParams{
+		ID:           account.ID,
+		InterestRate: "0.05",
+	})
+	require.NoError(t, err)
+
+	// accrue the day the account was opened on, far enough back to be its own month
+	day := time.Date(account.CreatedAt.Year(), account.CreatedAt.Month(), account.CreatedAt.Day(), 0, 0, 0, 0, time.UTC)
+
+	first, err := store.AccrueInterest(context.Background(), day)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, first.Processed, 1)
+
+	// a second run for the same day accrues nothing new
+	again, err := store.AccrueInterest(context.Background(), day)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, again.Skipped, 1)
+
+	posted, err := store.PostInterest(context.Background(), day)
+	require.NoError(t, err)
+	require.Equal(t, day.AddDate(0, 0, 1-day.Day()), posted.Date)
+
+	want, err := dailyInterest(account.Balance, "0.05", daycount.Actual365Fixed)
+	require.NoError(t, err)
+	interest, err := sumInterest([]InterestAccrual{{Amount: want}})
+	require.NoError(t, err)
+
+	updated, err := testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance+interest, updated.Balance)
+
+	// posting is idempotent too
+	_, err = store.PostInterest(context.Background(), day)
+	require.NoError(t, err)
+	updated, err = testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance+interest, updated.Balance)
+}
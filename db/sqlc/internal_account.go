@@ -0,0 +1,31 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Owners of the bank's internal accounts, the underscore keeps them out of reach of user registration
+const (
+	internalOwnerPrefix = "bank_"
+	CashAccountOwner    = "bank_cash"
+)
+
+// IsInternalAccount reports whether the account belongs to the bank itself and may go below zero
+func IsInternalAccount(account Account) bool {
+	return strings.HasPrefix(account.Owner, internalOwnerPrefix)
+}
+
+// getInternalAccount returns the internal account of owner in the given currency
+func getInternalAccount(ctx context.Context, q *Queries, owner string, currency string) (Account, error) {
+	account, err := q.GetAccountByOwnerAndCurrency(ctx, GetAccountByOwnerAndCurrencyParams{
+		Owner:    owner,
+		Currency: currency,
+	})
+	if err == sql.ErrNoRows {
+		return account, fmt.Errorf("%w: no %s account in %s", ErrAccountNotFound, owner, currency)
+	}
+	return account, err
+}
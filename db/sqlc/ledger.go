@@ -0,0 +1,129 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"fmt"
+)
+
+// ledgerPageSize is the number of entries VerifyLedger loads per query
+const ledgerPageSize = 500
+
+// LedgerVerification is the result of walking the hash chain of an account
+// BrokenEntryID and Reason are only set when Valid is false and point to the first entry that does not check out
+type LedgerVerification struct {
+	AccountID      int64  `json:"accountId"`
+	EntriesChecked int64  `json:"entriesChecked"`
+	Valid          bool   `json:"valid"`
+	BrokenEntryID  int64  `json:"brokenEntryId,omitempty"`
+	Reason         string `json:"reason,omitempty"`
+}
+
+// entryHash chains an entry to the previous one of its account
+// It covers the entry and every column of its transfer, so editing either one breaks the chain
+func entryHash(prevHash []byte, accountID int64, amount int64, transfer Transfer) []byte {
+	h := sha256.New()
+	fmt.Fprintf(h, "%x|%d|%d|%d|%d|%d|%d|%q|%d|%q|%q|%d|%d|%q",
+		prevHash,
+		accountID,
+		amount,
+		transfer.ID,
+		transfer.FromAccountID,
+		transfer.ToAccountID,
+		transfer.Amount,
+		transfer.Currency,
+		transfer.ToAmount,
+		transfer.ToCurrency,
+		transfer.FxRate,
+		transfer.CreatedAt.UnixMicro(),
+		transfer.ReversalOf.Int64,
+		transfer.ReversalReason.String,
+	)
+	// the fee columns came later, leaving them out of transfers without a fee keeps older chains valid
+	if transfer.Fee != 0 {
+		fmt.Fprintf(h, "|%d|%d", transfer.Fee, transfer.FeeAccountID.Int64)
+	}
+	// so do the details, metadata is hashed as postgres returns jsonb, which is the same on every read
+	if transfer.Description.Valid || transfer.Reference.Valid || !bytes.Equal(transfer.Metadata, emptyMetadata) {
+		fmt.Fprintf(h, "|%q|%q|%s", transfer.Description.String, transfer.Reference.String, transfer.Metadata)
+	}
+	return h.Sum(nil)
+}
+
+// createLedgerEntry appends an entry of transfer to the hash chain of the account
+// The account has to be locked by the caller, otherwise two entries could chain to the same previous one
+func createLedgerEntry(ctx context.Context, q *Queries, accountID int64, amount int64, transfer Transfer) (Entry, error) {
+	var prevHash []byte
+	last, err := q.GetLastChainedEntry(ctx, accountID)
+	if err == nil {
+		prevHash = last.Hash
+	} else if err != sql.ErrNoRows {
+		return Entry{}, err
+	}
+
+	return q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  accountID,
+		Amount:     amount,
+		TransferID: sql.NullInt64{Int64: transfer.ID, Valid: true},
+		PrevHash:   prevHash,
+		Hash:       entryHash(prevHash, accountID, amount, transfer),
+	})
+}
+
+// ANCHOR - VerifyLedger walks the hash chain of the account from its first chained entry
+// and reports the first entry whose link or contents do not match, entries written before the chain existed are skipped
+func (store *SQLStore) VerifyLedger(ctx context.Context, accountID int64) (LedgerVerification, error) {
+	result := LedgerVerification{AccountID: accountID, Valid: true}
+
+	_, err := store.GetAccount(ctx, accountID)
+	if err == sql.ErrNoRows {
+		return result, fmt.Errorf("%w: %d", ErrAccountNotFound, accountID)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	var prevHash []byte
+	var afterID int64
+	for {
+		rows, err := store.ListLedgerEntries(ctx, ListLedgerEntriesParams{
+			AccountID: accountID,
+			ID:        afterID,
+			Limit:     ledgerPageSize,
+		})
+		if err != nil {
+			return result, err
+		}
+
+		for _, row := range rows {
+			result.EntriesChecked++
+			if reason := checkLedgerEntry(prevHash, row); reason != "" {
+				result.Valid = false
+				result.BrokenEntryID = row.Entry.ID
+				result.Reason = reason
+				return result, nil
+			}
+			prevHash = row.Entry.Hash
+			afterID = row.Entry.ID
+		}
+
+		if len(rows) < ledgerPageSize {
+			return result, nil
+		}
+	}
+}
+
+// checkLedgerEntry returns why the entry does not follow prevHash, or an empty string if it does
+func checkLedgerEntry(prevHash []byte, row ListLedgerEntriesRow) string {
+	switch {
+	case row.Entry.Hash == nil:
+		return "entry has no hash"
+	case !bytes.Equal(row.Entry.PrevHash, prevHash):
+		return "prev_hash does not match the previous entry"
+	case !bytes.Equal(row.Entry.Hash, entryHash(prevHash, row.Entry.AccountID, row.Entry.Amount, row.Transfer)):
+		return "hash does not match the entry or its transfer"
+	}
+	return ""
+}
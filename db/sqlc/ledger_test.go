@@ -0,0 +1,85 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLedgerHashChain(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithCurrency(t, "USD")
+	account2 := createRandomAccountWithCurrency(t, "USD")
+
+	var results []TransferTxResult
+	for i := 0; i < 3; i++ {
+		result, err := store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        1,
+			Currency:      "USD",
+		})
+		require.NoError(t, err)
+		results = append(results, result)
+	}
+
+	require.Nil(t, results[0].FromEntry.PrevHash)
+	for i := 1; i < len(results); i++ {
+		require.Equal(t, results[i-1].FromEntry.Hash, results[i].FromEntry.PrevHash)
+		require.Equal(t, results[i-1].ToEntry.Hash, results[i].ToEntry.PrevHash)
+	}
+
+	verification, err := store.VerifyLedger(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.True(t, verification.Valid)
+	require.Equal(t, int64(3), verification.EntriesChecked)
+	require.Zero(t, verification.BrokenEntryID)
+}
+
+func TestLedgerIsAppendOnly(t *testing.T) {
+	transfer := createRandomTransfer(t)
+	entry := createRandomEntryForAccount(t, createRandomAccount(t))
+
+	_, err := testDB.Exec("UPDATE entries SET amount = amount + 1 WHERE id = $1", entry.ID)
+	require.Error(t, err)
+
+	_, err = testDB.Exec("DELETE FROM entries WHERE id = $1", entry.ID)
+	require.Error(t, err)
+
+	_, err = testDB.Exec("UPDATE transfers SET amount = amount + 1 WHERE id = $1", transfer.ID)
+	require.Error(t, err)
+
+	_, err = testDB.Exec("DELETE FROM transfers WHERE id = $1", transfer.ID)
+	require.Error(t, err)
+}
+
+func TestVerifyLedgerAccountNotFound(t *testing.T) {
+	store := NewStore(testDB)
+
+	_, err := store.VerifyLedger(context.Background(), -1)
+	require.ErrorIs(t, err, ErrAccountNotFound)
+}
+
+func TestCheckLedgerEntry(t *testing.T) {
+	transfer := Transfer{ID: 1, FromAccountID: 1, ToAccountID: 2, Amount: 100, Currency: "USD", ToAmount: 100, ToCurrency: "USD", FxRate: "1"}
+	first := Entry{ID: 1, AccountID: 1, Amount: -100, Hash: entryHash(nil, 1, -100, transfer)}
+	second := Entry{ID: 2, AccountID: 1, Amount: -100, PrevHash: first.Hash, Hash: entryHash(first.Hash, 1, -100, transfer)}
+
+	require.Empty(t, checkLedgerEntry(nil, ListLedgerEntriesRow{Entry: first, Transfer: transfer}))
+	require.Empty(t, checkLedgerEntry(first.Hash, ListLedgerEntriesRow{Entry: second, Transfer: transfer}))
+
+	// a skipped entry breaks the link
+	require.NotEmpty(t, checkLedgerEntry(nil, ListLedgerEntriesRow{Entry: second, Transfer: transfer}))
+
+	// an edited amount breaks the hash
+	edited := second
+	edited.Amount = -1
+	require.NotEmpty(t, checkLedgerEntry(first.Hash, ListLedgerEntriesRow{Entry: edited, Transfer: transfer}))
+
+	// so does an edited transfer
+	editedTransfer := transfer
+	editedTransfer.Amount = 1
+	require.NotEmpty(t, checkLedgerEntry(first.Hash, ListLedgerEntriesRow{Entry: second, Transfer: editedTransfer}))
+}
@@ -5,32 +5,165 @@
 package db
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
 )
 
 type Account struct {
-	ID        int64     `json:"id"`
-	Owner     string    `json:"owner"`
-	Balance   float64   `json:"balance"`
+	ID    int64  `json:"id"`
+	Owner string `json:"owner"`
+	// in minor units of the account currency
+	Balance   int64     `json:"balance"`
 	Currency  string    `json:"currency"`
 	CreatedAt time.Time `json:"created_at"`
+	// sum of the authorized holds against the account, in minor units
+	HeldAmount int64 `json:"held_amount"`
+	// balance minus held_amount, what a new transfer can spend
+	AvailableBalance int64 `json:"available_balance"`
+	// active, frozen or closed, only active accounts send and receive money
+	Status string `json:"status"`
+	// why the status was last changed
+	StatusReason    sql.NullString `json:"status_reason"`
+	StatusChangedAt sql.NullTime   `json:"status_changed_at"`
+	// checking, savings or overdraft, the rules of each type live in the application
+	Type string `json:"type"`
+	// annual interest rate as a fraction, 0.025 is 2.5%
+	InterestRate string `json:"interest_rate"`
 }
 
 type Entry struct {
 	ID        int64 `json:"id"`
 	AccountID int64 `json:"account_id"`
-	// can be negative or positive
-	Amount    float64   `json:"amount"`
-	CreatedAt time.Time `json:"created_at"`
+	// in minor units, can be negative or positive
+	Amount     int64         `json:"amount"`
+	CreatedAt  time.Time     `json:"created_at"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	// hash of the previous chained entry of the account, null for the first one
+	PrevHash []byte `json:"prev_hash"`
+	// sha256 over prev_hash, the entry and its transfer
+	Hash []byte `json:"hash"`
+}
+
+type FxRate struct {
+	BaseCurrency  string `json:"base_currency"`
+	QuoteCurrency string `json:"quote_currency"`
+	// units of quote currency for one unit of base currency
+	Rate      string    `json:"rate"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type Hold struct {
+	ID            int64 `json:"id"`
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+	// authorized amount in minor units, reserved on the from account while authorized
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+	// authorized, captured, voided or expired
+	Status         string `json:"status"`
+	CapturedAmount int64  `json:"captured_amount"`
+	// the transfer a capture created
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	ExpiresAt  time.Time     `json:"expires_at"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+type IdempotencyKey struct {
+	Username string `json:"username"`
+	Key      string `json:"key"`
+	// sha256 of the request the key was first used with
+	RequestHash string `json:"request_hash"`
+	// serialized result returned on replay
+	Response  json.RawMessage `json:"response"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type InterestAccrual struct {
+	ID          int64     `json:"id"`
+	AccountID   int64     `json:"account_id"`
+	AccrualDate time.Time `json:"accrual_date"`
+	// end of day balance the interest accrued on, in minor units
+	Balance  int64  `json:"balance"`
+	Rate     string `json:"rate"`
+	DayCount string `json:"day_count"`
+	// interest of the day in fractional minor units, rounded once the month is posted
+	Amount string `json:"amount"`
+	// the posting transfer, null when the month rounded to nothing
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	PostedAt   sql.NullTime  `json:"posted_at"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+type ScheduledTransfer struct {
+	ID            int64  `json:"id"`
+	Owner         string `json:"owner"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	// in minor units, must be positive
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+	// cron expression, @every <duration>, a macro like @monthly or @once
+	Rule      string       `json:"rule"`
+	NextRunAt time.Time    `json:"next_run_at"`
+	EndAt     sql.NullTime `json:"end_at"`
+	Active    bool         `json:"active"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type ScheduledTransferRun struct {
+	ID                  int64 `json:"id"`
+	ScheduledTransferID int64 `json:"scheduled_transfer_id"`
+	// the next_run_at the run was due at
+	RunAt time.Time `json:"run_at"`
+	// succeeded or failed
+	Status     string         `json:"status"`
+	TransferID sql.NullInt64  `json:"transfer_id"`
+	Error      sql.NullString `json:"error"`
+	CreatedAt  time.Time      `json:"created_at"`
 }
 
 type Transfer struct {
 	ID            int64 `json:"id"`
 	FromAccountID int64 `json:"from_account_id"`
 	ToAccountID   int64 `json:"to_account_id"`
-	// can not be negative
-	Amount    float64   `json:"amount"`
+	// in minor units, can not be negative
+	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	Currency  string    `json:"currency"`
+	// in minor units of to_currency, credited to the to account
+	ToAmount   int64  `json:"to_amount"`
+	ToCurrency string `json:"to_currency"`
+	// applied rate from currency to to_currency
+	FxRate string `json:"fx_rate"`
+	// the transfer this one compensates, a transfer can be reversed only once
+	ReversalOf     sql.NullInt64  `json:"reversal_of"`
+	ReversalReason sql.NullString `json:"reversal_reason"`
+	// in minor units of currency, debited from the from account on top of amount
+	Fee int64 `json:"fee"`
+	// the revenue account credited with the fee
+	FeeAccountID sql.NullInt64 `json:"fee_account_id"`
+	// free text shown to both parties, what the payment is for
+	Description sql.NullString `json:"description"`
+	// reference of the payer, like an invoice number, searchable
+	Reference sql.NullString `json:"reference"`
+	// string keys and values the client attached to the transfer
+	Metadata json.RawMessage `json:"metadata"`
+}
+
+type TransferLimit struct {
+	ID        int64          `json:"id"`
+	Username  sql.NullString `json:"username"`
+	AccountID sql.NullInt64  `json:"account_id"`
+	Currency  string         `json:"currency"`
+	// in minor units of currency, null means no limit like every max_ column
+	MaxPerTransfer sql.NullInt64 `json:"max_per_transfer"`
+	MaxDailyAmount sql.NullInt64 `json:"max_daily_amount"`
+	// outgoing transfers per bank day, reversals do not count
+	MaxDailyCount    sql.NullInt64 `json:"max_daily_count"`
+	MaxMonthlyAmount sql.NullInt64 `json:"max_monthly_amount"`
+	MaxMonthlyCount  sql.NullInt64 `json:"max_monthly_count"`
+	UpdatedAt        time.Time     `json:"updated_at"`
 }
 
 type User struct {
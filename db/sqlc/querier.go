@@ -6,36 +6,86 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
 type Querier interface {
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	AddAccountHeld(ctx context.Context, arg AddAccountHeldParams) (Account, error)
+	ClaimDueScheduledTransfer(ctx context.Context, nextRunAt time.Time) (ScheduledTransfer, error)
+	ClaimExpiredHolds(ctx context.Context, arg ClaimExpiredHoldsParams) ([]Hold, error)
+	ClaimUnpostedInterestAccruals(ctx context.Context, arg ClaimUnpostedInterestAccrualsParams) ([]InterestAccrual, error)
+	CountAccounts(ctx context.Context) (int64, error)
+	// a hold counts from its authorization, the transfer its capture booked is not counted again
+	CountMonthlyWithdrawals(ctx context.Context, fromAccountID int64) (int64, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateHold(ctx context.Context, arg CreateHoldParams) (Hold, error)
+	CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error)
+	CreateInterestAccrual(ctx context.Context, arg CreateInterestAccrualParams) (InterestAccrual, error)
+	CreateScheduledTransfer(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error)
+	CreateScheduledTransferRun(ctx context.Context, arg CreateScheduledTransferRunParams) (ScheduledTransferRun, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
-	DeleteAccount(ctx context.Context, id int64) error
-	DeleteEntry(ctx context.Context, id int64) error
-	DeleteTransfer(ctx context.Context, id int64) error
 	GetAccount(ctx context.Context, id int64) (Account, error)
+	GetAccountByOwnerAndCurrency(ctx context.Context, arg GetAccountByOwnerAndCurrencyParams) (Account, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	GetAccountTransferLimit(ctx context.Context, accountID sql.NullInt64) (TransferLimit, error)
+	// authorized and captured holds count from their authorization with what they reserved or captured,
+	// the transfers captures booked are not counted again
+	GetAccountTransferUsage(ctx context.Context, fromAccountID int64) (GetAccountTransferUsageRow, error)
+	GetEndOfDayBalance(ctx context.Context, arg GetEndOfDayBalanceParams) (int64, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
 	GetEntryByAccountId(ctx context.Context, accountID int64) (Entry, error)
+	GetFxRate(ctx context.Context, arg GetFxRateParams) (FxRate, error)
+	GetHold(ctx context.Context, id int64) (Hold, error)
+	GetHoldForUpdate(ctx context.Context, id int64) (Hold, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
+	GetLastChainedEntry(ctx context.Context, accountID int64) (Entry, error)
+	GetLastInterestAccrualDate(ctx context.Context) (time.Time, error)
+	// the usage of all accounts of owner in currency, holds count like in GetAccountTransferUsage
+	GetOwnerTransferUsage(ctx context.Context, arg GetOwnerTransferUsageParams) (GetOwnerTransferUsageRow, error)
+	GetScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error)
+	GetStatementBalances(ctx context.Context, arg GetStatementBalancesParams) (GetStatementBalancesRow, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
 	GetTransferByAccounts(ctx context.Context, arg GetTransferByAccountsParams) (Transfer, error)
 	GetTransferByFromAccountId(ctx context.Context, fromAccountID int64) (Transfer, error)
 	GetTransferByToAccountId(ctx context.Context, toAccountID int64) (Transfer, error)
+	GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error)
+	GetTransferReversal(ctx context.Context, reversalOf sql.NullInt64) (Transfer, error)
 	GetUser(ctx context.Context, username string) (User, error)
+	GetUserTransferLimit(ctx context.Context, arg GetUserTransferLimitParams) (TransferLimit, error)
+	GetUserTransferLimitForUpdate(ctx context.Context, arg GetUserTransferLimitForUpdateParams) (TransferLimit, error)
 	ListAccount(ctx context.Context, arg ListAccountParams) ([]Account, error)
+	ListAccountByOwner(ctx context.Context, arg ListAccountByOwnerParams) ([]Account, error)
+	ListAccountByOwnerAfter(ctx context.Context, arg ListAccountByOwnerAfterParams) ([]Account, error)
+	ListAccountEntries(ctx context.Context, arg ListAccountEntriesParams) ([]ListAccountEntriesRow, error)
+	ListAccountsWithUnpostedInterest(ctx context.Context, accrualDate time.Time) ([]int64, error)
+	ListBalanceMismatches(ctx context.Context) ([]ListBalanceMismatchesRow, error)
 	ListEntry(ctx context.Context, arg ListEntryParams) ([]Entry, error)
 	ListEntryByAccountId(ctx context.Context, arg ListEntryByAccountIdParams) ([]Entry, error)
+	ListFxRates(ctx context.Context) ([]FxRate, error)
+	ListInterestBearingAccounts(ctx context.Context, arg ListInterestBearingAccountsParams) ([]Account, error)
+	ListLedgerEntries(ctx context.Context, arg ListLedgerEntriesParams) ([]ListLedgerEntriesRow, error)
+	ListOrphanedEntries(ctx context.Context) ([]Entry, error)
+	ListScheduledTransferRuns(ctx context.Context, arg ListScheduledTransferRunsParams) ([]ScheduledTransferRun, error)
+	ListScheduledTransfersByOwner(ctx context.Context, arg ListScheduledTransfersByOwnerParams) ([]ScheduledTransfer, error)
 	ListTransfer(ctx context.Context, arg ListTransferParams) ([]Transfer, error)
 	ListTransferByAccounts(ctx context.Context, arg ListTransferByAccountsParams) ([]Transfer, error)
 	ListTransferByFromAccountId(ctx context.Context, arg ListTransferByFromAccountIdParams) ([]Transfer, error)
+	// keyset page of the transfers in or out of an account of owner, after the cursor in (created_at, id) order
+	ListTransferByOwnerAfter(ctx context.Context, arg ListTransferByOwnerAfterParams) ([]Transfer, error)
+	ListTransferByReference(ctx context.Context, arg ListTransferByReferenceParams) ([]Transfer, error)
 	ListTransferByToAccountId(ctx context.Context, arg ListTransferByToAccountIdParams) ([]Transfer, error)
-	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
-	UpdateEntry(ctx context.Context, arg UpdateEntryParams) (Entry, error)
-	UpdateTransfer(ctx context.Context, arg UpdateTransferParams) (Transfer, error)
+	ListUnbalancedTransfers(ctx context.Context) ([]ListUnbalancedTransfersRow, error)
+	UpdateAccountInterestRate(ctx context.Context, arg UpdateAccountInterestRateParams) (Account, error)
+	UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error)
+	UpdateHoldStatus(ctx context.Context, arg UpdateHoldStatusParams) (Hold, error)
+	UpdateScheduledTransfer(ctx context.Context, arg UpdateScheduledTransferParams) (ScheduledTransfer, error)
+	UpsertAccountTransferLimit(ctx context.Context, arg UpsertAccountTransferLimitParams) (TransferLimit, error)
+	UpsertFxRate(ctx context.Context, arg UpsertFxRateParams) (FxRate, error)
+	UpsertUserTransferLimit(ctx context.Context, arg UpsertUserTransferLimitParams) (TransferLimit, error)
 }
 
 var _ Querier = (*Queries)(nil)
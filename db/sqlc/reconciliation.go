@@ -0,0 +1,50 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// ReconciliationReport lists everything that does not add up in the ledger, OK is true when the lists are empty
+// Entries written before transfers were linked to them show up as orphaned, with their transfers as unbalanced
+type ReconciliationReport struct {
+	OK                  bool                         `json:"ok"`
+	StartedAt           time.Time                    `json:"startedAt"`
+	AccountsChecked     int64                        `json:"accountsChecked"`
+	BalanceMismatches   []ListBalanceMismatchesRow   `json:"balanceMismatches"`
+	UnbalancedTransfers []ListUnbalancedTransfersRow `json:"unbalancedTransfers"`
+	OrphanedEntries     []Entry                      `json:"orphanedEntries"`
+}
+
+// ANCHOR - Reconcile checks every account balance against the sum of its entries
+// and every transfer against its two entries, three with a fee, all within one read only snapshot
+func (store *SQLStore) Reconcile(ctx context.Context) (report ReconciliationReport, err error) {
+	report.StartedAt = time.Now().UTC()
+
+	_, err = store.execTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}, func(q *Queries) (err error) {
+		report.AccountsChecked, err = q.CountAccounts(ctx)
+		if err != nil {
+			return
+		}
+
+		report.BalanceMismatches, err = q.ListBalanceMismatches(ctx)
+		if err != nil {
+			return
+		}
+
+		report.UnbalancedTransfers, err = q.ListUnbalancedTransfers(ctx)
+		if err != nil {
+			return
+		}
+
+		report.OrphanedEntries, err = q.ListOrphanedEntries(ctx)
+		return
+	})
+	if err != nil {
+		return
+	}
+
+	report.OK = len(report.BalanceMismatches) == 0 && len(report.UnbalancedTransfers) == 0 && len(report.OrphanedEntries) == 0
+	return
+}
@@ -0,0 +1,69 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcile(t *testing.T) {
+	store := NewStore(testDB)
+
+	// accounts created with an opening balance and no entries do not reconcile
+	account := createRandomAccountWithCurrency(t, "USD")
+
+	// a transfer written without entries is unbalanced
+	other := createRandomAccountWithCurrency(t, "USD")
+	transfer := createRandomTransferForAccounts(t, account.ID, other.ID)
+
+	report, err := store.Reconcile(context.Background())
+	require.NoError(t, err)
+	require.False(t, report.OK)
+	require.NotZero(t, report.AccountsChecked)
+
+	var mismatch *ListBalanceMismatchesRow
+	for i := range report.BalanceMismatches {
+		if report.BalanceMismatches[i].ID == account.ID {
+			mismatch = &report.BalanceMismatches[i]
+		}
+	}
+	require.NotNil(t, mismatch)
+	require.Equal(t, account.Balance, mismatch.Balance)
+	require.Zero(t, mismatch.EntriesTotal)
+
+	var unbalanced *ListUnbalancedTransfersRow
+	for i := range report.UnbalancedTransfers {
+		if report.UnbalancedTransfers[i].ID == transfer.ID {
+			unbalanced = &report.UnbalancedTransfers[i]
+		}
+	}
+	require.NotNil(t, unbalanced)
+	require.Zero(t, unbalanced.EntryCount)
+}
+
+func TestReconcileTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithCurrency(t, "USD")
+	account2 := createRandomAccountWithCurrency(t, "USD")
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+		Currency:      "USD",
+	})
+	require.NoError(t, err)
+
+	report, err := store.Reconcile(context.Background())
+	require.NoError(t, err)
+
+	for _, unbalanced := range report.UnbalancedTransfers {
+		require.NotEqual(t, result.Transfer.ID, unbalanced.ID)
+	}
+	for _, entry := range report.OrphanedEntries {
+		require.NotEqual(t, result.FromEntry.ID, entry.ID)
+		require.NotEqual(t, result.ToEntry.ID, entry.ID)
+	}
+}
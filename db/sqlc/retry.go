@@ -0,0 +1,89 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Bounds of the retries of a transaction that failed on a serialization failure or deadlock
+const (
+	maxTxRetries   = 3
+	baseRetryDelay = 10 * time.Millisecond
+	maxRetryDelay  = 200 * time.Millisecond
+)
+
+// TxStats counts the transactions run by a store since it was created
+type TxStats struct {
+	Transactions int64 `json:"transactions"`
+	Retries      int64 `json:"retries"`
+	// RetriesExhausted counts transactions that still failed with a retryable error after maxTxRetries retries
+	RetriesExhausted int64 `json:"retriesExhausted"`
+}
+
+// txCounters is the concurrency safe counterpart of TxStats
+type txCounters struct {
+	transactions     atomic.Int64
+	retries          atomic.Int64
+	retriesExhausted atomic.Int64
+}
+
+func (c *txCounters) record(retries int, err error) {
+	c.transactions.Add(1)
+	c.retries.Add(int64(retries))
+	if isRetryableTxError(err) {
+		c.retriesExhausted.Add(1)
+	}
+}
+
+func (c *txCounters) snapshot() TxStats {
+	return TxStats{
+		Transactions:     c.transactions.Load(),
+		Retries:          c.retries.Load(),
+		RetriesExhausted: c.retriesExhausted.Load(),
+	}
+}
+
+// isRetryableTxError reports whether postgres aborted the transaction because of a concurrent one,
+// in which case running it again from the start can succeed
+func isRetryableTxError(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	name := pqErr.Code.Name()
+	return name == "serialization_failure" || name == "deadlock_detected"
+}
+
+// retryDelay returns a random delay up to an exponentially growing, capped bound (full jitter)
+func retryDelay(retry int) time.Duration {
+	bound := baseRetryDelay << retry
+	if bound > maxRetryDelay || bound <= 0 {
+		bound = maxRetryDelay
+	}
+	return time.Duration(rand.Int63n(int64(bound)) + 1)
+}
+
+// retryTx runs attempt until it succeeds, fails with a non retryable error, runs out of retries or ctx is done
+// It returns the number of retries made
+func retryTx(ctx context.Context, attempt func() error) (retries int, err error) {
+	for {
+		err = attempt()
+		if err == nil || !isRetryableTxError(err) || retries == maxTxRetries {
+			return
+		}
+
+		timer := time.NewTimer(retryDelay(retries))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		retries++
+	}
+}
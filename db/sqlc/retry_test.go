@@ -0,0 +1,91 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsRetryableTxError(t *testing.T) {
+	require.True(t, isRetryableTxError(&pq.Error{Code: "40001"}))
+	require.True(t, isRetryableTxError(&pq.Error{Code: "40P01"}))
+	require.True(t, isRetryableTxError(fmt.Errorf("tx err: %w, rb err: conn closed", &pq.Error{Code: "40P01"})))
+	require.False(t, isRetryableTxError(&pq.Error{Code: "23505"}))
+	require.False(t, isRetryableTxError(ErrInsufficientFunds))
+	require.False(t, isRetryableTxError(nil))
+}
+
+func TestRetryDelay(t *testing.T) {
+	for retry := 0; retry < 10; retry++ {
+		delay := retryDelay(retry)
+		require.Positive(t, delay)
+		require.LessOrEqual(t, delay, maxRetryDelay)
+	}
+}
+
+func TestRetryTx(t *testing.T) {
+	serializationFailure := &pq.Error{Code: "40001"}
+
+	testCases := []struct {
+		name        string
+		failures    int
+		err         error
+		wantRetries int
+		wantCalls   int
+		wantErr     error
+	}{
+		{name: "FirstTry", failures: 0, wantRetries: 0, wantCalls: 1},
+		{name: "RetriedOnce", failures: 1, err: serializationFailure, wantRetries: 1, wantCalls: 2},
+		{name: "Exhausted", failures: maxTxRetries + 1, err: serializationFailure, wantRetries: maxTxRetries, wantCalls: maxTxRetries + 1, wantErr: serializationFailure},
+		{name: "NotRetryable", failures: 1, err: ErrInsufficientFunds, wantRetries: 0, wantCalls: 1, wantErr: ErrInsufficientFunds},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			retries, err := retryTx(context.Background(), func() error {
+				calls++
+				if calls <= tc.failures {
+					return tc.err
+				}
+				return nil
+			})
+
+			require.Equal(t, tc.wantRetries, retries)
+			require.Equal(t, tc.wantCalls, calls)
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestRetryTxContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	calls := 0
+	retries, err := retryTx(ctx, func() error {
+		calls++
+		return &pq.Error{Code: "40P01"}
+	})
+	require.Error(t, err)
+	require.Zero(t, retries)
+	require.Equal(t, 1, calls)
+}
+
+func TestTxCounters(t *testing.T) {
+	counters := &txCounters{}
+	counters.record(0, nil)
+	counters.record(2, nil)
+	counters.record(maxTxRetries, &pq.Error{Code: "40001"})
+	counters.record(0, errors.New("boom"))
+
+	require.Equal(t, TxStats{Transactions: 4, Retries: 2 + maxTxRetries, RetriesExhausted: 1}, counters.snapshot())
+}
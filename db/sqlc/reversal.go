@@ -0,0 +1,71 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/T-BO0/bank/util/money"
+	"github.com/lib/pq"
+)
+
+// ReverseTransferTxParams contains the inputs of a transfer reversal
+type ReverseTransferTxParams struct {
+	TransferID int64  `json:"transferId"`
+	Reason     string `json:"reason"`
+}
+
+// ANCHOR - ReverseTransferTx compensates a transfer with an opposite one linked to it through reversal_of
+// The original transfer and its entries are never changed, the reversal debits ToAmount from the original to account
+// and credits Amount back to the original from account, so a cross-currency transfer is undone at its own rate
+// The fee of the original transfer is kept, the reversal itself is free
+// It fails with ErrTransferNotFound, ErrTransferAlreadyReversed, ErrTransferIsReversal or ErrInsufficientFunds
+func (store *SQLStore) ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	retries, err := store.execTx(ctx, nil, func(q *Queries) error {
+		// locking the original serializes concurrent reversals of the same transfer
+		original, err := q.GetTransferForUpdate(ctx, arg.TransferID)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w: %d", ErrTransferNotFound, arg.TransferID)
+		}
+		if err != nil {
+			return err
+		}
+
+		if original.ReversalOf.Valid {
+			return fmt.Errorf("%w: %d", ErrTransferIsReversal, original.ID)
+		}
+
+		_, err = q.GetTransferReversal(ctx, sql.NullInt64{Int64: original.ID, Valid: true})
+		if err == nil {
+			return fmt.Errorf("%w: %d", ErrTransferAlreadyReversed, original.ID)
+		}
+		if err != sql.ErrNoRows {
+			return err
+		}
+
+		fxRate, err := money.InvertRate(original.FxRate)
+		if err != nil {
+			return err
+		}
+
+		result, err = transfer(ctx, q, CreateTransferParams{
+			FromAccountID:  original.ToAccountID,
+			ToAccountID:    original.FromAccountID,
+			Amount:         original.ToAmount,
+			Currency:       original.ToCurrency,
+			ToAmount:       original.Amount,
+			ToCurrency:     original.Currency,
+			FxRate:         fxRate,
+			ReversalOf:     sql.NullInt64{Int64: original.ID, Valid: true},
+			ReversalReason: sql.NullString{String: arg.Reason, Valid: true},
+		})
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+			return fmt.Errorf("%w: %d", ErrTransferAlreadyReversed, original.ID)
+		}
+		return err
+	})
+	result.Retries = retries
+	return result, err
+}
@@ -0,0 +1,99 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReverseTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithCurrency(t, "USD")
+	account2 := createRandomAccountWithCurrency(t, "USD")
+
+	original, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		Currency:      "USD",
+	})
+	require.NoError(t, err)
+
+	result, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Reason:     "sent by mistake",
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, account2.ID, result.Transfer.FromAccountID)
+	require.Equal(t, account1.ID, result.Transfer.ToAccountID)
+	require.Equal(t, int64(10), result.Transfer.Amount)
+	require.True(t, result.Transfer.ReversalOf.Valid)
+	require.Equal(t, original.Transfer.ID, result.Transfer.ReversalOf.Int64)
+	require.Equal(t, "sent by mistake", result.Transfer.ReversalReason.String)
+	require.Equal(t, int64(-10), result.FromEntry.Amount)
+	require.Equal(t, int64(10), result.ToEntry.Amount)
+
+	// both balances are back where they started
+	require.Equal(t, account1.Balance, result.ToAccount.Balance)
+	require.Equal(t, account2.Balance, result.FromAccount.Balance)
+
+	// the original transfer is kept untouched
+	stored, err := testQueries.GetTransfer(context.Background(), original.Transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, original.Transfer.Amount, stored.Amount)
+	require.False(t, stored.ReversalOf.Valid)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Reason:     "again",
+	})
+	require.ErrorIs(t, err, ErrTransferAlreadyReversed)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: result.Transfer.ID,
+		Reason:     "undo the undo",
+	})
+	require.ErrorIs(t, err, ErrTransferIsReversal)
+}
+
+func TestReverseTransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithCurrency(t, "USD")
+	account2 := createRandomAccountWithCurrency(t, "USD")
+
+	original, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		Currency:      "USD",
+	})
+	require.NoError(t, err)
+
+	// the receiver spends everything before the reversal
+	_, err = store.WithdrawTx(context.Background(), CashTxParams{
+		AccountID: account2.ID,
+		Amount:    original.ToAccount.Balance,
+		Currency:  "USD",
+	})
+	require.NoError(t, err)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Reason:     "refund",
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func TestReverseTransferTxNotFound(t *testing.T) {
+	store := NewStore(testDB)
+
+	_, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: -1,
+		Reason:     "refund",
+	})
+	require.ErrorIs(t, err, ErrTransferNotFound)
+}
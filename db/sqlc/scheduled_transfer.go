@@ -0,0 +1,116 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/T-BO0/bank/util/schedule"
+)
+
+// Statuses of a scheduled transfer run
+const (
+	ScheduledRunSucceeded = "succeeded"
+	ScheduledRunFailed    = "failed"
+)
+
+// ScheduledTransferTxResult is the result of running one due scheduled transfer
+// Transfer is only set when the run succeeded
+type ScheduledTransferTxResult struct {
+	ScheduledTransfer ScheduledTransfer    `json:"scheduledTransfer"`
+	Run               ScheduledTransferRun `json:"run"`
+	Transfer          *TransferTxResult    `json:"transfer,omitempty"`
+	// Retries is the number of times the transaction was run again after a serialization failure or deadlock
+	Retries int `json:"-"`
+}
+
+// ANCHOR - RunScheduledTransferTx claims the scheduled transfer that is due the longest at now and runs it
+// The claim skips rows other workers hold, and the transfer, its outcome and the advanced next run commit together,
+// so a run is never executed twice. A transfer rejected for funds, currency or a missing account is recorded as failed
+// and the schedule still advances. It fails with ErrNoScheduledTransferDue when there is nothing to run
+func (store *SQLStore) RunScheduledTransferTx(ctx context.Context, now time.Time) (ScheduledTransferTxResult, error) {
+	var result ScheduledTransferTxResult
+
+	retries, err := store.execTx(ctx, nil, func(q *Queries) error {
+		result = ScheduledTransferTxResult{}
+
+		scheduled, err := q.ClaimDueScheduledTransfer(ctx, now)
+		if err == sql.ErrNoRows {
+			return ErrNoScheduledTransferDue
+		}
+		if err != nil {
+			return err
+		}
+
+		run := CreateScheduledTransferRunParams{
+			ScheduledTransferID: scheduled.ID,
+			RunAt:               scheduled.NextRunAt,
+			Status:              ScheduledRunSucceeded,
+		}
+
+		transferResult, err := chargedTransfer(ctx, q, CreateTransferParams{
+			FromAccountID: scheduled.FromAccountID,
+			ToAccountID:   scheduled.ToAccountID,
+			Amount:        scheduled.Amount,
+			Currency:      scheduled.Currency,
+			ToAmount:      scheduled.Amount,
+			ToCurrency:    scheduled.Currency,
+			FxRate:        "1",
+		})
+		switch {
+		case err == nil:
+			result.Transfer = &transferResult
+			run.TransferID = sql.NullInt64{Int64: transferResult.Transfer.ID, Valid: true}
+		case isTransferRejection(err):
+			run.Status = ScheduledRunFailed
+			run.Error = sql.NullString{String: err.Error(), Valid: true}
+		default:
+			return err
+		}
+
+		result.Run, err = q.CreateScheduledTransferRun(ctx, run)
+		if err != nil {
+			return err
+		}
+
+		nextRunAt, active := nextScheduledRun(scheduled, now)
+		result.ScheduledTransfer, err = q.UpdateScheduledTransfer(ctx, UpdateScheduledTransferParams{
+			ID:        scheduled.ID,
+			NextRunAt: sql.NullTime{Time: nextRunAt, Valid: true},
+			Active:    sql.NullBool{Bool: active, Valid: true},
+		})
+		return err
+	})
+	result.Retries = retries
+	return result, err
+}
+
+// isTransferRejection reports whether transfer refused to move the money before writing anything
+func isTransferRejection(err error) bool {
+	return errors.Is(err, ErrInsufficientFunds) ||
+		errors.Is(err, ErrCurrencyMismatch) ||
+		errors.Is(err, ErrAccountNotFound) ||
+		errors.Is(err, ErrAccountNotActive) ||
+		errors.Is(err, ErrWithdrawalLimitReached) ||
+		errors.Is(err, ErrLimitExceeded)
+}
+
+// nextScheduledRun returns the first run of the rule after now, skipping the ones missed while no worker ran,
+// and false once the rule has no more runs before the end of the scheduled transfer
+func nextScheduledRun(scheduled ScheduledTransfer, now time.Time) (time.Time, bool) {
+	rule, err := schedule.Parse(scheduled.Rule)
+	if err != nil {
+		return scheduled.NextRunAt, false
+	}
+
+	next := rule.Next(scheduled.NextRunAt)
+	for !next.IsZero() && !next.After(now) {
+		next = rule.Next(next)
+	}
+
+	if next.IsZero() || (scheduled.EndAt.Valid && next.After(scheduled.EndAt.Time)) {
+		return scheduled.NextRunAt, false
+	}
+	return next, true
+}
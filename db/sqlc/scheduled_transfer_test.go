@@ -0,0 +1,128 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomScheduledTransfer(t *testing.T, from Account, to Account, amount int64, nextRunAt time.Time) ScheduledTransfer {
+	arg := CreateScheduledTransferParams{
+		Owner:         from.Owner,
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        amount,
+		Currency:      from.Currency,
+		Rule:          "@every 24h",
+		NextRunAt:     nextRunAt,
+	}
+
+	scheduled, err := testQueries.CreateScheduledTransfer(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, scheduled.ID)
+	require.Equal(t, arg.Owner, scheduled.Owner)
+	require.Equal(t, arg.Amount, scheduled.Amount)
+	require.Equal(t, arg.Rule, scheduled.Rule)
+	require.WithinDuration(t, arg.NextRunAt, scheduled.NextRunAt, time.Second)
+	require.True(t, scheduled.Active)
+
+	return scheduled
+}
+
+func TestRunScheduledTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithCurrency(t, "USD")
+	account2 := createRandomAccountWithCurrency(t, "USD")
+
+	// far in the past, so it is due before anything other tests left behind
+	due := time.Date(2000, time.January, 1, 9, 0, 0, 0, time.UTC)
+	scheduled := createRandomScheduledTransfer(t, account1, account2, 1, due)
+	now := due.Add(36 * time.Hour)
+
+	result, err := store.RunScheduledTransferTx(context.Background(), now)
+	require.NoError(t, err)
+
+	require.Equal(t, scheduled.ID, result.ScheduledTransfer.ID)
+	require.Equal(t, ScheduledRunSucceeded, result.Run.Status)
+	require.NotNil(t, result.Transfer)
+	require.Equal(t, result.Transfer.Transfer.ID, result.Run.TransferID.Int64)
+	require.Equal(t, account1.Balance-1, result.Transfer.FromAccount.Balance)
+
+	// the run missed while nothing ran is skipped
+	require.True(t, result.ScheduledTransfer.NextRunAt.Equal(due.Add(48*time.Hour)))
+	require.True(t, result.ScheduledTransfer.Active)
+
+	runs, err := testQueries.ListScheduledTransferRuns(context.Background(), ListScheduledTransferRunsParams{
+		ScheduledTransferID: scheduled.ID,
+		Limit:               5,
+	})
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+}
+
+func TestRunScheduledTransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithCurrency(t, "USD")
+	account2 := createRandomAccountWithCurrency(t, "USD")
+
+	due := time.Date(2000, time.January, 1, 8, 0, 0, 0, time.UTC)
+	scheduled := createRandomScheduledTransfer(t, account1, account2, account1.Balance+1, due)
+	scheduled, err := testQueries.UpdateScheduledTransfer(context.Background(), UpdateScheduledTransferParams{
+		ID:    scheduled.ID,
+		Rule:  sql.NullString{String: "@once", Valid: true},
+		EndAt: sql.NullTime{Time: due.Add(time.Hour), Valid: true},
+	})
+	require.NoError(t, err)
+
+	result, err := store.RunScheduledTransferTx(context.Background(), due)
+	require.NoError(t, err)
+
+	require.Equal(t, scheduled.ID, result.ScheduledTransfer.ID)
+	require.Equal(t, ScheduledRunFailed, result.Run.Status)
+	require.Contains(t, result.Run.Error.String, ErrInsufficientFunds.Error())
+	require.Nil(t, result.Transfer)
+	require.False(t, result.ScheduledTransfer.Active)
+
+	account, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, account.Balance)
+}
+
+func TestRunScheduledTransferTxNothingDue(t *testing.T) {
+	store := NewStore(testDB)
+
+	_, err := store.RunScheduledTransferTx(context.Background(), time.Date(1990, time.January, 1, 0, 0, 0, 0, time.UTC))
+	require.ErrorIs(t, err, ErrNoScheduledTransferDue)
+}
+
+func TestNextScheduledRun(t *testing.T) {
+	due := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name       string
+		rule       string
+		endAt      sql.NullTime
+		now        time.Time
+		wantNext   time.Time
+		wantActive bool
+	}{
+		{name: "OnTime", rule: "0 9 1 * *", now: due, wantNext: time.Date(2024, time.April, 1, 9, 0, 0, 0, time.UTC), wantActive: true},
+		{name: "SkipsMissed", rule: "0 9 1 * *", now: due.AddDate(0, 2, 0), wantNext: time.Date(2024, time.June, 1, 9, 0, 0, 0, time.UTC), wantActive: true},
+		{name: "Once", rule: "@once", now: due, wantNext: due, wantActive: false},
+		{name: "Ended", rule: "0 9 1 * *", endAt: sql.NullTime{Time: due.AddDate(0, 0, 10), Valid: true}, now: due, wantNext: due, wantActive: false},
+		{name: "InvalidRule", rule: "sometimes", now: due, wantNext: due, wantActive: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			next, active := nextScheduledRun(ScheduledTransfer{Rule: tc.rule, NextRunAt: due, EndAt: tc.endAt}, tc.now)
+			require.Equal(t, tc.wantNext, next)
+			require.Equal(t, tc.wantActive, active)
+		})
+	}
+}
@@ -3,31 +3,70 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"sort"
+	"time"
+
+	"github.com/T-BO0/bank/util/money"
 )
 
 // Store provides all functions to execute db queries and transactions
 type Store interface {
 	Querier
 	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
+	FXTransferTx(ctx context.Context, arg FXTransferTxParams) (TransferTxResult, error)
+	DepositTx(ctx context.Context, arg CashTxParams) (CashTxResult, error)
+	WithdrawTx(ctx context.Context, arg CashTxParams) (CashTxResult, error)
+	ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (TransferTxResult, error)
+	RunScheduledTransferTx(ctx context.Context, now time.Time) (ScheduledTransferTxResult, error)
+	TxStats() TxStats
+	VerifyLedger(ctx context.Context, accountID int64) (LedgerVerification, error)
+	Reconcile(ctx context.Context) (ReconciliationReport, error)
+	AuthorizeTx(ctx context.Context, arg AuthorizeTxParams) (HoldTxResult, error)
+	CaptureTx(ctx context.Context, arg CaptureTxParams) (HoldTxResult, error)
+	VoidTx(ctx context.Context, holdID int64) (HoldTxResult, error)
+	ExpireHoldsTx(ctx context.Context, now time.Time, limit int32) ([]Hold, error)
+	UpdateAccountStatusTx(ctx context.Context, arg UpdateAccountStatusTxParams) (Account, error)
+	AccrueInterest(ctx context.Context, day time.Time) (InterestRunResult, error)
+	PostInterest(ctx context.Context, month time.Time) (InterestRunResult, error)
+	BatchTransferTx(ctx context.Context, arg BatchTransferTxParams) (BatchTransferTxResult, error)
 }
 
 // SQLStore provides all functions to execute db queries and transactions
 type SQLStore struct {
 	*Queries
-	db *sql.DB
+	db    *sql.DB
+	stats *txCounters
 }
 
 func NewStore(db *sql.DB) Store {
 	return &SQLStore{
 		Queries: New(db),
 		db:      db,
+		stats:   &txCounters{},
 	}
 }
 
-// execTx executes a function within a database transaction
-func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
-	tx, err := store.db.BeginTx(ctx, nil)
+// TxStats returns the transaction and retry counts of the store
+func (store *SQLStore) TxStats() TxStats {
+	return store.stats.snapshot()
+}
+
+// execTx executes a function within a database transaction started with opts, nil opts uses the defaults
+// Serialization failures and deadlocks roll back and run fn again, so fn must not keep state between calls
+// It returns the number of retries it took
+func (store *SQLStore) execTx(ctx context.Context, opts *sql.TxOptions, fn func(*Queries) error) (int, error) {
+	retries, err := retryTx(ctx, func() error {
+		return store.runTx(ctx, opts, fn)
+	})
+	store.stats.record(retries, err)
+	return retries, err
+}
+
+// runTx executes a function within a single database transaction
+func (store *SQLStore) runTx(ctx context.Context, opts *sql.TxOptions, fn func(*Queries) error) error {
+	tx, err := store.db.BeginTx(ctx, opts)
 	if err != nil {
 		return err
 	}
@@ -36,7 +75,7 @@ func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) erro
 	err = fn(q)
 	if err != nil {
 		if rbErr := tx.Rollback(); rbErr != nil {
-			return fmt.Errorf("tx err: %v, rb err: %v", err, rbErr)
+			return fmt.Errorf("tx err: %w, rb err: %v", err, rbErr)
 		}
 		return err
 	}
@@ -44,20 +83,62 @@ func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) erro
 }
 
 // TransferTxParams contains all the inputs parameters of the transfer transaction
+// Amount is in minor units of Currency
+// Description, Reference and Metadata are optional and stored on the transfer as they are
+// A non empty IdempotencyKey, scoped per Username, makes a retry return the result of the first call
 type TransferTxParams struct {
-	FromAccountID int64   `json:"fromAccountId"`
-	ToAccountID   int64   `json:"toAccountId"`
-	Amount        float64 `json:"amount"`
+	FromAccountID  int64             `json:"fromAccountId"`
+	ToAccountID    int64             `json:"toAccountId"`
+	Amount         int64             `json:"amount"`
+	Currency       string            `json:"currency"`
+	Description    string            `json:"description,omitempty"`
+	Reference      string            `json:"reference,omitempty"`
+	Metadata       map[string]string `json:"metadata,omitempty"`
+	IdempotencyKey string            `json:"-"`
+	Username       string            `json:"-"`
 }
 
-func (ttx *TransferTxParams) convertToCreateTransferParams() CreateTransferParams {
+func (ttx *TransferTxParams) convertToCreateTransferParams(toAmount int64, toCurrency string, fxRate string) CreateTransferParams {
 	return CreateTransferParams{
 		FromAccountID: ttx.FromAccountID,
 		ToAccountID:   ttx.ToAccountID,
 		Amount:        ttx.Amount,
+		Currency:      ttx.Currency,
+		ToAmount:      toAmount,
+		ToCurrency:    toCurrency,
+		FxRate:        fxRate,
+		Description:   nullString(ttx.Description),
+		Reference:     nullString(ttx.Reference),
+		Metadata:      ttx.metadataJSON(),
 	}
 }
 
+// metadataJSON returns Metadata as a json object, an empty one without metadata
+func (ttx *TransferTxParams) metadataJSON() json.RawMessage {
+	if len(ttx.Metadata) == 0 {
+		return emptyMetadata
+	}
+	// a map of strings always marshals, with its keys sorted
+	metadata, _ := json.Marshal(ttx.Metadata)
+	return metadata
+}
+
+// emptyMetadata is the metadata of a transfer without any
+var emptyMetadata = json.RawMessage("{}")
+
+// nullString maps an empty string to NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// FXTransferTxParams contains the inputs of a transfer between accounts of different currencies
+// Rate is a decimal string of ToCurrency units per one unit of Currency
+type FXTransferTxParams struct {
+	TransferTxParams
+	ToCurrency string `json:"toCurrency"`
+	Rate       string `json:"rate"`
+}
+
 // TransferTxResult is the result of the transfer transaction
 type TransferTxResult struct {
 	Transfer    Transfer `json:"transfer"`
@@ -65,54 +146,193 @@ type TransferTxResult struct {
 	ToAccount   Account  `json:"toAccount"`
 	FromEntry   Entry    `json:"fromEntry"`
 	ToEntry     Entry    `json:"toEntry"`
+	// FeeEntry is the credit of the fee to the revenue account, only set when the transfer has a fee
+	FeeEntry *Entry `json:"feeEntry,omitempty"`
+	// Replayed is true when the result was loaded from a previously used idempotency key
+	Replayed bool `json:"-"`
+	// Retries is the number of times the transaction was run again after a serialization failure or deadlock
+	Retries int `json:"-"`
 }
 
 // ANCHOR - TransferTx performs a money transfer from one account to the other
 // It creates a transfer record, add account entries, and update accounts' balance within a single database transaction
+// The fee of the from account type and currency, see feeSchedule, is debited with the amount and credited to the revenue account
+// It fails with ErrAccountNotFound, ErrAccountNotActive, ErrCurrencyMismatch, ErrInsufficientFunds,
+// ErrWithdrawalLimitReached or a *LimitExceededError before anything is written
 func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
-	var result TransferTxResult
+	return store.transferTx(ctx, arg, arg.Amount, arg.Currency, "1")
+}
 
-	err := store.execTx(ctx, func(q *Queries) error {
-		var err error
+// ANCHOR - FXTransferTx performs a money transfer between accounts of different currencies
+// It debits Amount of Currency, credits the amount converted at Rate in ToCurrency and records both on the transfer
+func (store *SQLStore) FXTransferTx(ctx context.Context, arg FXTransferTxParams) (TransferTxResult, error) {
+	toAmount, err := money.Convert(arg.Amount, arg.Currency, arg.ToCurrency, arg.Rate)
+	if err != nil {
+		return TransferTxResult{}, err
+	}
+	if toAmount <= 0 {
+		return TransferTxResult{}, fmt.Errorf("%w: %d %s converts to nothing at rate %s", money.ErrInvalidAmount, arg.Amount, arg.Currency, arg.Rate)
+	}
 
-		result.Transfer, err = q.CreateTransfer(ctx, arg.convertToCreateTransferParams())
-		if err != nil {
-			return err
-		}
+	return store.transferTx(ctx, arg.TransferTxParams, toAmount, arg.ToCurrency, arg.Rate)
+}
 
-		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountID,
-			Amount:    -arg.Amount,
-		})
-		if err != nil {
-			return err
+// transferTx debits arg.Amount from the from account and credits toAmount of toCurrency to the to account
+func (store *SQLStore) transferTx(ctx context.Context, arg TransferTxParams, toAmount int64, toCurrency string, fxRate string) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	retries, err := store.execTx(ctx, nil, func(q *Queries) error {
+		if arg.IdempotencyKey != "" {
+			replayed, err := replayTransfer(ctx, q, arg, &result)
+			if err != nil || replayed {
+				return err
+			}
 		}
 
-		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.ToAccountID,
-			Amount:    arg.Amount,
-		})
+		var err error
+		result, err = chargedTransfer(ctx, q, arg.convertToCreateTransferParams(toAmount, toCurrency, fxRate))
 		if err != nil {
 			return err
 		}
 
-		if arg.FromAccountID < arg.ToAccountID {
-			result.FromAccount, result.ToAccount, err = addMoney(ctx, q, arg.FromAccountID, -arg.Amount, arg.ToAccountID, arg.Amount)
-		} else {
-			result.ToAccount, result.FromAccount, err = addMoney(ctx, q, arg.ToAccountID, arg.Amount, arg.FromAccountID, -arg.Amount)
+		if arg.IdempotencyKey != "" {
+			return saveTransferResult(ctx, q, arg, result)
 		}
-		return err
+		return nil
 	})
+	result.Retries = retries
 	return result, err
 }
 
+// transfer moves the money of a single transfer within the transaction q belongs to
+// It debits arg.Amount from the from account and credits arg.ToAmount to the to account
+// A non zero arg.Fee is debited from the from account on top of arg.Amount and credited to arg.FeeAccountID,
+// which is locked together with the from and the to account
+// Both entries are appended to the hash chain of their account while the accounts are locked
+// The debit has to follow the rules of the from account type, see checkDebit, so money reserved by
+// authorized holds can't be spent twice. Both accounts have to be active, a frozen or closed account
+// neither sends nor receives. The amount of anything but a reversal counts against the transfer limits, see checkLimits
+func transfer(ctx context.Context, q *Queries, arg CreateTransferParams) (result TransferTxResult, err error) {
+	ids := []int64{arg.FromAccountID, arg.ToAccountID}
+	if arg.Fee != 0 {
+		ids = append(ids, arg.FeeAccountID.Int64)
+	}
+	accounts, err := lockAccounts(ctx, q, ids...)
+	if err != nil {
+		return
+	}
+	fromAccount, toAccount := accounts[arg.FromAccountID], accounts[arg.ToAccountID]
+
+	if err = checkAccountsActive(fromAccount, toAccount); err != nil {
+		return
+	}
+
+	if fromAccount.Currency != arg.Currency || toAccount.Currency != arg.ToCurrency {
+		err = ErrCurrencyMismatch
+		return
+	}
+
+	debit := arg.Amount + arg.Fee
+	if err = checkDebit(ctx, q, fromAccount, debit, !arg.ReversalOf.Valid); err != nil {
+		return
+	}
+
+	if !arg.ReversalOf.Valid {
+		if err = checkLimits(ctx, q, fromAccount, arg.Amount); err != nil {
+			return
+		}
+	}
+
+	return bookTransfer(ctx, q, arg)
+}
+
+// bookTransfer creates the transfer with its entries and moves its money between accounts the caller
+// already locked and checked, the debit of arg.Amount and arg.Fee is not checked again
+func bookTransfer(ctx context.Context, q *Queries, arg CreateTransferParams) (result TransferTxResult, err error) {
+	if len(arg.Metadata) == 0 {
+		arg.Metadata = emptyMetadata
+	}
+
+	debit := arg.Amount + arg.Fee
+	result.Transfer, err = q.CreateTransfer(ctx, arg)
+	if err != nil {
+		return
+	}
+
+	result.FromEntry, err = createLedgerEntry(ctx, q, arg.FromAccountID, -debit, result.Transfer)
+	if err != nil {
+		return
+	}
+
+	result.ToEntry, err = createLedgerEntry(ctx, q, arg.ToAccountID, arg.ToAmount, result.Transfer)
+	if err != nil {
+		return
+	}
+
+	if arg.FromAccountID < arg.ToAccountID {
+		result.FromAccount, result.ToAccount, err = addMoney(ctx, q, arg.FromAccountID, -debit, arg.ToAccountID, arg.ToAmount)
+	} else {
+		result.ToAccount, result.FromAccount, err = addMoney(ctx, q, arg.ToAccountID, arg.ToAmount, arg.FromAccountID, -debit)
+	}
+	if err != nil || arg.Fee == 0 {
+		return
+	}
+
+	result.FeeEntry, err = creditFee(ctx, q, arg.FeeAccountID.Int64, arg.Fee, result.Transfer)
+	return
+}
+
+// creditFee books the fee of transfer as the third entry, on the revenue account transfer locked with the others
+func creditFee(ctx context.Context, q *Queries, feeAccountID int64, fee int64, transfer Transfer) (*Entry, error) {
+	entry, err := createLedgerEntry(ctx, q, feeAccountID, fee, transfer)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+		ID:     feeAccountID,
+		Amount: fee,
+	})
+	return &entry, err
+}
+
+// lockAccounts locks every account of ids for update once, in ascending ID order, and returns them by ID
+// Every transaction locks accounts in this order, the order addMoney updates them in too,
+// so transactions touching the same accounts wait on each other instead of deadlocking
+func lockAccounts(ctx context.Context, q *Queries, ids ...int64) (map[int64]Account, error) {
+	sorted := append([]int64(nil), ids...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	accounts := make(map[int64]Account, len(sorted))
+	for _, id := range sorted {
+		if _, locked := accounts[id]; locked {
+			continue
+		}
+		account, err := lockAccount(ctx, q, id)
+		if err != nil {
+			return nil, err
+		}
+		accounts[id] = account
+	}
+	return accounts, nil
+}
+
+// lockAccount locks the account row and maps a missing row to ErrAccountNotFound
+func lockAccount(ctx context.Context, q *Queries, accountID int64) (Account, error) {
+	account, err := q.GetAccountForUpdate(ctx, accountID)
+	if err == sql.ErrNoRows {
+		return account, fmt.Errorf("%w: %d", ErrAccountNotFound, accountID)
+	}
+	return account, err
+}
+
 func addMoney(
 	ctx context.Context,
 	q *Queries,
 	accountID1 int64,
-	amount1 float64,
+	amount1 int64,
 	accountID2 int64,
-	amount2 float64,
+	amount2 int64,
 ) (account1 Account, account2 Account, err error) {
 	account1, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
 		ID:     accountID1,
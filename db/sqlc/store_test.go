@@ -2,23 +2,25 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"testing"
 
+	"github.com/T-BO0/bank/util"
 	"github.com/stretchr/testify/require"
 )
 
 func TestTransferTx(t *testing.T) {
 	store := NewStore(testDB)
 
-	account1 := createRandomAccount(t)
-	account2 := createRandomAccount(t)
+	account1 := createRandomAccountWithCurrency(t, "USD")
+	account2 := createRandomAccountWithCurrency(t, "USD")
 
 	fmt.Println(">> before:", account1.Balance, account2.Balance)
 
 	// run n concurrent transfer transaction
 	n := 5
-	amount := float64(3)
+	amount := int64(3)
 
 	c_err := make(chan error)
 	c_result := make(chan TransferTxResult)
@@ -29,6 +31,7 @@ func TestTransferTx(t *testing.T) {
 				FromAccountID: account1.ID,
 				ToAccountID:   account2.ID,
 				Amount:        amount,
+				Currency:      account1.Currency,
 			})
 
 			c_err <- err
@@ -104,20 +107,20 @@ func TestTransferTx(t *testing.T) {
 	require.NoError(t, err)
 
 	fmt.Println(">> after:", updatedAccount1.Balance, updatedAccount2.Balance)
-	require.Equal(t, account1.Balance-float64(n)*amount, updatedAccount1.Balance)
-	require.Equal(t, account2.Balance+float64(n)*amount, updatedAccount2.Balance)
+	require.Equal(t, account1.Balance-int64(n)*amount, updatedAccount1.Balance)
+	require.Equal(t, account2.Balance+int64(n)*amount, updatedAccount2.Balance)
 }
 
 func TestTransferTxDeadLock(t *testing.T) {
 	store := NewStore(testDB)
 
-	account1 := createRandomAccount(t)
-	account2 := createRandomAccount(t)
+	account1 := createRandomAccountWithCurrency(t, "USD")
+	account2 := createRandomAccountWithCurrency(t, "USD")
 
 	fmt.Println(">> before:", account1.Balance, account2.Balance)
 
 	n := 10
-	amount := float64(3)
+	amount := int64(3)
 
 	c_err := make(chan error)
 
@@ -136,6 +139,7 @@ func TestTransferTxDeadLock(t *testing.T) {
 				FromAccountID: fromAccountID,
 				ToAccountID:   toAccountID,
 				Amount:        amount,
+				Currency:      account1.Currency,
 			})
 
 			c_err <- err
@@ -158,4 +162,133 @@ func TestTransferTxDeadLock(t *testing.T) {
 	fmt.Println(">> after:", updatedAccount1.Balance, updatedAccount2.Balance)
 	require.Equal(t, account1.Balance, updatedAccount1.Balance)
 	require.Equal(t, account2.Balance, updatedAccount2.Balance)
+
+	stats := store.TxStats()
+	require.Equal(t, int64(n), stats.Transactions)
+	require.Zero(t, stats.RetriesExhausted)
+}
+
+func TestTransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithCurrency(t, "USD")
+	account2 := createRandomAccountWithCurrency(t, "USD")
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance + 1,
+		Currency:      "USD",
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// nothing should be written
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+
+	_, err = testQueries.GetTransferByFromAccountId(context.Background(), account1.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestTransferTxCurrencyMismatch(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithCurrency(t, "USD")
+	account2 := createRandomAccountWithCurrency(t, "EUR")
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+		Currency:      "USD",
+	})
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+}
+
+func TestTransferTxAccountNotFound(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithCurrency(t, "USD")
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account1.ID + 1_000_000,
+		Amount:        1,
+		Currency:      "USD",
+	})
+	require.ErrorIs(t, err, ErrAccountNotFound)
+}
+
+func TestTransferTxIdempotency(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithCurrency(t, "USD")
+	account2 := createRandomAccountWithCurrency(t, "USD")
+
+	arg := TransferTxParams{
+		FromAccountID:  account1.ID,
+		ToAccountID:    account2.ID,
+		Amount:         10,
+		Currency:       "USD",
+		IdempotencyKey: util.RandomString(16),
+		Username:       account1.Owner,
+	}
+
+	result1, err := store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.False(t, result1.Replayed)
+
+	// a retry returns the first result without moving money again
+	result2, err := store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, result2.Replayed)
+	require.Equal(t, result1.Transfer.ID, result2.Transfer.ID)
+	require.Equal(t, result1.FromAccount.Balance, result2.FromAccount.Balance)
+
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-arg.Amount, updatedAccount1.Balance)
+
+	// the same key with another transfer is rejected
+	arg.Amount = 20
+	_, err = store.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrIdempotencyKeyReused)
+}
+
+func TestFXTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithCurrency(t, "USD")
+	account2 := createRandomAccountWithCurrency(t, "GEL")
+
+	arg := FXTransferTxParams{
+		TransferTxParams: TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        1000,
+			Currency:      "USD",
+		},
+		ToCurrency: "GEL",
+		Rate:       "2.7",
+	}
+
+	result, err := store.FXTransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Equal(t, int64(1000), result.Transfer.Amount)
+	require.Equal(t, "USD", result.Transfer.Currency)
+	require.Equal(t, int64(2700), result.Transfer.ToAmount)
+	require.Equal(t, "GEL", result.Transfer.ToCurrency)
+	require.Equal(t, "2.7000000000", result.Transfer.FxRate)
+
+	require.Equal(t, int64(-1000), result.FromEntry.Amount)
+	require.Equal(t, int64(2700), result.ToEntry.Amount)
+	require.Equal(t, account1.Balance-1000, result.FromAccount.Balance)
+	require.Equal(t, account2.Balance+2700, result.ToAccount.Balance)
+
+	// the to account currency has to match ToCurrency
+	arg.ToCurrency = "EUR"
+	_, err = store.FXTransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
 }
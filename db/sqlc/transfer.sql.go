@@ -7,27 +7,90 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
 )
 
+const countMonthlyWithdrawals = `-- name: CountMonthlyWithdrawals :one
+SELECT COUNT(*) FROM (
+  SELECT transfers.id FROM transfers
+  WHERE transfers.from_account_id = $1
+    AND transfers.reversal_of IS NULL
+    AND transfers.created_at >= date_trunc('month', now() AT TIME ZONE 'UTC' + INTERVAL '4 hours')
+    AND NOT EXISTS (SELECT 1 FROM holds WHERE holds.transfer_id = transfers.id)
+  UNION ALL
+  SELECT holds.id FROM holds
+  WHERE holds.from_account_id = $1
+    AND holds.status IN ('authorized', 'captured')
+    AND holds.created_at >= date_trunc('month', now() AT TIME ZONE 'UTC' + INTERVAL '4 hours')
+) AS withdrawals
+`
+
+// a hold counts from its authorization, the transfer its capture booked is not counted again
+func (q *Queries) CountMonthlyWithdrawals(ctx context.Context, fromAccountID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countMonthlyWithdrawals, fromAccountID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createTransfer = `-- name: CreateTransfer :one
 INSERT INTO transfers (
   from_account_id,
   to_account_id,
-  amount
+  amount,
+  currency,
+  to_amount,
+  to_currency,
+  fx_rate,
+  reversal_of,
+  reversal_reason,
+  fee,
+  fee_account_id,
+  description,
+  reference,
+  metadata
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
 )
-RETURNING id, from_account_id, to_account_id, amount, created_at
+RETURNING id, from_account_id, to_account_id, amount, created_at, currency, to_amount, to_currency, fx_rate, reversal_of, reversal_reason, fee, fee_account_id, description, reference, metadata
 `
 
 type CreateTransferParams struct {
-	FromAccountID int64   `json:"from_account_id"`
-	ToAccountID   int64   `json:"to_account_id"`
-	Amount        float64 `json:"amount"`
+	FromAccountID  int64           `json:"from_account_id"`
+	ToAccountID    int64           `json:"to_account_id"`
+	Amount         int64           `json:"amount"`
+	Currency       string          `json:"currency"`
+	ToAmount       int64           `json:"to_amount"`
+	ToCurrency     string          `json:"to_currency"`
+	FxRate         string          `json:"fx_rate"`
+	ReversalOf     sql.NullInt64   `json:"reversal_of"`
+	ReversalReason sql.NullString  `json:"reversal_reason"`
+	Fee            int64           `json:"fee"`
+	FeeAccountID   sql.NullInt64   `json:"fee_account_id"`
+	Description    sql.NullString  `json:"description"`
+	Reference      sql.NullString  `json:"reference"`
+	Metadata       json.RawMessage `json:"metadata"`
 }
 
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.db.QueryRowContext(ctx, createTransfer, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.Currency,
+		arg.ToAmount,
+		arg.ToCurrency,
+		arg.FxRate,
+		arg.ReversalOf,
+		arg.ReversalReason,
+		arg.Fee,
+		arg.FeeAccountID,
+		arg.Description,
+		arg.Reference,
+		arg.Metadata,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -35,23 +98,115 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.Currency,
+		&i.ToAmount,
+		&i.ToCurrency,
+		&i.FxRate,
+		&i.ReversalOf,
+		&i.ReversalReason,
+		&i.Fee,
+		&i.FeeAccountID,
+		&i.Description,
+		&i.Reference,
+		&i.Metadata,
 	)
 	return i, err
 }
 
-const deleteTransfer = `-- name: DeleteTransfer :exec
-DELETE 
-FROM transfers
-WHERE id = $1
+const getAccountTransferUsage = `-- name: GetAccountTransferUsage :one
+WITH outgoing AS (
+  SELECT transfers.amount, transfers.created_at FROM transfers
+  WHERE transfers.from_account_id = $1
+    AND transfers.reversal_of IS NULL
+    AND transfers.created_at >= date_trunc('month', now() AT TIME ZONE 'UTC' + INTERVAL '4 hours')
+    AND NOT EXISTS (SELECT 1 FROM holds WHERE holds.transfer_id = transfers.id)
+  UNION ALL
+  SELECT CASE WHEN holds.status = 'captured' THEN holds.captured_amount ELSE holds.amount END, holds.created_at FROM holds
+  WHERE holds.from_account_id = $1
+    AND holds.status IN ('authorized', 'captured')
+    AND holds.created_at >= date_trunc('month', now() AT TIME ZONE 'UTC' + INTERVAL '4 hours')
+)
+SELECT
+  COUNT(*) FILTER (WHERE created_at >= date_trunc('day', now() AT TIME ZONE 'UTC' + INTERVAL '4 hours')) AS daily_count,
+  COALESCE(SUM(amount) FILTER (WHERE created_at >= date_trunc('day', now() AT TIME ZONE 'UTC' + INTERVAL '4 hours')), 0)::bigint AS daily_amount,
+  COUNT(*) AS monthly_count,
+  COALESCE(SUM(amount), 0)::bigint AS monthly_amount
+FROM outgoing
 `
 
-func (q *Queries) DeleteTransfer(ctx context.Context, id int64) error {
-	_, err := q.db.ExecContext(ctx, deleteTransfer, id)
-	return err
+type GetAccountTransferUsageRow struct {
+	DailyCount    int64 `json:"daily_count"`
+	DailyAmount   int64 `json:"daily_amount"`
+	MonthlyCount  int64 `json:"monthly_count"`
+	MonthlyAmount int64 `json:"monthly_amount"`
+}
+
+// authorized and captured holds count from their authorization with what they reserved or captured,
+// the transfers captures booked are not counted again
+func (q *Queries) GetAccountTransferUsage(ctx context.Context, fromAccountID int64) (GetAccountTransferUsageRow, error) {
+	row := q.db.QueryRowContext(ctx, getAccountTransferUsage, fromAccountID)
+	var i GetAccountTransferUsageRow
+	err := row.Scan(
+		&i.DailyCount,
+		&i.DailyAmount,
+		&i.MonthlyCount,
+		&i.MonthlyAmount,
+	)
+	return i, err
+}
+
+const getOwnerTransferUsage = `-- name: GetOwnerTransferUsage :one
+WITH outgoing AS (
+  SELECT transfers.amount, transfers.created_at FROM transfers
+  JOIN accounts ON accounts.id = transfers.from_account_id
+  WHERE accounts.owner = $1
+    AND transfers.currency = $2
+    AND transfers.reversal_of IS NULL
+    AND transfers.created_at >= date_trunc('month', now() AT TIME ZONE 'UTC' + INTERVAL '4 hours')
+    AND NOT EXISTS (SELECT 1 FROM holds WHERE holds.transfer_id = transfers.id)
+  UNION ALL
+  SELECT CASE WHEN holds.status = 'captured' THEN holds.captured_amount ELSE holds.amount END, holds.created_at FROM holds
+  JOIN accounts ON accounts.id = holds.from_account_id
+  WHERE accounts.owner = $1
+    AND holds.currency = $2
+    AND holds.status IN ('authorized', 'captured')
+    AND holds.created_at >= date_trunc('month', now() AT TIME ZONE 'UTC' + INTERVAL '4 hours')
+)
+SELECT
+  COUNT(*) FILTER (WHERE created_at >= date_trunc('day', now() AT TIME ZONE 'UTC' + INTERVAL '4 hours')) AS daily_count,
+  COALESCE(SUM(amount) FILTER (WHERE created_at >= date_trunc('day', now() AT TIME ZONE 'UTC' + INTERVAL '4 hours')), 0)::bigint AS daily_amount,
+  COUNT(*) AS monthly_count,
+  COALESCE(SUM(amount), 0)::bigint AS monthly_amount
+FROM outgoing
+`
+
+type GetOwnerTransferUsageParams struct {
+	Owner    string `json:"owner"`
+	Currency string `json:"currency"`
+}
+
+type GetOwnerTransferUsageRow struct {
+	DailyCount    int64 `json:"daily_count"`
+	DailyAmount   int64 `json:"daily_amount"`
+	MonthlyCount  int64 `json:"monthly_count"`
+	MonthlyAmount int64 `json:"monthly_amount"`
+}
+
+// the usage of all accounts of owner in currency, holds count like in GetAccountTransferUsage
+func (q *Queries) GetOwnerTransferUsage(ctx context.Context, arg GetOwnerTransferUsageParams) (GetOwnerTransferUsageRow, error) {
+	row := q.db.QueryRowContext(ctx, getOwnerTransferUsage, arg.Owner, arg.Currency)
+	var i GetOwnerTransferUsageRow
+	err := row.Scan(
+		&i.DailyCount,
+		&i.DailyAmount,
+		&i.MonthlyCount,
+		&i.MonthlyAmount,
+	)
+	return i, err
 }
 
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at 
+SELECT id, from_account_id, to_account_id, amount, created_at, currency, to_amount, to_currency, fx_rate, reversal_of, reversal_reason, fee, fee_account_id, description, reference, metadata 
 FROM transfers
 WHERE id = $1 
 LIMIT 1
@@ -66,12 +221,23 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.Currency,
+		&i.ToAmount,
+		&i.ToCurrency,
+		&i.FxRate,
+		&i.ReversalOf,
+		&i.ReversalReason,
+		&i.Fee,
+		&i.FeeAccountID,
+		&i.Description,
+		&i.Reference,
+		&i.Metadata,
 	)
 	return i, err
 }
 
 const getTransferByAccounts = `-- name: GetTransferByAccounts :one
-SELECT id, from_account_id, to_account_id, amount, created_at 
+SELECT id, from_account_id, to_account_id, amount, created_at, currency, to_amount, to_currency, fx_rate, reversal_of, reversal_reason, fee, fee_account_id, description, reference, metadata 
 FROM transfers
 WHERE from_account_id = $1
 AND to_account_id = $2
@@ -92,12 +258,23 @@ func (q *Queries) GetTransferByAccounts(ctx context.Context, arg GetTransferByAc
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.Currency,
+		&i.ToAmount,
+		&i.ToCurrency,
+		&i.FxRate,
+		&i.ReversalOf,
+		&i.ReversalReason,
+		&i.Fee,
+		&i.FeeAccountID,
+		&i.Description,
+		&i.Reference,
+		&i.Metadata,
 	)
 	return i, err
 }
 
 const getTransferByFromAccountId = `-- name: GetTransferByFromAccountId :one
-SELECT id, from_account_id, to_account_id, amount, created_at 
+SELECT id, from_account_id, to_account_id, amount, created_at, currency, to_amount, to_currency, fx_rate, reversal_of, reversal_reason, fee, fee_account_id, description, reference, metadata 
 FROM transfers
 WHERE from_account_id = $1 
 LIMIT 1
@@ -112,12 +289,23 @@ func (q *Queries) GetTransferByFromAccountId(ctx context.Context, fromAccountID
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.Currency,
+		&i.ToAmount,
+		&i.ToCurrency,
+		&i.FxRate,
+		&i.ReversalOf,
+		&i.ReversalReason,
+		&i.Fee,
+		&i.FeeAccountID,
+		&i.Description,
+		&i.Reference,
+		&i.Metadata,
 	)
 	return i, err
 }
 
 const getTransferByToAccountId = `-- name: GetTransferByToAccountId :one
-SELECT id, from_account_id, to_account_id, amount, created_at 
+SELECT id, from_account_id, to_account_id, amount, created_at, currency, to_amount, to_currency, fx_rate, reversal_of, reversal_reason, fee, fee_account_id, description, reference, metadata 
 FROM transfers
 WHERE to_account_id = $1 
 LIMIT 1
@@ -132,12 +320,86 @@ func (q *Queries) GetTransferByToAccountId(ctx context.Context, toAccountID int6
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.Currency,
+		&i.ToAmount,
+		&i.ToCurrency,
+		&i.FxRate,
+		&i.ReversalOf,
+		&i.ReversalReason,
+		&i.Fee,
+		&i.FeeAccountID,
+		&i.Description,
+		&i.Reference,
+		&i.Metadata,
+	)
+	return i, err
+}
+
+const getTransferForUpdate = `-- name: GetTransferForUpdate :one
+SELECT id, from_account_id, to_account_id, amount, created_at, currency, to_amount, to_currency, fx_rate, reversal_of, reversal_reason, fee, fee_account_id, description, reference, metadata 
+FROM transfers
+WHERE id = $1 
+LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, getTransferForUpdate, id)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.Currency,
+		&i.ToAmount,
+		&i.ToCurrency,
+		&i.FxRate,
+		&i.ReversalOf,
+		&i.ReversalReason,
+		&i.Fee,
+		&i.FeeAccountID,
+		&i.Description,
+		&i.Reference,
+		&i.Metadata,
+	)
+	return i, err
+}
+
+const getTransferReversal = `-- name: GetTransferReversal :one
+SELECT id, from_account_id, to_account_id, amount, created_at, currency, to_amount, to_currency, fx_rate, reversal_of, reversal_reason, fee, fee_account_id, description, reference, metadata 
+FROM transfers
+WHERE reversal_of = $1 
+LIMIT 1
+`
+
+func (q *Queries) GetTransferReversal(ctx context.Context, reversalOf sql.NullInt64) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, getTransferReversal, reversalOf)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.Currency,
+		&i.ToAmount,
+		&i.ToCurrency,
+		&i.FxRate,
+		&i.ReversalOf,
+		&i.ReversalReason,
+		&i.Fee,
+		&i.FeeAccountID,
+		&i.Description,
+		&i.Reference,
+		&i.Metadata,
 	)
 	return i, err
 }
 
 const listTransfer = `-- name: ListTransfer :many
-SELECT id, from_account_id, to_account_id, amount, created_at 
+SELECT id, from_account_id, to_account_id, amount, created_at, currency, to_amount, to_currency, fx_rate, reversal_of, reversal_reason, fee, fee_account_id, description, reference, metadata 
 FROM transfers
 ORDER BY id
 LIMIT $1
@@ -164,6 +426,17 @@ func (q *Queries) ListTransfer(ctx context.Context, arg ListTransferParams) ([]T
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.Currency,
+			&i.ToAmount,
+			&i.ToCurrency,
+			&i.FxRate,
+			&i.ReversalOf,
+			&i.ReversalReason,
+			&i.Fee,
+			&i.FeeAccountID,
+			&i.Description,
+			&i.Reference,
+			&i.Metadata,
 		); err != nil {
 			return nil, err
 		}
@@ -179,7 +452,7 @@ func (q *Queries) ListTransfer(ctx context.Context, arg ListTransferParams) ([]T
 }
 
 const listTransferByAccounts = `-- name: ListTransferByAccounts :many
-SELECT id, from_account_id, to_account_id, amount, created_at 
+SELECT id, from_account_id, to_account_id, amount, created_at, currency, to_amount, to_currency, fx_rate, reversal_of, reversal_reason, fee, fee_account_id, description, reference, metadata 
 FROM transfers
 WHERE from_account_id = $1
 AND to_account_id = $2
@@ -214,6 +487,17 @@ func (q *Queries) ListTransferByAccounts(ctx context.Context, arg ListTransferBy
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.Currency,
+			&i.ToAmount,
+			&i.ToCurrency,
+			&i.FxRate,
+			&i.ReversalOf,
+			&i.ReversalReason,
+			&i.Fee,
+			&i.FeeAccountID,
+			&i.Description,
+			&i.Reference,
+			&i.Metadata,
 		); err != nil {
 			return nil, err
 		}
@@ -229,7 +513,7 @@ func (q *Queries) ListTransferByAccounts(ctx context.Context, arg ListTransferBy
 }
 
 const listTransferByFromAccountId = `-- name: ListTransferByFromAccountId :many
-SELECT id, from_account_id, to_account_id, amount, created_at 
+SELECT id, from_account_id, to_account_id, amount, created_at, currency, to_amount, to_currency, fx_rate, reversal_of, reversal_reason, fee, fee_account_id, description, reference, metadata 
 FROM transfers
 WHERE from_account_id = $1
 LIMIT $2
@@ -257,6 +541,154 @@ func (q *Queries) ListTransferByFromAccountId(ctx context.Context, arg ListTrans
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.Currency,
+			&i.ToAmount,
+			&i.ToCurrency,
+			&i.FxRate,
+			&i.ReversalOf,
+			&i.ReversalReason,
+			&i.Fee,
+			&i.FeeAccountID,
+			&i.Description,
+			&i.Reference,
+			&i.Metadata,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransferByOwnerAfter = `-- name: ListTransferByOwnerAfter :many
+SELECT id, from_account_id, to_account_id, amount, created_at, currency, to_amount, to_currency, fx_rate, reversal_of, reversal_reason, fee, fee_account_id, description, reference, metadata 
+FROM transfers
+WHERE EXISTS (
+  SELECT 1
+  FROM accounts
+  WHERE accounts.owner = $1::varchar
+  AND accounts.id IN (transfers.from_account_id, transfers.to_account_id)
+)
+AND (created_at, id) > ($2::timestamp, $3::bigint)
+ORDER BY created_at, id
+LIMIT $4
+`
+
+type ListTransferByOwnerAfterParams struct {
+	Owner          string    `json:"owner"`
+	AfterCreatedAt time.Time `json:"after_created_at"`
+	AfterID        int64     `json:"after_id"`
+	LimitCount     int32     `json:"limit_count"`
+}
+
+// keyset page of the transfers in or out of an account of owner, after the cursor in (created_at, id) order
+func (q *Queries) ListTransferByOwnerAfter(ctx context.Context, arg ListTransferByOwnerAfterParams) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, listTransferByOwnerAfter,
+		arg.Owner,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.Currency,
+			&i.ToAmount,
+			&i.ToCurrency,
+			&i.FxRate,
+			&i.ReversalOf,
+			&i.ReversalReason,
+			&i.Fee,
+			&i.FeeAccountID,
+			&i.Description,
+			&i.Reference,
+			&i.Metadata,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransferByReference = `-- name: ListTransferByReference :many
+SELECT id, from_account_id, to_account_id, amount, created_at, currency, to_amount, to_currency, fx_rate, reversal_of, reversal_reason, fee, fee_account_id, description, reference, metadata 
+FROM transfers
+WHERE reference = $1::varchar
+AND EXISTS (
+  SELECT 1
+  FROM accounts
+  WHERE accounts.owner = $2::varchar
+  AND accounts.id IN (transfers.from_account_id, transfers.to_account_id)
+)
+AND (created_at, id) > ($3::timestamp, $4::bigint)
+ORDER BY created_at, id
+LIMIT $5
+`
+
+type ListTransferByReferenceParams struct {
+	Reference      string    `json:"reference"`
+	Owner          string    `json:"owner"`
+	AfterCreatedAt time.Time `json:"after_created_at"`
+	AfterID        int64     `json:"after_id"`
+	LimitCount     int32     `json:"limit_count"`
+}
+
+// keyset page of the transfers with reference in or out of an account of owner, after the cursor in (created_at, id) order
+func (q *Queries) ListTransferByReference(ctx context.Context, arg ListTransferByReferenceParams) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, listTransferByReference,
+		arg.Reference,
+		arg.Owner,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.Currency,
+			&i.ToAmount,
+			&i.ToCurrency,
+			&i.FxRate,
+			&i.ReversalOf,
+			&i.ReversalReason,
+			&i.Fee,
+			&i.FeeAccountID,
+			&i.Description,
+			&i.Reference,
+			&i.Metadata,
 		); err != nil {
 			return nil, err
 		}
@@ -272,7 +704,7 @@ func (q *Queries) ListTransferByFromAccountId(ctx context.Context, arg ListTrans
 }
 
 const listTransferByToAccountId = `-- name: ListTransferByToAccountId :many
-SELECT id, from_account_id, to_account_id, amount, created_at 
+SELECT id, from_account_id, to_account_id, amount, created_at, currency, to_amount, to_currency, fx_rate, reversal_of, reversal_reason, fee, fee_account_id, description, reference, metadata 
 FROM transfers
 WHERE to_account_id = $1
 LIMIT $2
@@ -300,6 +732,17 @@ func (q *Queries) ListTransferByToAccountId(ctx context.Context, arg ListTransfe
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.Currency,
+			&i.ToAmount,
+			&i.ToCurrency,
+			&i.FxRate,
+			&i.ReversalOf,
+			&i.ReversalReason,
+			&i.Fee,
+			&i.FeeAccountID,
+			&i.Description,
+			&i.Reference,
+			&i.Metadata,
 		); err != nil {
 			return nil, err
 		}
@@ -313,28 +756,3 @@ func (q *Queries) ListTransferByToAccountId(ctx context.Context, arg ListTransfe
 	}
 	return items, nil
 }
-
-const updateTransfer = `-- name: UpdateTransfer :one
-UPDATE transfers 
-SET amount = $2
-WHERE id = $1
-RETURNING id, from_account_id, to_account_id, amount, created_at
-`
-
-type UpdateTransferParams struct {
-	ID     int64   `json:"id"`
-	Amount float64 `json:"amount"`
-}
-
-func (q *Queries) UpdateTransfer(ctx context.Context, arg UpdateTransferParams) (Transfer, error) {
-	row := q.db.QueryRowContext(ctx, updateTransfer, arg.ID, arg.Amount)
-	var i Transfer
-	err := row.Scan(
-		&i.ID,
-		&i.FromAccountID,
-		&i.ToAccountID,
-		&i.Amount,
-		&i.CreatedAt,
-	)
-	return i, err
-}
@@ -0,0 +1,134 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Scopes of a transfer limit
+const (
+	LimitScopeAccount = "account"
+	LimitScopeUser    = "user"
+)
+
+// Limits a transfer limit row can set, the names of LimitExceededError.Limit
+const (
+	LimitPerTransfer   = "per_transfer"
+	LimitDailyCount    = "daily_count"
+	LimitDailyAmount   = "daily_amount"
+	LimitMonthlyCount  = "monthly_count"
+	LimitMonthlyAmount = "monthly_amount"
+)
+
+// LimitExceededError tells which limit a transfer would go over and what is left of it
+// Max and Remaining are in minor units of Currency for amount limits and in transfers for count limits,
+// Remaining is what is left for the rest of the bank day or month, or Max for a per transfer limit
+type LimitExceededError struct {
+	Scope     string `json:"scope"`
+	Limit     string `json:"limit"`
+	Currency  string `json:"currency"`
+	Max       int64  `json:"max"`
+	Remaining int64  `json:"remaining"`
+}
+
+func (e *LimitExceededError) Error() string {
+	return fmt.Sprintf("%s: %s %s limit of %d %s, %d remaining", ErrLimitExceeded, e.Scope, e.Limit, e.Max, e.Currency, e.Remaining)
+}
+
+// Unwrap makes errors.Is match ErrLimitExceeded
+func (e *LimitExceededError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+// IsCountLimit reports whether the limit counts transfers rather than adding up amounts
+func (e *LimitExceededError) IsCountLimit() bool {
+	return e.Limit == LimitDailyCount || e.Limit == LimitMonthlyCount
+}
+
+// transferUsage is what already left an account, or all accounts of a user in a currency,
+// in the current bank day and month, reversals excluded
+type transferUsage struct {
+	DailyCount    int64
+	DailyAmount   int64
+	MonthlyCount  int64
+	MonthlyAmount int64
+}
+
+// checkLimits checks amount leaving the locked account against the limits of the account and of its owner
+// The account lock makes the account limits atomic, the user limit row is locked for update so concurrent
+// transfers out of different accounts of the user are checked one after the other. Lock order is accounts first,
+// then the user limit row. Internal bank accounts have no limits
+func checkLimits(ctx context.Context, q *Queries, account Account, amount int64) error {
+	if IsInternalAccount(account) {
+		return nil
+	}
+
+	accountLimit, err := q.GetAccountTransferLimit(ctx, sql.NullInt64{Int64: account.ID, Valid: true})
+	switch {
+	case err == nil:
+		usage, err := q.GetAccountTransferUsage(ctx, account.ID)
+		if err != nil {
+			return err
+		}
+		if err := checkLimit(accountLimit, LimitScopeAccount, account.Currency, transferUsage(usage), amount); err != nil {
+			return err
+		}
+	case err != sql.ErrNoRows:
+		return err
+	}
+
+	userLimit, err := q.GetUserTransferLimitForUpdate(ctx, GetUserTransferLimitForUpdateParams{
+		Username: sql.NullString{String: account.Owner, Valid: true},
+		Currency: account.Currency,
+	})
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	usage, err := q.GetOwnerTransferUsage(ctx, GetOwnerTransferUsageParams{
+		Owner:    account.Owner,
+		Currency: account.Currency,
+	})
+	if err != nil {
+		return err
+	}
+	return checkLimit(userLimit, LimitScopeUser, account.Currency, transferUsage(usage), amount)
+}
+
+// checkLimit returns a *LimitExceededError for the first limit of the row one more transfer of amount would go over
+func checkLimit(limit TransferLimit, scope string, currency string, usage transferUsage, amount int64) error {
+	checks := []struct {
+		name   string
+		max    sql.NullInt64
+		used   int64
+		adding int64
+	}{
+		{name: LimitPerTransfer, max: limit.MaxPerTransfer, adding: amount},
+		{name: LimitDailyCount, max: limit.MaxDailyCount, used: usage.DailyCount, adding: 1},
+		{name: LimitDailyAmount, max: limit.MaxDailyAmount, used: usage.DailyAmount, adding: amount},
+		{name: LimitMonthlyCount, max: limit.MaxMonthlyCount, used: usage.MonthlyCount, adding: 1},
+		{name: LimitMonthlyAmount, max: limit.MaxMonthlyAmount, used: usage.MonthlyAmount, adding: amount},
+	}
+
+	for _, check := range checks {
+		if !check.max.Valid {
+			continue
+		}
+
+		remaining := max(check.max.Int64-check.used, 0)
+		if check.adding > remaining {
+			return &LimitExceededError{
+				Scope:     scope,
+				Limit:     check.name,
+				Currency:  currency,
+				Max:       check.max.Int64,
+				Remaining: remaining,
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func limitOf(max int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: max, Valid: true}
+}
+
+func TestCheckLimit(t *testing.T) {
+	testCases := []struct {
+		name          string
+		limit         TransferLimit
+		usage         transferUsage
+		amount        int64
+		wantLimit     string
+		wantRemaining int64
+	}{
+		{name: "NoLimits", limit: TransferLimit{}, usage: transferUsage{DailyCount: 100, DailyAmount: 1000000}, amount: 100000},
+		{name: "PerTransfer", limit: TransferLimit{MaxPerTransfer: limitOf(1000)}, amount: 1001, wantLimit: LimitPerTransfer, wantRemaining: 1000},
+		{name: "PerTransferExact", limit: TransferLimit{MaxPerTransfer: limitOf(1000)}, amount: 1000},
+		{name: "DailyCount", limit: TransferLimit{MaxDailyCount: limitOf(3)}, usage: transferUsage{DailyCount: 3}, amount: 1, wantLimit: LimitDailyCount},
+		{name: "DailyAmount", limit: TransferLimit{MaxDailyAmount: limitOf(5000)}, usage: transferUsage{DailyAmount: 4500}, amount: 501, wantLimit: LimitDailyAmount, wantRemaining: 500},
+		{name: "MonthlyCount", limit: TransferLimit{MaxMonthlyCount: limitOf(10)}, usage: transferUsage{MonthlyCount: 10}, amount: 1, wantLimit: LimitMonthlyCount},
+		{name: "MonthlyAmount", limit: TransferLimit{MaxMonthlyAmount: limitOf(5000)}, usage: transferUsage{MonthlyAmount: 6000}, amount: 1, wantLimit: LimitMonthlyAmount},
+		{name: "WithinAll", limit: TransferLimit{MaxDailyAmount: limitOf(5000), MaxMonthlyCount: limitOf(10)}, usage: transferUsage{DailyAmount: 1000, MonthlyCount: 9}, amount: 4000},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkLimit(tc.limit, LimitScopeAccount, "USD", tc.usage, tc.amount)
+			if tc.wantLimit == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, ErrLimitExceeded)
+			var limitErr *LimitExceededError
+			require.True(t, errors.As(err, &limitErr))
+			require.Equal(t, tc.wantLimit, limitErr.Limit)
+			require.Equal(t, tc.wantRemaining, limitErr.Remaining)
+		})
+	}
+}
+
+func TestTransferTxAccountLimit(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithCurrency(t, "USD")
+	account2 := createRandomAccountWithCurrency(t, "USD")
+
+	_, err := testQueries.UpsertAccountTransferLimit(context.Background(), UpsertAccountTransferLimitParams{
+		AccountID:      account1.ID,
+		Currency:       "USD",
+		MaxDailyAmount: limitOf(15),
+		MaxDailyCount:  limitOf(2),
+	})
+	require.NoError(t, err)
+
+	transfer := func(amount int64) error {
+		_, err := store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        amount,
+			Currency:      "USD",
+		})
+		return err
+	}
+
+	require.NoError(t, transfer(10))
+
+	err = transfer(6)
+	var limitErr *LimitExceededError
+	require.True(t, errors.As(err, &limitErr))
+	require.Equal(t, LimitScopeAccount, limitErr.Scope)
+	require.Equal(t, LimitDailyAmount, limitErr.Limit)
+	require.Equal(t, int64(5), limitErr.Remaining)
+
+	require.NoError(t, transfer(5))
+
+	err = transfer(1)
+	require.True(t, errors.As(err, &limitErr))
+	require.Equal(t, LimitDailyCount, limitErr.Limit)
+}
+
+func TestTransferTxUserLimit(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := createRandomUser(t)
+	var accounts []Account
+	for _, accountType := range []string{AccountChecking, AccountOverdraft} {
+		account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+			Owner:    user.Username,
+			Balance:  10000,
+			Currency: "EUR",
+			Type:     accountType,
+		})
+		require.NoError(t, err)
+		accounts = append(accounts, account)
+	}
+	other := createRandomAccountWithCurrency(t, "EUR")
+
+	_, err := testQueries.UpsertUserTransferLimit(context.Background(), UpsertUserTransferLimitParams{
+		Username:       user.Username,
+		Currency:       "EUR",
+		MaxDailyAmount: limitOf(1000),
+	})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: accounts[0].ID,
+		ToAccountID:   other.ID,
+		Amount:        600,
+		Currency:      "EUR",
+	})
+	require.NoError(t, err)
+
+	// the user limit adds up the transfers out of all accounts of the user
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: accounts[1].ID,
+		ToAccountID:   other.ID,
+		Amount:        600,
+		Currency:      "EUR",
+	})
+	var limitErr *LimitExceededError
+	require.True(t, errors.As(err, &limitErr))
+	require.Equal(t, LimitScopeUser, limitErr.Scope)
+	require.Equal(t, int64(400), limitErr.Remaining)
+}
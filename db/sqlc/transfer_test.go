@@ -2,7 +2,7 @@ package db
 
 import (
 	"context"
-	"database/sql"
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -13,28 +13,22 @@ import (
 func createRandomTransfer(t *testing.T) Transfer {
 	account1 := createRandomAccount(t)
 	account2 := createRandomAccount(t)
-	args := CreateTransferParams{
-		FromAccountID: account1.ID,
-		ToAccountID:   account2.ID,
-		Amount:        util.RandomMoney(),
-	}
-
-	transfer, err := testQueries.CreateTransfer(context.Background(), args)
-	require.NoError(t, err)
-	require.NotEmpty(t, transfer)
-
-	require.Equal(t, transfer.FromAccountID, args.FromAccountID)
-	require.Equal(t, transfer.ToAccountID, args.ToAccountID)
-	require.Equal(t, transfer.Amount, args.Amount)
 
-	return transfer
+	return createRandomTransferForAccounts(t, account1.ID, account2.ID)
 }
 
 func createRandomTransferForAccounts(t *testing.T, fromAccountId int64, toAccountId int64) Transfer {
+	amount := util.RandomMoney()
+	currency := util.RandomCurrency()
 	args := CreateTransferParams{
 		FromAccountID: fromAccountId,
 		ToAccountID:   toAccountId,
-		Amount:        util.RandomMoney(),
+		Amount:        amount,
+		Currency:      currency,
+		ToAmount:      amount,
+		ToCurrency:    currency,
+		FxRate:        "1",
+		Metadata:      json.RawMessage("{}"),
 	}
 
 	transfer, err := testQueries.CreateTransfer(context.Background(), args)
@@ -44,6 +38,9 @@ func createRandomTransferForAccounts(t *testing.T, fromAccountId int64, toAccoun
 	require.Equal(t, transfer.FromAccountID, args.FromAccountID)
 	require.Equal(t, transfer.ToAccountID, args.ToAccountID)
 	require.Equal(t, transfer.Amount, args.Amount)
+	require.Equal(t, transfer.Currency, args.Currency)
+	require.Equal(t, transfer.ToAmount, args.ToAmount)
+	require.Equal(t, transfer.ToCurrency, args.ToCurrency)
 
 	return transfer
 }
@@ -117,18 +114,6 @@ func TestGetTransferByToAccount(t *testing.T) {
 	require.WithinDuration(t, transfer2.CreatedAt, transfer1.CreatedAt, time.Second)
 }
 
-func TestDeleteTransfer(t *testing.T) {
-	transfer1 := createRandomTransfer(t)
-
-	err := testQueries.DeleteTransfer(context.Background(), transfer1.ID)
-	require.NoError(t, err)
-
-	transfer2, err := testQueries.GetTransfer(context.Background(), transfer1.ID)
-	require.Error(t, err)
-	require.EqualError(t, err, sql.ErrNoRows.Error())
-	require.Empty(t, transfer2)
-}
-
 func TestListTransfer(t *testing.T) {
 	for i := 0; i < 10; i++ {
 		createRandomTransfer(t)
@@ -218,18 +203,108 @@ func TestListTransferByToAccountId(t *testing.T) {
 	}
 }
 
-func TestUpdateTransfer(t *testing.T) {
-	transfer1 := createRandomTransfer(t)
+func TestListTransferByOwnerAfter(t *testing.T) {
+	account1 := createAccountOfType(t, AccountChecking, 10000)
+	account2 := createAccountOfType(t, AccountChecking, 10000)
+	store := NewStore(testDB)
+
+	var created []Transfer
+	for _, arg := range []TransferTxParams{
+		{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 100, Currency: "USD"},
+		{FromAccountID: account2.ID, ToAccountID: account1.ID, Amount: 200, Currency: "USD"},
+		{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 300, Currency: "USD"},
+	} {
+		result, err := store.TransferTx(context.Background(), arg)
+		require.NoError(t, err)
+		created = append(created, result.Transfer)
+	}
+
+	// transfers in and out of the accounts of the owner are listed, page after page
+	first, err := testQueries.ListTransferByOwnerAfter(context.Background(), ListTransferByOwnerAfterParams{
+		Owner:      account1.Owner,
+		LimitCount: 2,
+	})
+	require.NoError(t, err)
+	require.Len(t, first, 2)
+	require.Equal(t, created[0].ID, first[0].ID)
+	require.Equal(t, created[1].ID, first[1].ID)
+
+	last := first[len(first)-1]
+	second, err := testQueries.ListTransferByOwnerAfter(context.Background(), ListTransferByOwnerAfterParams{
+		Owner:          account1.Owner,
+		AfterCreatedAt: last.CreatedAt,
+		AfterID:        last.ID,
+		LimitCount:     2,
+	})
+	require.NoError(t, err)
+	require.Len(t, second, 1)
+	require.Equal(t, created[2].ID, second[0].ID)
+
+	// other users see none of them
+	other := createRandomUser(t)
+	transfers, err := testQueries.ListTransferByOwnerAfter(context.Background(), ListTransferByOwnerAfterParams{
+		Owner:      other.Username,
+		LimitCount: 5,
+	})
+	require.NoError(t, err)
+	require.Empty(t, transfers)
+}
 
-	args := UpdateTransferParams{ID: transfer1.ID, Amount: util.RandomMoney()}
+func TestListTransferByReference(t *testing.T) {
+	account1 := createAccountOfType(t, AccountChecking, 10000)
+	account2 := createAccountOfType(t, AccountChecking, 10000)
+	reference := "INV-" + util.RandomString(10)
+	store := NewStore(testDB)
 
-	transfer2, err := testQueries.UpdateTransfer(context.Background(), args)
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1000,
+		Currency:      "USD",
+		Description:   "Invoice payment",
+		Reference:     reference,
+		Metadata:      map[string]string{"order_id": "42"},
+	})
 	require.NoError(t, err)
-	require.NotEmpty(t, transfer2)
+	require.Equal(t, "Invoice payment", result.Transfer.Description.String)
+	require.Equal(t, reference, result.Transfer.Reference.String)
+	require.JSONEq(t, `{"order_id": "42"}`, string(result.Transfer.Metadata))
+
+	// a transfer without details gets no description and reference and empty metadata
+	plain, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account2.ID,
+		ToAccountID:   account1.ID,
+		Amount:        500,
+		Currency:      "USD",
+	})
+	require.NoError(t, err)
+	require.False(t, plain.Transfer.Description.Valid)
+	require.False(t, plain.Transfer.Reference.Valid)
+	require.JSONEq(t, `{}`, string(plain.Transfer.Metadata))
+
+	// both sides find the transfer by its reference, other users don't
+	for _, owner := range []string{account1.Owner, account2.Owner} {
+		transfers, err := testQueries.ListTransferByReference(context.Background(), ListTransferByReferenceParams{
+			Reference:  reference,
+			Owner:      owner,
+			LimitCount: 5,
+		})
+		require.NoError(t, err)
+		require.Len(t, transfers, 1)
+		require.Equal(t, result.Transfer.ID, transfers[0].ID)
+	}
 
-	require.Equal(t, transfer2.ID, transfer1.ID)
-	require.Equal(t, transfer2.FromAccountID, transfer1.FromAccountID)
-	require.Equal(t, transfer2.ToAccountID, transfer1.ToAccountID)
-	require.Equal(t, transfer2.Amount, args.Amount)
-	require.WithinDuration(t, transfer2.CreatedAt, transfer1.CreatedAt, time.Second)
+	other := createRandomUser(t)
+	transfers, err := testQueries.ListTransferByReference(context.Background(), ListTransferByReferenceParams{
+		Reference:  reference,
+		Owner:      other.Username,
+		LimitCount: 5,
+	})
+	require.NoError(t, err)
+	require.Empty(t, transfers)
+
+	// the details are part of the hash chain
+	verification, err := store.VerifyLedger(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.True(t, verification.Valid)
 }
@@ -0,0 +1,37 @@
+package fx
+
+import (
+	"context"
+	"database/sql"
+
+	db "github.com/T-BO0/bank/db/sqlc"
+)
+
+// DBRateProvider reads rates from the fx_rates table
+type DBRateProvider struct {
+	querier db.Querier
+}
+
+// NewDBRateProvider creates a new DBRateProvider
+func NewDBRateProvider(querier db.Querier) RateProvider {
+	return &DBRateProvider{querier: querier}
+}
+
+// Rate returns a decimal string of quote currency units per one unit of base currency
+func (provider *DBRateProvider) Rate(ctx context.Context, base string, quote string) (string, error) {
+	return findRate(ctx, provider.lookup, base, quote)
+}
+
+func (provider *DBRateProvider) lookup(ctx context.Context, base string, quote string) (string, bool, error) {
+	fxRate, err := provider.querier.GetFxRate(ctx, db.GetFxRateParams{
+		BaseCurrency:  base,
+		QuoteCurrency: quote,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	return fxRate.Rate, true, nil
+}
@@ -0,0 +1,67 @@
+package fx
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+)
+
+// FileRateProvider serves rates loaded once from a CSV file with base,quote,rate rows
+type FileRateProvider struct {
+	rates map[string]string
+}
+
+// NewFileRateProvider loads rates from the CSV file at path, a header row is optional
+func NewFileRateProvider(path string) (RateProvider, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open fx rates file: %w", err)
+	}
+	defer file.Close()
+
+	return newFileRateProvider(file)
+}
+
+func newFileRateProvider(r io.Reader) (*FileRateProvider, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read fx rates file: %w", err)
+	}
+
+	provider := &FileRateProvider{rates: make(map[string]string)}
+	for i, record := range records {
+		base, quote, rate := strings.ToUpper(record[0]), strings.ToUpper(record[1]), record[2]
+		if i == 0 && base == "BASE" {
+			continue
+		}
+
+		r, ok := new(big.Rat).SetString(rate)
+		if !ok || r.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid fx rate %q for %s/%s on line %d", rate, base, quote, i+1)
+		}
+		provider.rates[pairKey(base, quote)] = rate
+	}
+	return provider, nil
+}
+
+// Rate returns a decimal string of quote currency units per one unit of base currency
+func (provider *FileRateProvider) Rate(ctx context.Context, base string, quote string) (string, error) {
+	return findRate(ctx, provider.lookup, base, quote)
+}
+
+func (provider *FileRateProvider) lookup(_ context.Context, base string, quote string) (string, bool, error) {
+	rate, ok := provider.rates[pairKey(base, quote)]
+	return rate, ok, nil
+}
+
+func pairKey(base string, quote string) string {
+	return base + "/" + quote
+}
@@ -0,0 +1,42 @@
+package fx
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileRateProvider(t *testing.T) {
+	provider, err := newFileRateProvider(strings.NewReader("base,quote,rate\nUSD,GEL,2.7\neur, usd, 1.08\n"))
+	require.NoError(t, err)
+
+	rate, err := provider.Rate(context.Background(), "USD", "GEL")
+	require.NoError(t, err)
+	require.Equal(t, "2.7", rate)
+
+	rate, err = provider.Rate(context.Background(), "EUR", "USD")
+	require.NoError(t, err)
+	require.Equal(t, "1.08", rate)
+
+	// inverse of a stored pair
+	rate, err = provider.Rate(context.Background(), "GEL", "USD")
+	require.NoError(t, err)
+	require.Equal(t, "0.3703703704", rate)
+
+	rate, err = provider.Rate(context.Background(), "GEL", "GEL")
+	require.NoError(t, err)
+	require.Equal(t, "1", rate)
+
+	_, err = provider.Rate(context.Background(), "EUR", "GEL")
+	require.ErrorIs(t, err, ErrRateNotFound)
+}
+
+func TestFileRateProviderInvalidRate(t *testing.T) {
+	_, err := newFileRateProvider(strings.NewReader("USD,GEL,-2.7\n"))
+	require.Error(t, err)
+
+	_, err = newFileRateProvider(strings.NewReader("USD,GEL\n"))
+	require.Error(t, err)
+}
@@ -0,0 +1,43 @@
+// Package fx provides foreign exchange rates for cross-currency transfers
+package fx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/T-BO0/bank/util/money"
+)
+
+// ErrRateNotFound is returned when neither the pair nor its inverse has a rate
+var ErrRateNotFound = errors.New("fx rate not found")
+
+// RateProvider is an interface for looking up exchange rates
+type RateProvider interface {
+	// Rate returns a decimal string of quote currency units per one unit of base currency
+	Rate(ctx context.Context, base string, quote string) (string, error)
+}
+
+// lookupFunc returns the stored rate of a currency pair, and false if there is none
+type lookupFunc func(ctx context.Context, base string, quote string) (string, bool, error)
+
+// findRate resolves a rate by the direct pair first and falls back to inverting the opposite pair
+func findRate(ctx context.Context, lookup lookupFunc, base string, quote string) (string, error) {
+	if base == quote {
+		return "1", nil
+	}
+
+	rate, ok, err := lookup(ctx, base, quote)
+	if err != nil || ok {
+		return rate, err
+	}
+
+	inverse, ok, err := lookup(ctx, quote, base)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", fmt.Errorf("%w: %s/%s", ErrRateNotFound, base, quote)
+	}
+	return money.InvertRate(inverse)
+}
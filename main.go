@@ -1,12 +1,14 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"log"
 
 	"github.com/T-BO0/bank/api"
-	db "github.com/T-BO0/bank/db/sqlc"
+	"github.com/T-BO0/bank/db/dbx"
 	"github.com/T-BO0/bank/util"
+	"github.com/T-BO0/bank/worker"
 	_ "github.com/lib/pq"
 )
 
@@ -20,8 +22,24 @@ func main() {
 		log.Fatal("cannot connect to db", err)
 	}
 
-	store := db.NewStore(conn)
-	server := api.NewServer(store)
+	store := dbx.NewStore(conn)
+	if config.ReconciliationInterval > 0 {
+		go worker.NewReconciler(store, config.ReconciliationInterval).Run(context.Background())
+	}
+	if config.SchedulerInterval > 0 {
+		go worker.NewScheduler(store, config.SchedulerInterval).Run(context.Background())
+	}
+	if config.HoldExpiryInterval > 0 {
+		go worker.NewHoldExpirer(store, config.HoldExpiryInterval).Run(context.Background())
+	}
+	if config.InterestInterval > 0 {
+		go worker.NewInterestJob(store, config.InterestInterval).Run(context.Background())
+	}
+
+	server, err := api.NewServer(config, store)
+	if err != nil {
+		log.Fatal("cannot create server: ", err)
+	}
 
 	err = server.Start(config.ServerAddress)
 	if err != nil {
@@ -0,0 +1,287 @@
+package statement
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/T-BO0/bank/util/money"
+)
+
+// camtNamespace is the namespace of the camt.053 version written, the one most ERP systems import
+const camtNamespace = "urn:iso:std:iso:20022:tech:xsd:camt.053.001.08"
+
+// Lengths of the text types of the schema, longer values are cut to fit
+const (
+	camtMax35Text  = 35
+	camtMax140Text = 140
+)
+
+// Codes of the schema used in a statement
+const (
+	camtCredit           = "CRDT"
+	camtDebit            = "DBIT"
+	camtOpeningBooked    = "OPBD"
+	camtClosingBooked    = "CLBD"
+	camtBooked           = "BOOK"
+	camtPayments         = "PMNT"
+	camtIssuedTransfer   = "ICDT"
+	camtReceivedTransfer = "RCDT"
+	camtInternalBook     = "BOOK"
+)
+
+// The types below follow the elements of camt.053.001.08 they are named after, their fields are in the order of the
+// schema sequences, which encoding/xml keeps. Only the elements a statement of the bank has are there.
+
+type camtAmount struct {
+	Currency string `xml:"Ccy,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type camtGroupHeader struct {
+	MessageID       string `xml:"MsgId"`
+	CreatedDateTime string `xml:"CreDtTm"`
+}
+
+type camtPeriod struct {
+	FromDateTime string `xml:"FrDtTm"`
+	ToDateTime   string `xml:"ToDtTm"`
+}
+
+type camtAccount struct {
+	ID        string `xml:"Id>Othr>Id"`
+	Currency  string `xml:"Ccy"`
+	OwnerName string `xml:"Ownr>Nm"`
+}
+
+type camtCounterpartyAccount struct {
+	ID string `xml:"Id>Othr>Id"`
+}
+
+type camtBalance struct {
+	Code                 string     `xml:"Tp>CdOrPrtry>Cd"`
+	Amount               camtAmount `xml:"Amt"`
+	CreditDebitIndicator string     `xml:"CdtDbtInd"`
+	Date                 string     `xml:"Dt>Dt"`
+}
+
+type camtSum struct {
+	Sum string `xml:"Sum"`
+}
+
+type camtTotals struct {
+	NumberOfEntries      int64   `xml:"TtlNtries>NbOfNtries"`
+	Sum                  string  `xml:"TtlNtries>Sum"`
+	NetAmount            string  `xml:"TtlNtries>TtlNetNtry>Amt"`
+	CreditDebitIndicator string  `xml:"TtlNtries>TtlNetNtry>CdtDbtInd"`
+	Credits              camtSum `xml:"TtlCdtNtries"`
+	Debits               camtSum `xml:"TtlDbtNtries"`
+}
+
+type camtBankTransactionCode struct {
+	Domain    string `xml:"Domn>Cd"`
+	Family    string `xml:"Domn>Fmly>Cd"`
+	SubFamily string `xml:"Domn>Fmly>SubFmlyCd"`
+}
+
+type camtParty struct {
+	Name string `xml:"Pty>Nm"`
+}
+
+type camtRelatedParties struct {
+	Debtor          *camtParty               `xml:"Dbtr,omitempty"`
+	DebtorAccount   *camtCounterpartyAccount `xml:"DbtrAcct,omitempty"`
+	Creditor        *camtParty               `xml:"Cdtr,omitempty"`
+	CreditorAccount *camtCounterpartyAccount `xml:"CdtrAcct,omitempty"`
+}
+
+type camtReferences struct {
+	EndToEndID string `xml:"EndToEndId"`
+}
+
+type camtRemittance struct {
+	Unstructured string `xml:"Ustrd"`
+}
+
+// camtTransactionDetails has pointers to the optional elements, encoding/xml writes the parents of an empty a>b field
+type camtTransactionDetails struct {
+	References           *camtReferences     `xml:"Refs,omitempty"`
+	Amount               camtAmount          `xml:"Amt"`
+	CreditDebitIndicator string              `xml:"CdtDbtInd"`
+	RelatedParties       *camtRelatedParties `xml:"RltdPties,omitempty"`
+	Remittance           *camtRemittance     `xml:"RmtInf,omitempty"`
+}
+
+type camtEntry struct {
+	XMLName              xml.Name                `xml:"Ntry"`
+	Reference            string                  `xml:"NtryRef"`
+	Amount               camtAmount              `xml:"Amt"`
+	CreditDebitIndicator string                  `xml:"CdtDbtInd"`
+	Status               string                  `xml:"Sts>Cd"`
+	BookingDateTime      string                  `xml:"BookgDt>DtTm"`
+	ValueDate            string                  `xml:"ValDt>Dt"`
+	ServicerReference    string                  `xml:"AcctSvcrRef"`
+	BankTransactionCode  camtBankTransactionCode `xml:"BkTxCd"`
+	Details              camtTransactionDetails  `xml:"NtryDtls>TxDtls"`
+}
+
+// camtWriter writes a statement as an ISO 20022 camt.053 bank to customer statement
+// The document is encoded element by element, every entry is encoded on its own as it comes
+type camtWriter struct {
+	w        *bufio.Writer
+	enc      *xml.Encoder
+	currency string
+}
+
+func newCamtWriter(w io.Writer) Writer {
+	buffered := bufio.NewWriter(w)
+	enc := xml.NewEncoder(buffered)
+	enc.Indent("", "  ")
+	return &camtWriter{w: buffered, enc: enc}
+}
+
+func (writer *camtWriter) Begin(statement Statement) error {
+	writer.currency = statement.Currency
+	id := statementID(statement)
+	created := camtDateTime(bankTime(statement.GeneratedAt))
+
+	if err := writer.enc.EncodeToken(xml.ProcInst{Target: "xml", Inst: []byte(`version="1.0" encoding="UTF-8"`)}); err != nil {
+		return err
+	}
+	for _, start := range []xml.StartElement{
+		{Name: xml.Name{Local: "Document"}, Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: camtNamespace}}},
+		{Name: xml.Name{Local: "BkToCstmrStmt"}},
+	} {
+		if err := writer.enc.EncodeToken(start); err != nil {
+			return err
+		}
+	}
+	if err := writer.enc.EncodeElement(camtGroupHeader{MessageID: id, CreatedDateTime: created}, camtStart("GrpHdr")); err != nil {
+		return err
+	}
+
+	if err := writer.enc.EncodeToken(camtStart("Stmt")); err != nil {
+		return err
+	}
+	net, netIndicator := camtSigned(statement.TotalCredits-statement.TotalDebits, statement.Currency)
+	for _, element := range []struct {
+		name  string
+		value interface{}
+	}{
+		{name: "Id", value: id},
+		{name: "CreDtTm", value: created},
+		{name: "FrToDt", value: camtPeriod{
+			FromDateTime: camtDateTime(statement.From),
+			ToDateTime:   camtDateTime(statement.To.AddDate(0, 0, 1).Add(-time.Second)),
+		}},
+		{name: "Acct", value: camtAccount{
+			ID:        fmt.Sprint(statement.AccountID),
+			Currency:  statement.Currency,
+			OwnerName: truncate(statement.Owner, camtMax140Text),
+		}},
+		{name: "Bal", value: camtBalanceOf(camtOpeningBooked, statement.OpeningBalance, statement.Currency, statement.From)},
+		{name: "Bal", value: camtBalanceOf(camtClosingBooked, statement.ClosingBalance, statement.Currency, statement.To)},
+		{name: "TxsSummry", value: camtTotals{
+			NumberOfEntries:      statement.EntryCount,
+			Sum:                  money.Format(statement.TotalCredits+statement.TotalDebits, statement.Currency),
+			NetAmount:            net,
+			CreditDebitIndicator: netIndicator,
+			Credits:              camtSum{Sum: money.Format(statement.TotalCredits, statement.Currency)},
+			Debits:               camtSum{Sum: money.Format(statement.TotalDebits, statement.Currency)},
+		}},
+	} {
+		if err := writer.enc.EncodeElement(element.value, camtStart(element.name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (writer *camtWriter) Line(line Line) error {
+	amount, indicator := camtSigned(line.Amount, writer.currency)
+	entry := camtEntry{
+		Reference:            fmt.Sprint(line.ID),
+		Amount:               camtAmount{Currency: writer.currency, Value: amount},
+		CreditDebitIndicator: indicator,
+		Status:               camtBooked,
+		BookingDateTime:      camtDateTime(line.CreatedAt),
+		ValueDate:            line.CreatedAt.Format(bankDateLayout),
+		ServicerReference:    fmt.Sprint(line.ID),
+		BankTransactionCode: camtBankTransactionCode{
+			Domain:    camtPayments,
+			Family:    camtReceivedTransfer,
+			SubFamily: camtInternalBook,
+		},
+		Details: camtTransactionDetails{
+			Amount:               camtAmount{Currency: writer.currency, Value: amount},
+			CreditDebitIndicator: indicator,
+		},
+	}
+	if line.Amount < 0 {
+		entry.BankTransactionCode.Family = camtIssuedTransfer
+	}
+	if line.Reference.Valid {
+		entry.Details.References = &camtReferences{EndToEndID: truncate(line.Reference.String, camtMax35Text)}
+	}
+	if line.Description.Valid {
+		entry.Details.Remittance = &camtRemittance{Unstructured: truncate(line.Description.String, camtMax140Text)}
+	}
+
+	// the counterparty is the creditor of a debit and the debtor of a credit
+	if line.CounterpartyAccountID.Valid {
+		party := &camtParty{Name: truncate(line.CounterpartyOwner.String, camtMax140Text)}
+		account := &camtCounterpartyAccount{ID: fmt.Sprint(line.CounterpartyAccountID.Int64)}
+		if line.Amount < 0 {
+			entry.Details.RelatedParties = &camtRelatedParties{Creditor: party, CreditorAccount: account}
+		} else {
+			entry.Details.RelatedParties = &camtRelatedParties{Debtor: party, DebtorAccount: account}
+		}
+	}
+	return writer.enc.Encode(entry)
+}
+
+func (writer *camtWriter) End(statement Statement) error {
+	for _, name := range []string{"Stmt", "BkToCstmrStmt", "Document"} {
+		if err := writer.enc.EncodeToken(xml.EndElement{Name: xml.Name{Local: name}}); err != nil {
+			return err
+		}
+	}
+	if err := writer.enc.Flush(); err != nil {
+		return err
+	}
+	if _, err := writer.w.WriteString("\n"); err != nil {
+		return err
+	}
+	return writer.w.Flush()
+}
+
+// camtStart returns the start of an element without a namespace of its own
+func camtStart(name string) xml.StartElement {
+	return xml.StartElement{Name: xml.Name{Local: name}}
+}
+
+// camtBalanceOf returns a booked balance of the account at the end of day
+func camtBalanceOf(code string, balance int64, currency string, day time.Time) camtBalance {
+	amount, indicator := camtSigned(balance, currency)
+	return camtBalance{
+		Code:                 code,
+		Amount:               camtAmount{Currency: currency, Value: amount},
+		CreditDebitIndicator: indicator,
+		Date:                 day.Format(bankDateLayout),
+	}
+}
+
+// camtSigned splits an amount in the unsigned amount the schema wants and whether it is a credit or a debit
+func camtSigned(amount int64, currency string) (string, string) {
+	if amount < 0 {
+		return money.Format(-amount, currency), camtDebit
+	}
+	return money.Format(amount, currency), camtCredit
+}
+
+// camtDateTime formats a time recorded on the bank's clock with its offset
+func camtDateTime(t time.Time) string {
+	return inBankZone(t).Format("2006-01-02T15:04:05-07:00")
+}
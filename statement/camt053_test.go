@@ -0,0 +1,200 @@
+package statement
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// camtSequence is a complex type of the camt.053.001.08 schema, the children it may have in order
+// and which of them are required, for the types a statement of the bank uses
+type camtSequence struct {
+	children []string
+	required []string
+}
+
+// camtSchema maps every element of a statement to its type in the schema, simple types have no entry
+// Names that have different types in different places are keyed by their parent as parent/name
+var camtSchema = map[string]camtSequence{
+	"Document":      {children: []string{"BkToCstmrStmt"}, required: []string{"BkToCstmrStmt"}},
+	"BkToCstmrStmt": {children: []string{"GrpHdr", "Stmt", "SplmtryData"}, required: []string{"GrpHdr", "Stmt"}},
+	"GrpHdr": {
+		children: []string{"MsgId", "CreDtTm", "MsgRcpt", "MsgPgntn", "OrgnlBizQry", "AddtlInf"},
+		required: []string{"MsgId", "CreDtTm"},
+	},
+	"Stmt": {
+		children: []string{"Id", "StmtPgntn", "ElctrncSeqNb", "RptgSeq", "LglSeqNb", "CreDtTm", "FrToDt", "CpyDplctInd",
+			"RptgSrc", "Acct", "RltdAcct", "Intrst", "Bal", "TxsSummry", "Ntry", "AddtlStmtInf"},
+		required: []string{"Id", "Acct", "Bal"},
+	},
+	"FrToDt":      {children: []string{"FrDtTm", "ToDtTm"}, required: []string{"FrDtTm", "ToDtTm"}},
+	"Acct":        {children: []string{"Id", "Tp", "Ccy", "Nm", "Prxy", "Ownr", "Svcr"}, required: []string{"Id"}},
+	"DbtrAcct":    {children: []string{"Id", "Tp", "Ccy", "Nm", "Prxy"}, required: []string{"Id"}},
+	"CdtrAcct":    {children: []string{"Id", "Tp", "Ccy", "Nm", "Prxy"}, required: []string{"Id"}},
+	"Acct/Id":     {children: []string{"IBAN", "Othr"}},
+	"DbtrAcct/Id": {children: []string{"IBAN", "Othr"}},
+	"CdtrAcct/Id": {children: []string{"IBAN", "Othr"}},
+	"Othr":        {children: []string{"Id", "SchmeNm", "Issr"}, required: []string{"Id"}},
+	"Ownr":        {children: []string{"Nm", "PstlAdr", "Id", "CtryOfRes", "CtctDtls"}},
+	"Bal":         {children: []string{"Tp", "CdtLine", "Amt", "CdtDbtInd", "Dt", "Avlbty"}, required: []string{"Tp", "Amt", "CdtDbtInd", "Dt"}},
+	"Bal/Tp":      {children: []string{"CdOrPrtry", "SubTp"}, required: []string{"CdOrPrtry"}},
+	"CdOrPrtry":   {children: []string{"Cd", "Prtry"}},
+	"Bal/Dt":      {children: []string{"Dt", "DtTm"}},
+	"BookgDt":     {children: []string{"Dt", "DtTm"}},
+	"ValDt":       {children: []string{"Dt", "DtTm"}},
+	"TxsSummry":   {children: []string{"TtlNtries", "TtlCdtNtries", "TtlDbtNtries", "TtlNtriesPerBkTxCd"}},
+	"TtlNtries":   {children: []string{"NbOfNtries", "Sum", "TtlNetNtry"}},
+	"TtlNetNtry":  {children: []string{"Amt", "CdtDbtInd"}, required: []string{"Amt", "CdtDbtInd"}},
+	"TtlCdtNtries": {
+		children: []string{"NbOfNtries", "Sum"},
+	},
+	"TtlDbtNtries": {
+		children: []string{"NbOfNtries", "Sum"},
+	},
+	"Ntry": {
+		children: []string{"NtryRef", "Amt", "CdtDbtInd", "RvslInd", "Sts", "BookgDt", "ValDt", "AcctSvcrRef", "Avlbty",
+			"BkTxCd", "ComssnWvrInd", "AddtlInfInd", "AmtDtls", "Chrgs", "TechInptChanl", "Intrst", "CardTx", "NtryDtls", "AddtlNtryInf"},
+		required: []string{"Amt", "CdtDbtInd", "Sts", "BkTxCd"},
+	},
+	"Sts":       {children: []string{"Cd", "Prtry"}},
+	"BkTxCd":    {children: []string{"Domn", "Prtry"}},
+	"Domn":      {children: []string{"Cd", "Fmly"}, required: []string{"Cd", "Fmly"}},
+	"Fmly":      {children: []string{"Cd", "SubFmlyCd"}, required: []string{"Cd", "SubFmlyCd"}},
+	"NtryDtls":  {children: []string{"Btch", "TxDtls"}},
+	"TxDtls":    {children: []string{"Refs", "Amt", "CdtDbtInd", "AmtDtls", "Avlbty", "BkTxCd", "Chrgs", "Intrst", "RltdPties", "RltdAgts", "LclInstrm", "Purp", "RltdRmtInf", "RmtInf"}},
+	"Refs":      {children: []string{"MsgId", "AcctSvcrRef", "PmtInfId", "InstrId", "EndToEndId", "UETR", "TxId"}},
+	"RltdPties": {children: []string{"InitgPty", "Dbtr", "DbtrAcct", "UltmtDbtr", "Cdtr", "CdtrAcct", "UltmtCdtr", "TradgPty", "Prtry"}},
+	"Dbtr":      {children: []string{"Pty", "Agt"}},
+	"Cdtr":      {children: []string{"Pty", "Agt"}},
+	"Pty":       {children: []string{"Nm", "PstlAdr", "Id", "CtryOfRes", "CtctDtls"}},
+	"RmtInf":    {children: []string{"Ustrd", "Strd"}},
+}
+
+// camtMaxLengths are the lengths of the text elements of a statement in the schema
+var camtMaxLengths = map[string]int{"MsgId": 35, "NtryRef": 35, "AcctSvcrRef": 35, "EndToEndId": 35, "Nm": 140, "Ustrd": 140}
+
+// camtElement is an element of a decoded document
+type camtElement struct {
+	name     string
+	children []*camtElement
+	text     string
+}
+
+// parseCamt decodes a document into a tree of its elements
+func parseCamt(t *testing.T, document string) *camtElement {
+	decoder := xml.NewDecoder(strings.NewReader(document))
+	root := &camtElement{}
+	stack := []*camtElement{root}
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			require.Equal(t, "EOF", err.Error())
+			break
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			if token.Name.Local == "Document" {
+				require.Equal(t, camtNamespace, token.Name.Space)
+			}
+			element := &camtElement{name: token.Name.Local}
+			parent := stack[len(stack)-1]
+			parent.children = append(parent.children, element)
+			stack = append(stack, element)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			stack[len(stack)-1].text += strings.TrimSpace(string(token))
+		}
+	}
+	require.Len(t, root.children, 1)
+	return root.children[0]
+}
+
+// requireCamtSchema checks the children of element and all below it against the sequences of the schema
+func requireCamtSchema(t *testing.T, element *camtElement, path string) {
+	parent := path[strings.LastIndex(path, "/")+1:]
+	path += "/" + element.name
+	if max, ok := camtMaxLengths[element.name]; ok {
+		require.LessOrEqual(t, len([]rune(element.text)), max, path)
+	}
+
+	sequence, ok := camtSchema[parent+"/"+element.name]
+	if !ok {
+		sequence, ok = camtSchema[element.name]
+	}
+	if !ok {
+		require.Empty(t, element.children, "%s is a simple type", path)
+		require.NotEmpty(t, element.text, path)
+		return
+	}
+
+	require.NotEmpty(t, element.children, "%s has no children", path)
+	position := 0
+	seen := map[string]bool{}
+	for _, child := range element.children {
+		index := -1
+		for i, name := range sequence.children[position:] {
+			if name == child.name {
+				index = position + i
+				break
+			}
+		}
+		require.NotEqual(t, -1, index, "%s/%s is not allowed there", path, child.name)
+		position = index
+		seen[child.name] = true
+		requireCamtSchema(t, child, path)
+	}
+	for _, name := range sequence.required {
+		require.True(t, seen[name], "%s misses %s", path, name)
+	}
+}
+
+func TestGenerateCamt053(t *testing.T) {
+	store := newFakeStore()
+	store.entries[1].Description.String = strings.Repeat("x", 200)
+	store.entries[1].Description.Valid = true
+	out := generate(t, store, FormatCamt053)
+
+	document := parseCamt(t, out)
+	requireCamtSchema(t, document, "")
+
+	require.Contains(t, out, `<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.08">`)
+	require.Contains(t, out, "<MsgId>7-240301-240331</MsgId>")
+	require.Contains(t, out, "<CreDtTm>2024-04-01T12:00:00+04:00</CreDtTm>")
+	require.Contains(t, out, "<FrDtTm>2024-03-01T00:00:00+04:00</FrDtTm>")
+	require.Contains(t, out, "<ToDtTm>2024-03-31T23:59:59+04:00</ToDtTm>")
+
+	stmt := document.children[0].children[1]
+	require.Equal(t, "Stmt", stmt.name)
+	var entries []*camtElement
+	for _, child := range stmt.children {
+		if child.name == "Ntry" {
+			entries = append(entries, child)
+		}
+	}
+	require.Len(t, entries, 2)
+
+	// the counterparty of a credit is its debtor
+	require.Contains(t, out, "<Dbtr>\n                <Pty>\n                  <Nm>bob &amp; &lt;sons&gt;</Nm>")
+	require.Contains(t, out, "<EndToEndId>INV-1</EndToEndId>")
+	require.Contains(t, out, `<Amt Ccy="USD">5.00</Amt>`)
+	require.Contains(t, out, "<Cd>ICDT</Cd>")
+	require.Contains(t, out, "<Cd>CLBD</Cd>")
+}
+
+func TestGenerateCamt053NegativeBalance(t *testing.T) {
+	store := newFakeStore()
+	store.summary.OpeningBalance = -10000
+	store.summary.ClosingBalance = -8000
+
+	document := parseCamt(t, generate(t, store, FormatCamt053))
+	requireCamtSchema(t, document, "")
+
+	for _, child := range document.children[0].children[1].children {
+		if child.name == "Bal" {
+			require.Equal(t, "DBIT", child.children[2].text)
+		}
+	}
+}
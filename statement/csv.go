@@ -0,0 +1,83 @@
+package statement
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/T-BO0/bank/util/money"
+)
+
+// csvTimeLayout is the layout of the times in a csv statement, on the bank's clock like the entries
+const csvTimeLayout = "2006-01-02 15:04:05"
+
+// csvHeader are the columns of a csv statement
+var csvHeader = []string{
+	"date", "direction", "entry_id", "transfer_id", "description", "reference",
+	"counterparty_account_id", "counterparty_owner", "amount", "balance",
+}
+
+// csvWriter writes a statement as one row per entry, between an opening and a closing balance row
+type csvWriter struct {
+	w        *csv.Writer
+	currency string
+}
+
+func newCSVWriter(w io.Writer) Writer {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (writer *csvWriter) Begin(statement Statement) error {
+	writer.currency = statement.Currency
+	if err := writer.w.Write(csvHeader); err != nil {
+		return err
+	}
+	return writer.balanceRow(statement.From.Format(bankDateLayout), "Opening balance", statement.OpeningBalance, statement.Currency)
+}
+
+func (writer *csvWriter) Line(line Line) error {
+	currency := writer.currency
+	return writer.w.Write([]string{
+		line.CreatedAt.Format(csvTimeLayout),
+		direction(line.Amount),
+		strconv.FormatInt(line.ID, 10),
+		nullInt(line.TransferID.Int64, line.TransferID.Valid),
+		csvText(line.Description.String),
+		csvText(line.Reference.String),
+		nullInt(line.CounterpartyAccountID.Int64, line.CounterpartyAccountID.Valid),
+		csvText(line.CounterpartyOwner.String),
+		money.Format(line.Amount, currency),
+		money.Format(line.BalanceAfter, currency),
+	})
+}
+
+func (writer *csvWriter) End(statement Statement) error {
+	if err := writer.balanceRow(statement.To.Format(bankDateLayout), "Closing balance", statement.ClosingBalance, statement.Currency); err != nil {
+		return err
+	}
+	writer.w.Flush()
+	return writer.w.Error()
+}
+
+// balanceRow writes a row that only has a balance, without an entry
+func (writer *csvWriter) balanceRow(date string, description string, balance int64, currency string) error {
+	return writer.w.Write([]string{date, "", "", "", description, "", "", "", "", money.Format(balance, currency)})
+}
+
+// csvText keeps a text field from being read as a formula by spreadsheets, which run cells starting with one of
+// = + - @ or a tab or carriage return. Such a field gets a leading quote, which spreadsheets show as text.
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// nullInt formats n, or returns an empty string when it is not valid
+func nullInt(n int64, valid bool) string {
+	if !valid {
+		return ""
+	}
+	return strconv.FormatInt(n, 10)
+}
@@ -0,0 +1,122 @@
+package statement
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/T-BO0/bank/util/money"
+)
+
+// jsonHeader is the part of a json statement before its entries, amounts are formatted in the account currency
+type jsonHeader struct {
+	AccountID      int64     `json:"account_id"`
+	Owner          string    `json:"owner"`
+	AccountType    string    `json:"account_type"`
+	Currency       string    `json:"currency"`
+	From           string    `json:"from"`
+	To             string    `json:"to"`
+	OpeningBalance string    `json:"opening_balance"`
+	ClosingBalance string    `json:"closing_balance"`
+	TotalDebits    string    `json:"total_debits"`
+	TotalCredits   string    `json:"total_credits"`
+	EntryCount     int64     `json:"entry_count"`
+	GeneratedAt    time.Time `json:"generated_at"`
+}
+
+// jsonCounterparty is the other account of the transfer an entry belongs to
+type jsonCounterparty struct {
+	AccountID int64  `json:"account_id"`
+	Owner     string `json:"owner"`
+}
+
+// jsonEntry is one entry of a json statement, the same as an entry in the history of an account
+type jsonEntry struct {
+	ID           int64             `json:"id"`
+	Direction    string            `json:"direction"`
+	Amount       string            `json:"amount"`
+	BalanceAfter string            `json:"balance_after"`
+	CreatedAt    time.Time         `json:"created_at"`
+	TransferID   *int64            `json:"transfer_id,omitempty"`
+	Description  string            `json:"description,omitempty"`
+	Reference    string            `json:"reference,omitempty"`
+	Counterparty *jsonCounterparty `json:"counterparty,omitempty"`
+}
+
+// jsonWriter writes a statement as one object with the header fields and an entries array,
+// which is written one entry at a time instead of being marshaled as a whole
+type jsonWriter struct {
+	w        *bufio.Writer
+	currency string
+	entries  int
+}
+
+func newJSONWriter(w io.Writer) Writer {
+	return &jsonWriter{w: bufio.NewWriter(w)}
+}
+
+func (writer *jsonWriter) Begin(statement Statement) error {
+	writer.currency = statement.Currency
+	header, err := json.Marshal(jsonHeader{
+		AccountID:      statement.AccountID,
+		Owner:          statement.Owner,
+		AccountType:    statement.AccountType,
+		Currency:       statement.Currency,
+		From:           statement.From.Format(bankDateLayout),
+		To:             statement.To.Format(bankDateLayout),
+		OpeningBalance: money.Format(statement.OpeningBalance, statement.Currency),
+		ClosingBalance: money.Format(statement.ClosingBalance, statement.Currency),
+		TotalDebits:    money.Format(statement.TotalDebits, statement.Currency),
+		TotalCredits:   money.Format(statement.TotalCredits, statement.Currency),
+		EntryCount:     statement.EntryCount,
+		GeneratedAt:    statement.GeneratedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	// the header is an object, its closing brace makes room for the entries
+	writer.w.Write(header[:len(header)-1])
+	_, err = writer.w.WriteString(`,"entries":[`)
+	return err
+}
+
+func (writer *jsonWriter) Line(line Line) error {
+	entry := jsonEntry{
+		ID:           line.ID,
+		Direction:    direction(line.Amount),
+		Amount:       money.Format(line.Amount, writer.currency),
+		BalanceAfter: money.Format(line.BalanceAfter, writer.currency),
+		CreatedAt:    line.CreatedAt,
+		Description:  line.Description.String,
+		Reference:    line.Reference.String,
+	}
+	if line.TransferID.Valid {
+		entry.TransferID = &line.TransferID.Int64
+	}
+	if line.CounterpartyAccountID.Valid {
+		entry.Counterparty = &jsonCounterparty{
+			AccountID: line.CounterpartyAccountID.Int64,
+			Owner:     line.CounterpartyOwner.String,
+		}
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if writer.entries > 0 {
+		writer.w.WriteByte(',')
+	}
+	writer.entries++
+	_, err = writer.w.Write(data)
+	return err
+}
+
+func (writer *jsonWriter) End(statement Statement) error {
+	if _, err := writer.w.WriteString("]}\n"); err != nil {
+		return err
+	}
+	return writer.w.Flush()
+}
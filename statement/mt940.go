@@ -0,0 +1,146 @@
+package statement
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/T-BO0/bank/util/money"
+)
+
+// Limits of the fields of an MT940 message
+const (
+	mt940ReferenceLength = 16
+	mt940LineLength      = 65
+	mt940InfoLines       = 6
+)
+
+// mt940NoReference is the customer reference of an entry without one
+const mt940NoReference = "NONREF"
+
+// mt940StatementID is the reference of a statement, the account and both days of the period in base 36,
+// days counted from 1970-01-01. statementID doesn't fit the 16 characters of the field, this stays unique per
+// account and period and fits them for any account below 36^8.
+func mt940StatementID(statement Statement) string {
+	return strings.ToUpper(strconv.FormatInt(statement.AccountID, 36) + "-" + mt940Day(statement.From) + "-" + mt940Day(statement.To))
+}
+
+// mt940Day is the number of days from 1970-01-01 to t in base 36
+func mt940Day(t time.Time) string {
+	return strconv.FormatInt(t.Unix()/(24*60*60), 36)
+}
+
+// mt940Charset is the SWIFT x character set, anything else is written as a dot
+const mt940Charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789/-?:().,'+ "
+
+// mt940Writer writes a statement as the text block of one SWIFT MT940 customer statement message,
+// which is what ERP systems read from a statement file. Lines end in CRLF as SWIFT wants.
+type mt940Writer struct {
+	w        *bufio.Writer
+	currency string
+}
+
+func newMT940Writer(w io.Writer) Writer {
+	return &mt940Writer{w: bufio.NewWriter(w)}
+}
+
+func (writer *mt940Writer) Begin(statement Statement) error {
+	writer.currency = statement.Currency
+	writer.field("20", mt940StatementID(statement))
+	writer.field("25", fmt.Sprint(statement.AccountID))
+	// the bank does not number statements, each one is the only page of its period
+	writer.field("28C", "1/1")
+	return writer.field("60F", mt940Balance(statement.OpeningBalance, statement.From, statement.Currency))
+}
+
+func (writer *mt940Writer) Line(line Line) error {
+	mark, amount := "C", line.Amount
+	if amount < 0 {
+		mark, amount = "D", -amount
+	}
+	reference := mt940NoReference
+	if line.Reference.Valid {
+		reference = truncate(mt940Text(line.Reference.String), mt940ReferenceLength)
+	}
+
+	// value date, entry date, mark, amount, a transfer, the reference of the customer and the one of the bank
+	err := writer.field("61", fmt.Sprintf("%s%s%s%sNTRF%s//%d",
+		line.CreatedAt.Format("060102"), line.CreatedAt.Format("0102"), mark, mt940Amount(amount, writer.currency), reference, line.ID))
+
+	var info []string
+	if line.Description.Valid {
+		info = append(info, line.Description.String)
+	}
+	if line.CounterpartyAccountID.Valid {
+		info = append(info, fmt.Sprintf("%s ACCOUNT %d", line.CounterpartyOwner.String, line.CounterpartyAccountID.Int64))
+	}
+	if line.Reference.Valid {
+		info = append(info, "REF "+line.Reference.String)
+	}
+	if err != nil || len(info) == 0 {
+		return err
+	}
+	return writer.field("86", strings.Join(mt940Lines(strings.Join(info, " / ")), "\r\n"))
+}
+
+func (writer *mt940Writer) End(statement Statement) error {
+	writer.field("62F", mt940Balance(statement.ClosingBalance, statement.To, statement.Currency))
+	if _, err := writer.w.WriteString("-\r\n"); err != nil {
+		return err
+	}
+	return writer.w.Flush()
+}
+
+// field writes a tagged field, bufio keeps the first error for the flush at the end
+func (writer *mt940Writer) field(tag string, value string) error {
+	_, err := fmt.Fprintf(writer.w, ":%s:%s\r\n", tag, value)
+	return err
+}
+
+// mt940Balance formats a balance field, the mark, the day, the currency and the unsigned amount
+func mt940Balance(balance int64, day time.Time, currency string) string {
+	mark := "C"
+	if balance < 0 {
+		mark, balance = "D", -balance
+	}
+	return mark + day.Format("060102") + currency + mt940Amount(balance, currency)
+}
+
+// mt940Amount formats an unsigned amount with the decimal comma SWIFT uses, which is there even without decimals
+func mt940Amount(amount int64, currency string) string {
+	s := strings.Replace(money.Format(amount, currency), ".", ",", 1)
+	if !strings.Contains(s, ",") {
+		s += ","
+	}
+	return s
+}
+
+// mt940Text replaces the characters SWIFT can't carry
+func mt940Text(s string) string {
+	return strings.Map(func(r rune) rune {
+		if !strings.ContainsRune(mt940Charset, r) {
+			return '.'
+		}
+		return r
+	}, s)
+}
+
+// mt940Lines wraps s into the lines of an information field, what does not fit in them is cut
+// A line may not start with : or - which would be read as the next field or the end of the message
+func mt940Lines(s string) []string {
+	s = mt940Text(s)
+	var lines []string
+	for s != "" && len(lines) < mt940InfoLines {
+		n := min(len(s), mt940LineLength)
+		line := s[:n]
+		if line[0] == ':' || line[0] == '-' {
+			line = "." + line[1:]
+		}
+		lines = append(lines, line)
+		s = s[n:]
+	}
+	return lines
+}
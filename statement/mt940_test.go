@@ -0,0 +1,70 @@
+package statement
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateMT940(t *testing.T) {
+	out := generate(t, newFakeStore(), FormatMT940)
+
+	require.True(t, strings.HasSuffix(out, "\r\n-\r\n"))
+	lines := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+	require.Equal(t, []string{
+		":20:7-F9J-FAD",
+		":25:7",
+		":28C:1/1",
+		":60F:C240301USD100,00",
+		":61:2403050305C25,00NTRFINV-1//1",
+		":86:.HYPERLINK(.http://x.) / bob . .sons. ACCOUNT 8 / REF INV-1",
+		":61:2403200320D5,00NTRFNONREF//2",
+		":62F:C240331USD120,00",
+		"-",
+	}, lines)
+
+	for _, line := range lines {
+		for _, r := range line {
+			require.True(t, strings.ContainsRune(mt940Charset, r), "%q is not in the SWIFT character set", r)
+		}
+	}
+}
+
+func TestGenerateMT940NegativeBalance(t *testing.T) {
+	store := newFakeStore()
+	store.summary.OpeningBalance = -10000
+	store.summary.ClosingBalance = -8000
+
+	out := generate(t, store, FormatMT940)
+	require.Contains(t, out, ":60F:D240301USD100,00\r\n")
+	require.Contains(t, out, ":62F:D240331USD80,00\r\n")
+}
+
+func TestMT940StatementID(t *testing.T) {
+	// two periods of a long account id that share the start
+	month := Statement{AccountID: 123456789, From: testFrom, To: testTo}
+	fortnight := Statement{AccountID: 123456789, From: testFrom, To: time.Date(2024, 3, 14, 0, 0, 0, 0, time.UTC)}
+
+	require.Equal(t, "21I3V9-F9J-FAD", mt940StatementID(month))
+	require.NotEqual(t, mt940StatementID(month), mt940StatementID(fortnight))
+	require.LessOrEqual(t, len(mt940StatementID(Statement{AccountID: 1<<41 - 1, From: testFrom, To: testTo})), mt940ReferenceLength)
+}
+
+func TestMT940Lines(t *testing.T) {
+	lines := mt940Lines(strings.Repeat("a", 65) + "-b" + strings.Repeat("c", 500))
+	require.Len(t, lines, mt940InfoLines)
+	for _, line := range lines {
+		require.LessOrEqual(t, len(line), mt940LineLength)
+	}
+	// the second line would start with a dash and end the message
+	require.Equal(t, ".", lines[1][:1])
+
+	require.Equal(t, []string{"caf. ..."}, mt940Lines("café €@#"))
+}
+
+func TestMT940Amount(t *testing.T) {
+	require.Equal(t, "0,05", mt940Amount(5, "USD"))
+	require.Equal(t, "1234,56", mt940Amount(123456, "EUR"))
+}
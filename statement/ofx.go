@@ -0,0 +1,150 @@
+package statement
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	db "github.com/T-BO0/bank/db/sqlc"
+	"github.com/T-BO0/bank/util/money"
+)
+
+// ofxTimeLayout is the layout of an OFX datetime
+const ofxTimeLayout = "20060102150405.000"
+
+// ofxTimeZone says an OFX datetime is on the bank's clock, in hours ahead of UTC
+var ofxTimeZone = fmt.Sprintf("[%+d]", int(db.BankClockOffset/time.Hour))
+
+// ofxBankID is the routing number OFX requires in an account reference, the bank has no other one
+const ofxBankID = "000000000"
+
+// ofxNameLength is the most characters the NAME of an OFX transaction can have
+const ofxNameLength = 32
+
+// ofxAccountTypes maps account types to the OFX account types
+var ofxAccountTypes = map[string]string{
+	db.AccountChecking:  "CHECKING",
+	db.AccountSavings:   "SAVINGS",
+	db.AccountOverdraft: "CREDITLINE",
+}
+
+// ofxWriter writes a statement as an OFX 2.2 bank statement response, which personal finance apps import
+type ofxWriter struct {
+	w        *bufio.Writer
+	currency string
+}
+
+func newOFXWriter(w io.Writer) Writer {
+	return &ofxWriter{w: bufio.NewWriter(w)}
+}
+
+func (writer *ofxWriter) Begin(statement Statement) error {
+	writer.currency = statement.Currency
+	accountType, ok := ofxAccountTypes[statement.AccountType]
+	if !ok {
+		accountType = "CHECKING"
+	}
+
+	_, err := fmt.Fprintf(writer.w, `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+<OFX>
+<SIGNONMSGSRSV1>
+<SONRS>
+<STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>
+<DTSERVER>%s</DTSERVER>
+<LANGUAGE>ENG</LANGUAGE>
+</SONRS>
+</SIGNONMSGSRSV1>
+<BANKMSGSRSV1>
+<STMTTRNRS>
+<TRNUID>0</TRNUID>
+<STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>
+<STMTRS>
+<CURDEF>%s</CURDEF>
+<BANKACCTFROM><BANKID>%s</BANKID><ACCTID>%d</ACCTID><ACCTTYPE>%s</ACCTTYPE></BANKACCTFROM>
+<BANKTRANLIST>
+<DTSTART>%s</DTSTART>
+<DTEND>%s</DTEND>
+`,
+		ofxTime(bankTime(statement.GeneratedAt)),
+		ofxText(statement.Currency),
+		ofxBankID,
+		statement.AccountID,
+		accountType,
+		ofxTime(statement.From),
+		ofxTime(statement.To.AddDate(0, 0, 1)),
+	)
+	return err
+}
+
+func (writer *ofxWriter) Line(line Line) error {
+	trnType := "CREDIT"
+	if line.Amount < 0 {
+		trnType = "DEBIT"
+	}
+
+	name := line.CounterpartyOwner.String
+	if name == "" {
+		name = line.Description.String
+	}
+	if name == "" {
+		name = strings.ToUpper(direction(line.Amount))
+	}
+	memo := line.Description.String
+	if line.Reference.Valid {
+		memo = strings.TrimSpace(memo + " " + line.Reference.String)
+	}
+
+	if _, err := fmt.Fprintf(writer.w, "<STMTTRN><TRNTYPE>%s</TRNTYPE><DTPOSTED>%s</DTPOSTED><TRNAMT>%s</TRNAMT><FITID>%d</FITID><NAME>%s</NAME>",
+		trnType, ofxTime(line.CreatedAt), money.Format(line.Amount, writer.currency), line.ID, ofxText(truncate(name, ofxNameLength))); err != nil {
+		return err
+	}
+	if memo != "" {
+		if _, err := fmt.Fprintf(writer.w, "<MEMO>%s</MEMO>", ofxText(memo)); err != nil {
+			return err
+		}
+	}
+	_, err := writer.w.WriteString("</STMTTRN>\n")
+	return err
+}
+
+func (writer *ofxWriter) End(statement Statement) error {
+	if _, err := fmt.Fprintf(writer.w, `</BANKTRANLIST>
+<LEDGERBAL><BALAMT>%s</BALAMT><DTASOF>%s</DTASOF></LEDGERBAL>
+</STMTRS>
+</STMTTRNRS>
+</BANKMSGSRSV1>
+</OFX>
+`,
+		money.Format(statement.ClosingBalance, statement.Currency),
+		ofxTime(statement.To.AddDate(0, 0, 1)),
+	); err != nil {
+		return err
+	}
+	return writer.w.Flush()
+}
+
+// ofxTime formats a time recorded on the bank's clock
+func ofxTime(t time.Time) string {
+	return t.Format(ofxTimeLayout) + ofxTimeZone
+}
+
+// ofxText escapes s for the content of an element
+func ofxText(s string) string {
+	var b strings.Builder
+	// writing to a strings.Builder never fails
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// truncate cuts s to at most n characters
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
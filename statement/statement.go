@@ -0,0 +1,174 @@
+// Package statement generates account statements from the entries of an account and writes them in several formats
+package statement
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/T-BO0/bank/db/dbx"
+	db "github.com/T-BO0/bank/db/sqlc"
+)
+
+// Formats a statement can be written in
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+	FormatOFX  = "ofx"
+	// FormatCamt053 is the ISO 20022 bank to customer statement
+	FormatCamt053 = "camt053"
+	// FormatMT940 is the SWIFT customer statement message
+	FormatMT940 = "mt940"
+)
+
+// Directions of a line, a debit takes money out of the account and a credit puts it in
+const (
+	Debit  = "debit"
+	Credit = "credit"
+)
+
+// bankDateLayout is the layout of the days of a period
+const bankDateLayout = "2006-01-02"
+
+// bankZone is the zone of the bank's clock, which entries are recorded in, for the formats that write the offset
+var bankZone = time.FixedZone("", int(db.BankClockOffset/time.Second))
+
+// ErrUnsupportedFormat is returned by NewWriter for a format that is not in formats
+var ErrUnsupportedFormat = errors.New("unsupported statement format")
+
+// format is how a statement is written in one of the formats
+type format struct {
+	contentType string
+	extension   string
+	newWriter   func(w io.Writer) Writer
+}
+
+// formats is the registry of statement formats by name
+var formats = map[string]format{
+	FormatCSV:     {contentType: "text/csv; charset=utf-8", extension: "csv", newWriter: newCSVWriter},
+	FormatJSON:    {contentType: "application/json; charset=utf-8", extension: "json", newWriter: newJSONWriter},
+	FormatOFX:     {contentType: "application/x-ofx", extension: "ofx", newWriter: newOFXWriter},
+	FormatCamt053: {contentType: "application/xml; charset=utf-8", extension: "xml", newWriter: newCamtWriter},
+	FormatMT940:   {contentType: "text/plain; charset=us-ascii", extension: "sta", newWriter: newMT940Writer},
+}
+
+// IsSupportedFormat reports whether a statement can be written in the format
+func IsSupportedFormat(name string) bool {
+	_, ok := formats[name]
+	return ok
+}
+
+// ContentType returns the media type of a statement written in the format
+func ContentType(name string) string {
+	return formats[name].contentType
+}
+
+// Extension returns the file extension of a statement written in the format
+func Extension(name string) string {
+	return formats[name].extension
+}
+
+// Statement is everything about a statement but its lines, amounts are in minor units of Currency
+// From and To are the first and the last day of the period on the bank's clock, both inclusive
+type Statement struct {
+	AccountID      int64
+	Owner          string
+	AccountType    string
+	Currency       string
+	From           time.Time
+	To             time.Time
+	OpeningBalance int64
+	ClosingBalance int64
+	TotalDebits    int64
+	TotalCredits   int64
+	EntryCount     int64
+	GeneratedAt    time.Time
+}
+
+// Line is an entry of a statement with the balance of the account right after it
+type Line struct {
+	dbx.StatementEntry
+	BalanceAfter int64
+}
+
+// Writer writes a statement as it is generated
+// Begin is called once before any line, Line once per entry oldest first and End once after the last line.
+// A Writer may buffer, only after End returns is everything written to the underlying writer.
+type Writer interface {
+	Begin(statement Statement) error
+	Line(line Line) error
+	End(statement Statement) error
+}
+
+// direction returns whether an entry of amount is a debit or a credit
+func direction(amount int64) string {
+	if amount < 0 {
+		return Debit
+	}
+	return Credit
+}
+
+// bankTime converts t to the bank's clock, on which entries and periods are recorded
+func bankTime(t time.Time) time.Time {
+	return t.UTC().Add(db.BankClockOffset)
+}
+
+// inBankZone gives a time recorded on the bank's clock the bank's zone, so it formats with its offset
+func inBankZone(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), bankZone)
+}
+
+// statementID identifies the statement of an account for a period, it fits the 35 characters formats allow for it
+func statementID(statement Statement) string {
+	return fmt.Sprintf("%d-%s-%s", statement.AccountID, statement.From.Format("060102"), statement.To.Format("060102"))
+}
+
+// NewWriter creates a Writer that writes a statement in the format to w
+func NewWriter(name string, w io.Writer) (Writer, error) {
+	f, ok := formats[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, name)
+	}
+	return f.newWriter(w), nil
+}
+
+// Store is the part of dbx.Store statements are read from
+// StatementTx checks the entries against the balances before it calls summary, so a statement that doesn't add up
+// fails with dbx.ErrStatementMismatch before anything of it is written
+type Store interface {
+	StatementTx(ctx context.Context, arg dbx.StatementParams, summary func(dbx.StatementSummary) error, entry func(dbx.StatementEntry) error) error
+}
+
+// Generate writes the statement of an account from the first day from until the last day to, both on the bank's clock
+// The entries are streamed from the store to w one at a time, so the length of the period does not matter,
+// and the running balance of each line is carried forward from the opening balance
+func Generate(ctx context.Context, store Store, accountID int64, from time.Time, to time.Time, now time.Time, w Writer) error {
+	statement := Statement{AccountID: accountID, From: from, To: to, GeneratedAt: now}
+	var balance int64
+
+	err := store.StatementTx(ctx, dbx.StatementParams{
+		AccountID: accountID,
+		FromTime:  from,
+		ToTime:    to.AddDate(0, 0, 1),
+	}, func(summary dbx.StatementSummary) error {
+		statement.Owner = summary.Account.Owner
+		statement.AccountType = summary.Account.Type
+		statement.Currency = summary.Account.Currency
+		statement.OpeningBalance = summary.OpeningBalance
+		statement.ClosingBalance = summary.ClosingBalance
+		statement.TotalDebits = summary.TotalDebits
+		statement.TotalCredits = summary.TotalCredits
+		statement.EntryCount = summary.EntryCount
+		balance = summary.OpeningBalance
+		return w.Begin(statement)
+	}, func(entry dbx.StatementEntry) error {
+		balance += entry.Amount
+		return w.Line(Line{StatementEntry: entry, BalanceAfter: balance})
+	})
+	if err != nil {
+		return err
+	}
+	return w.End(statement)
+}
@@ -0,0 +1,188 @@
+package statement
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/T-BO0/bank/db/dbx"
+	db "github.com/T-BO0/bank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStore serves one statement from memory
+type fakeStore struct {
+	summary dbx.StatementSummary
+	entries []dbx.StatementEntry
+	arg     dbx.StatementParams
+	err     error
+}
+
+func (store *fakeStore) StatementTx(ctx context.Context, arg dbx.StatementParams, summary func(dbx.StatementSummary) error, entry func(dbx.StatementEntry) error) error {
+	store.arg = arg
+	if store.err != nil {
+		return store.err
+	}
+	if err := summary(store.summary); err != nil {
+		return err
+	}
+	for _, e := range store.entries {
+		if err := entry(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var (
+	testFrom = time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	testTo   = time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+	testNow  = time.Date(2024, 4, 1, 8, 0, 0, 0, time.UTC)
+)
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		summary: dbx.StatementSummary{
+			Account: db.Account{ID: 7, Owner: "alice", Currency: "USD", Type: db.AccountSavings},
+			GetStatementBalancesRow: db.GetStatementBalancesRow{
+				OpeningBalance: 10000,
+				ClosingBalance: 12000,
+				TotalDebits:    500,
+				TotalCredits:   2500,
+				EntryCount:     2,
+			},
+		},
+		entries: []dbx.StatementEntry{
+			{
+				ID:                    1,
+				Amount:                2500,
+				CreatedAt:             time.Date(2024, 3, 5, 12, 30, 0, 0, time.UTC),
+				TransferID:            sql.NullInt64{Int64: 11, Valid: true},
+				Description:           sql.NullString{String: "=HYPERLINK(\"http://x\")", Valid: true},
+				Reference:             sql.NullString{String: "INV-1", Valid: true},
+				CounterpartyAccountID: sql.NullInt64{Int64: 8, Valid: true},
+				CounterpartyOwner:     sql.NullString{String: "bob & <sons>", Valid: true},
+			},
+			{
+				ID:        2,
+				Amount:    -500,
+				CreatedAt: time.Date(2024, 3, 20, 9, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+}
+
+func generate(t *testing.T, store *fakeStore, format string) string {
+	var buf bytes.Buffer
+	w, err := NewWriter(format, &buf)
+	require.NoError(t, err)
+	require.NoError(t, Generate(context.Background(), store, 7, testFrom, testTo, testNow, w))
+	return buf.String()
+}
+
+func TestGenerateCSV(t *testing.T) {
+	store := newFakeStore()
+	out := generate(t, store, FormatCSV)
+	require.Equal(t, dbx.StatementParams{AccountID: 7, FromTime: testFrom, ToTime: testTo.AddDate(0, 0, 1)}, store.arg)
+
+	records, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 5)
+	require.Equal(t, csvHeader, records[0])
+	require.Equal(t, []string{"2024-03-01", "", "", "", "Opening balance", "", "", "", "", "100.00"}, records[1])
+	require.Equal(t, []string{
+		"2024-03-05 12:30:00", Credit, "1", "11", "'=HYPERLINK(\"http://x\")", "INV-1", "8", "bob & <sons>", "25.00", "125.00",
+	}, records[2])
+	require.Equal(t, []string{"2024-03-20 09:00:00", Debit, "2", "", "", "", "", "", "-5.00", "120.00"}, records[3])
+	require.Equal(t, []string{"2024-03-31", "", "", "", "Closing balance", "", "", "", "", "120.00"}, records[4])
+}
+
+func TestGenerateJSON(t *testing.T) {
+	out := generate(t, newFakeStore(), FormatJSON)
+
+	var statement struct {
+		jsonHeader
+		Entries []jsonEntry `json:"entries"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &statement))
+	require.Equal(t, int64(7), statement.AccountID)
+	require.Equal(t, "alice", statement.Owner)
+	require.Equal(t, "2024-03-01", statement.From)
+	require.Equal(t, "2024-03-31", statement.To)
+	require.Equal(t, "100.00", statement.OpeningBalance)
+	require.Equal(t, "120.00", statement.ClosingBalance)
+	require.Equal(t, "5.00", statement.TotalDebits)
+	require.Equal(t, "25.00", statement.TotalCredits)
+	require.Equal(t, int64(2), statement.EntryCount)
+
+	require.Len(t, statement.Entries, 2)
+	require.Equal(t, "125.00", statement.Entries[0].BalanceAfter)
+	require.NotNil(t, statement.Entries[0].Counterparty)
+	require.Equal(t, "bob & <sons>", statement.Entries[0].Counterparty.Owner)
+	require.Equal(t, Debit, statement.Entries[1].Direction)
+	require.Equal(t, "120.00", statement.Entries[1].BalanceAfter)
+	require.Nil(t, statement.Entries[1].TransferID)
+}
+
+func TestGenerateJSONWithoutEntries(t *testing.T) {
+	store := newFakeStore()
+	store.entries = nil
+	store.summary.ClosingBalance = store.summary.OpeningBalance
+
+	var statement map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal([]byte(generate(t, store, FormatJSON)), &statement))
+	require.JSONEq(t, "[]", string(statement["entries"]))
+}
+
+func TestGenerateOFX(t *testing.T) {
+	out := generate(t, newFakeStore(), FormatOFX)
+
+	// the body after the processing instructions is well formed xml
+	body := out[strings.Index(out, "<OFX>"):]
+	decoder := xml.NewDecoder(strings.NewReader(body))
+	for {
+		_, err := decoder.Token()
+		if err != nil {
+			require.Equal(t, "EOF", err.Error())
+			break
+		}
+	}
+
+	require.Contains(t, out, "<ACCTID>7</ACCTID><ACCTTYPE>SAVINGS</ACCTTYPE>")
+	require.Contains(t, out, "<DTSTART>20240301000000.000[+4]</DTSTART>")
+	require.Contains(t, out, "<DTEND>20240401000000.000[+4]</DTEND>")
+	require.Contains(t, out, "<TRNTYPE>CREDIT</TRNTYPE><DTPOSTED>20240305123000.000[+4]</DTPOSTED><TRNAMT>25.00</TRNAMT><FITID>1</FITID>")
+	require.Contains(t, out, "<NAME>bob &amp; &lt;sons&gt;</NAME>")
+	require.Contains(t, out, "<TRNTYPE>DEBIT</TRNTYPE>")
+	require.Contains(t, out, "<TRNAMT>-5.00</TRNAMT>")
+	require.Contains(t, out, "<LEDGERBAL><BALAMT>120.00</BALAMT>")
+}
+
+func TestGenerateBalanceMismatch(t *testing.T) {
+	store := newFakeStore()
+	store.err = dbx.ErrStatementMismatch
+
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatJSON, &buf)
+	require.NoError(t, err)
+	require.ErrorIs(t, Generate(context.Background(), store, 7, testFrom, testTo, testNow, w), dbx.ErrStatementMismatch)
+	require.Zero(t, buf.Len())
+}
+
+func TestNewWriterUnsupportedFormat(t *testing.T) {
+	_, err := NewWriter("pdf", &bytes.Buffer{})
+	require.ErrorIs(t, err, ErrUnsupportedFormat)
+	require.False(t, IsSupportedFormat("pdf"))
+	require.True(t, IsSupportedFormat(FormatOFX))
+}
+
+func TestTruncate(t *testing.T) {
+	require.Equal(t, "abc", truncate("abc", 32))
+	require.Equal(t, "ანბ", truncate("ანბანი", 3))
+}
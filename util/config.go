@@ -1,11 +1,23 @@
 package util
 
-import "github.com/spf13/viper"
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
 
 type Config struct {
-	DBDriver      string `mapstructure:"DB_DRIVER"`
-	DBSource      string `mapstructure:"DB_SOURCE"`
-	ServerAddress string `mapstructure:"SERVER_ADDRESS"`
+	DBDriver               string        `mapstructure:"DB_DRIVER"`
+	DBSource               string        `mapstructure:"DB_SOURCE"`
+	ServerAddress          string        `mapstructure:"SERVER_ADDRESS"`
+	TokenSymmetricKey      string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
+	AccessTokenDuration    time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	FXRatesFile            string        `mapstructure:"FX_RATES_FILE"`
+	OperatorAPIKey         string        `mapstructure:"OPERATOR_API_KEY"`
+	ReconciliationInterval time.Duration `mapstructure:"RECONCILIATION_INTERVAL"`
+	SchedulerInterval      time.Duration `mapstructure:"SCHEDULER_INTERVAL"`
+	HoldExpiryInterval     time.Duration `mapstructure:"HOLD_EXPIRY_INTERVAL"`
+	InterestInterval       time.Duration `mapstructure:"INTEREST_INTERVAL"`
 }
 
 func LoadConfig(path string) (config Config, err error) {
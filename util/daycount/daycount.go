@@ -0,0 +1,43 @@
+// Package daycount implements the day count conventions interest is accrued with
+//
+// A convention turns a day into the fraction of a year the annual rate applies to:
+//
+//	ACT/360    every day is 1/360 of a year, the money market convention of USD and EUR
+//	ACT/365F   every day is 1/365 of a year, leap years included
+package daycount
+
+import (
+	"math/big"
+)
+
+// Convention is a day count convention named as in the ISDA definitions
+type Convention string
+
+// Supported conventions
+const (
+	Actual360      Convention = "ACT/360"
+	Actual365Fixed Convention = "ACT/365F"
+)
+
+// currencyConventions maps a currency to the convention its deposits accrue with
+var currencyConventions = map[string]Convention{
+	"USD": Actual360,
+	"EUR": Actual360,
+	"GEL": Actual365Fixed,
+}
+
+// ForCurrency returns the convention of the currency, ACT/365F for currencies without one
+func ForCurrency(currency string) Convention {
+	if convention, ok := currencyConventions[currency]; ok {
+		return convention
+	}
+	return Actual365Fixed
+}
+
+// DayFraction returns the fraction of a year a single day is worth
+func (c Convention) DayFraction() *big.Rat {
+	if c == Actual360 {
+		return big.NewRat(1, 360)
+	}
+	return big.NewRat(1, 365)
+}
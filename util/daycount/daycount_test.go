@@ -0,0 +1,32 @@
+package daycount
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDayFraction(t *testing.T) {
+	testCases := []struct {
+		name       string
+		convention Convention
+		want       *big.Rat
+	}{
+		{name: "Actual360", convention: Actual360, want: big.NewRat(1, 360)},
+		{name: "Actual365Fixed", convention: Actual365Fixed, want: big.NewRat(1, 365)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Zero(t, tc.want.Cmp(tc.convention.DayFraction()))
+		})
+	}
+}
+
+func TestForCurrency(t *testing.T) {
+	require.Equal(t, Actual360, ForCurrency("USD"))
+	require.Equal(t, Actual360, ForCurrency("EUR"))
+	require.Equal(t, Actual365Fixed, ForCurrency("GEL"))
+	require.Equal(t, Actual365Fixed, ForCurrency("JPY"))
+}
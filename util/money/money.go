@@ -0,0 +1,158 @@
+// Package money converts between exact integer minor units and decimal strings
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Different types of error returned by the Parse function
+var (
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+	ErrInvalidAmount       = errors.New("invalid amount")
+	ErrInvalidRate         = errors.New("invalid rate")
+)
+
+// exponents holds the number of minor unit digits for every supported currency (ISO 4217)
+var exponents = map[string]int{
+	"USD": 2,
+	"EUR": 2,
+	"GEL": 2,
+}
+
+// IsSupportedCurrency reports whether currency can be parsed and formatted
+func IsSupportedCurrency(currency string) bool {
+	_, ok := exponents[currency]
+	return ok
+}
+
+// Exponent returns the number of minor unit digits of the currency
+func Exponent(currency string) (int, error) {
+	exp, ok := exponents[currency]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnsupportedCurrency, currency)
+	}
+	return exp, nil
+}
+
+// Parse converts a decimal string like "12.34" to minor units of the currency (1234 for USD)
+// It never goes through float arithmetic and rejects more fraction digits than the currency has
+func Parse(amount string, currency string) (int64, error) {
+	exp, err := Exponent(currency)
+	if err != nil {
+		return 0, err
+	}
+
+	s := strings.TrimSpace(amount)
+	negative := false
+	if strings.HasPrefix(s, "-") {
+		negative = true
+		s = s[1:]
+	}
+
+	whole, fraction, hasDot := strings.Cut(s, ".")
+	if whole == "" || (hasDot && fraction == "") || len(fraction) > exp {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidAmount, amount)
+	}
+	if !isDigits(whole) || !isDigits(fraction) {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidAmount, amount)
+	}
+
+	fraction += strings.Repeat("0", exp-len(fraction))
+	minor, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidAmount, amount)
+	}
+
+	if negative {
+		minor = -minor
+	}
+	return minor, nil
+}
+
+// Format converts minor units of the currency to a decimal string like "12.34"
+func Format(amount int64, currency string) string {
+	exp, ok := exponents[currency]
+	if !ok || exp == 0 {
+		return strconv.FormatInt(amount, 10)
+	}
+
+	sign := ""
+	digits := strconv.FormatInt(amount, 10)
+	if amount < 0 {
+		sign = "-"
+		digits = digits[1:]
+	}
+
+	if len(digits) <= exp {
+		digits = strings.Repeat("0", exp-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exp] + "." + digits[len(digits)-exp:]
+}
+
+// Convert converts minor units of from currency to minor units of to currency
+// rate is a decimal string of to units per one from unit, the result is rounded half away from zero
+func Convert(amount int64, from string, to string, rate string) (int64, error) {
+	fromExp, err := Exponent(from)
+	if err != nil {
+		return 0, err
+	}
+	toExp, err := Exponent(to)
+	if err != nil {
+		return 0, err
+	}
+
+	r, ok := new(big.Rat).SetString(rate)
+	if !ok || r.Sign() <= 0 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidRate, rate)
+	}
+
+	converted := new(big.Rat).SetInt64(amount)
+	converted.Mul(converted, r)
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(toExp-fromExp))), nil)
+	if toExp >= fromExp {
+		converted.Mul(converted, new(big.Rat).SetInt(scale))
+	} else {
+		converted.Quo(converted, new(big.Rat).SetInt(scale))
+	}
+
+	// round half away from zero: trunc(x + sign(x) / 2)
+	half := big.NewRat(int64(converted.Sign()), 2)
+	converted.Add(converted, half)
+	result := new(big.Int).Quo(converted.Num(), converted.Denom())
+	if !result.IsInt64() {
+		return 0, fmt.Errorf("%w: %d %s at rate %s overflows", ErrInvalidAmount, amount, from, rate)
+	}
+	return result.Int64(), nil
+}
+
+// RateScale is the number of fraction digits rates are stored with
+const RateScale = 10
+
+// InvertRate returns 1/rate rounded to RateScale fraction digits
+func InvertRate(rate string) (string, error) {
+	r, ok := new(big.Rat).SetString(rate)
+	if !ok || r.Sign() <= 0 {
+		return "", fmt.Errorf("%w: %q", ErrInvalidRate, rate)
+	}
+	return new(big.Rat).Inv(r).FloatString(RateScale), nil
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,106 @@
+package money
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		amount   string
+		currency string
+		want     int64
+		wantErr  error
+	}{
+		{amount: "12.34", currency: "USD", want: 1234},
+		{amount: "12.3", currency: "EUR", want: 1230},
+		{amount: "12", currency: "GEL", want: 1200},
+		{amount: "0.01", currency: "USD", want: 1},
+		{amount: "-5.50", currency: "USD", want: -550},
+		{amount: "92233720368547758.07", currency: "USD", want: math.MaxInt64},
+		{amount: "12.345", currency: "USD", wantErr: ErrInvalidAmount},
+		{amount: "12.", currency: "USD", wantErr: ErrInvalidAmount},
+		{amount: ".5", currency: "USD", wantErr: ErrInvalidAmount},
+		{amount: "1e3", currency: "USD", wantErr: ErrInvalidAmount},
+		{amount: "+1", currency: "USD", wantErr: ErrInvalidAmount},
+		{amount: "", currency: "USD", wantErr: ErrInvalidAmount},
+		{amount: "92233720368547758.08", currency: "USD", wantErr: ErrInvalidAmount},
+		{amount: "1.00", currency: "FUT", wantErr: ErrUnsupportedCurrency},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.amount+tc.currency, func(t *testing.T) {
+			got, err := Parse(tc.amount, tc.currency)
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	require.Equal(t, "12.34", Format(1234, "USD"))
+	require.Equal(t, "0.05", Format(5, "EUR"))
+	require.Equal(t, "0.00", Format(0, "GEL"))
+	require.Equal(t, "-0.50", Format(-50, "USD"))
+	require.Equal(t, "-123.00", Format(-12300, "USD"))
+	require.Equal(t, "42", Format(42, "FUT"))
+
+	for _, amount := range []int64{1, 99, 100, 123456789, -1, -100} {
+		got, err := Parse(Format(amount, "USD"), "USD")
+		require.NoError(t, err)
+		require.Equal(t, amount, got)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	testCases := []struct {
+		name    string
+		amount  int64
+		rate    string
+		want    int64
+		wantErr error
+	}{
+		{name: "Exact", amount: 1000, rate: "2.7", want: 2700},
+		{name: "RoundDown", amount: 1, rate: "0.4", want: 0},
+		{name: "RoundHalfUp", amount: 1, rate: "0.5", want: 1},
+		{name: "LongRate", amount: 12345, rate: "2.6845123456", want: 33140},
+		{name: "Negative", amount: -1, rate: "0.5", want: -1},
+		{name: "ZeroRate", amount: 100, rate: "0", wantErr: ErrInvalidRate},
+		{name: "BadRate", amount: 100, rate: "abc", wantErr: ErrInvalidRate},
+		{name: "Overflow", amount: math.MaxInt64, rate: "2", wantErr: ErrInvalidAmount},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Convert(tc.amount, "USD", "GEL", tc.rate)
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+
+	_, err := Convert(100, "USD", "FUT", "1")
+	require.ErrorIs(t, err, ErrUnsupportedCurrency)
+}
+
+func TestInvertRate(t *testing.T) {
+	got, err := InvertRate("4")
+	require.NoError(t, err)
+	require.Equal(t, "0.2500000000", got)
+
+	got, err = InvertRate("3")
+	require.NoError(t, err)
+	require.Equal(t, "0.3333333333", got)
+
+	_, err = InvertRate("0")
+	require.ErrorIs(t, err, ErrInvalidRate)
+}
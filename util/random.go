@@ -8,9 +8,9 @@ import (
 
 const alphabets = "abcdefghijklmnopqrstuvwxyz"
 
-// RandomFloat generates a random float64 between min and max
-func RandomFloat(min, max int64) float64 {
-	return float64(float64(min)+float64(rand.Int64N(max-min+1))) * rand.Float64()
+// RandomInt generates a random int64 between min and max
+func RandomInt(min, max int64) int64 {
+	return min + rand.Int64N(max-min+1)
 }
 
 // RandomString generates a random string fo length n
@@ -31,9 +31,9 @@ func RandomOwner() string {
 	return RandomString(6)
 }
 
-// RandomMoney will generate a random money
-func RandomMoney() float64 {
-	return RandomFloat(1000, 10000)
+// RandomMoney will generate a random amount of money in minor units
+func RandomMoney() int64 {
+	return RandomInt(1000, 10000)
 }
 
 // RandomCurrency will generate a random currency
@@ -0,0 +1,204 @@
+// Package schedule parses recurrence rules of scheduled transfers and computes their next run
+//
+// A rule is one of
+//
+//	@once               runs a single time at its start
+//	@every <duration>   runs every duration, like "@every 24h", at least a minute
+//	@hourly, @daily, @weekly, @monthly, @yearly
+//	<min> <hour> <day of month> <month> <day of week>   a cron expression evaluated in UTC
+//
+// Cron fields take "*", numbers, ranges "a-b", lists "a,b" and steps "*/n" or "a-b/n".
+// As in cron, when both day fields are restricted a day matching either one runs.
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidRule is returned for rules that can not be parsed
+var ErrInvalidRule = errors.New("invalid schedule rule")
+
+// minInterval is the shortest interval of an @every rule
+const minInterval = time.Minute
+
+// searchLimit bounds how far ahead a cron rule is searched, "0 0 30 2 *" would never run
+const searchLimit = 5 * 366 * 24 * time.Hour
+
+// Schedule computes the runs of a rule
+type Schedule interface {
+	// Next returns the first run strictly after t, or the zero time if there is none
+	Next(t time.Time) time.Time
+}
+
+var macros = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+	"@yearly":  "0 0 1 1 *",
+}
+
+// Parse parses a rule
+func Parse(rule string) (Schedule, error) {
+	rule = strings.TrimSpace(rule)
+
+	if rule == "@once" {
+		return once{}, nil
+	}
+
+	if every, ok := strings.CutPrefix(rule, "@every "); ok {
+		interval, err := time.ParseDuration(strings.TrimSpace(every))
+		if err != nil || interval < minInterval {
+			return nil, fmt.Errorf("%w: %q needs a duration of at least %s", ErrInvalidRule, rule, minInterval)
+		}
+		return constantDelay(interval), nil
+	}
+
+	if expr, ok := macros[rule]; ok {
+		rule = expr
+	}
+	return parseCron(rule)
+}
+
+// once never runs again after its start
+type once struct{}
+
+func (once) Next(time.Time) time.Time {
+	return time.Time{}
+}
+
+// constantDelay runs every interval after its start
+type constantDelay time.Duration
+
+func (d constantDelay) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(d))
+}
+
+// field bounds in the order of a cron expression
+var fieldBounds = [5]struct{ min, max int }{
+	{0, 59}, // minute
+	{0, 23}, // hour
+	{1, 31}, // day of month
+	{1, 12}, // month
+	{0, 6},  // day of week, sunday is 0
+}
+
+// cron holds the allowed values of every field of a cron expression
+type cron struct {
+	minute, hour, dom, month, dow map[int]bool
+	domStar, dowStar              bool
+}
+
+func parseCron(rule string) (Schedule, error) {
+	fields := strings.Fields(rule)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: %q needs 5 cron fields", ErrInvalidRule, rule)
+	}
+
+	var sets [5]map[int]bool
+	for i, field := range fields {
+		set, err := parseField(field, fieldBounds[i].min, fieldBounds[i].max)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: %v", ErrInvalidRule, rule, err)
+		}
+		sets[i] = set
+	}
+
+	return &cron{
+		minute:  sets[0],
+		hour:    sets[1],
+		dom:     sets[2],
+		month:   sets[3],
+		dow:     sets[4],
+		domStar: strings.HasPrefix(fields[2], "*"),
+		dowStar: strings.HasPrefix(fields[4], "*"),
+	}, nil
+}
+
+// parseField parses a comma separated list of values, ranges and steps within min and max
+func parseField(field string, min int, max int) (map[int]bool, error) {
+	set := map[int]bool{}
+	for _, part := range strings.Split(field, ",") {
+		expr, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepStr)
+			if err != nil || step <= 0 {
+				return nil, fmt.Errorf("invalid step %q", part)
+			}
+		}
+
+		lo, hi := min, max
+		if expr != "*" {
+			loStr, hiStr, isRange := strings.Cut(expr, "-")
+			var err error
+			lo, err = strconv.Atoi(loStr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q", part)
+			}
+			hi = lo
+			if isRange {
+				hi, err = strconv.Atoi(hiStr)
+				if err != nil {
+					return nil, fmt.Errorf("invalid range %q", part)
+				}
+			} else if hasStep {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return nil, fmt.Errorf("%q is out of range %d-%d", part, min, max)
+		}
+
+		for v := lo; v <= hi; v += step {
+			set[v] = true
+		}
+	}
+	return set, nil
+}
+
+// Next walks forward from the minute after t, skipping whole months, days and hours that can not match
+func (c *cron) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(searchLimit)
+
+	for t.Before(limit) {
+		if !c.month[int(t.Month())] {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !c.hour[t.Hour()] {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if !c.minute[t.Minute()] {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (c *cron) dayMatches(t time.Time) bool {
+	dom := c.dom[t.Day()]
+	dow := c.dow[int(t.Weekday())]
+	switch {
+	case c.domStar && c.dowStar:
+		return true
+	case c.domStar:
+		return dow
+	case c.dowStar:
+		return dom
+	}
+	return dom || dow
+}
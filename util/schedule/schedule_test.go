@@ -0,0 +1,75 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseInvalid(t *testing.T) {
+	rules := []string{
+		"",
+		"@every",
+		"@every 10s",
+		"@every soon",
+		"@fortnightly",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 7",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+	}
+
+	for _, rule := range rules {
+		_, err := Parse(rule)
+		require.ErrorIs(t, err, ErrInvalidRule, rule)
+	}
+}
+
+func TestNext(t *testing.T) {
+	start := time.Date(2024, time.January, 31, 10, 30, 0, 0, time.UTC)
+
+	testCases := []struct {
+		rule string
+		want time.Time
+	}{
+		{rule: "@once", want: time.Time{}},
+		{rule: "@every 24h", want: start.Add(24 * time.Hour)},
+		{rule: "@hourly", want: time.Date(2024, time.January, 31, 11, 0, 0, 0, time.UTC)},
+		{rule: "@daily", want: time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{rule: "@monthly", want: time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{rule: "@yearly", want: time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		// 2024-01-31 is a wednesday
+		{rule: "@weekly", want: time.Date(2024, time.February, 4, 0, 0, 0, 0, time.UTC)},
+		// rent on the 1st at 9:00
+		{rule: "0 9 1 * *", want: time.Date(2024, time.February, 1, 9, 0, 0, 0, time.UTC)},
+		{rule: "*/15 * * * *", want: time.Date(2024, time.January, 31, 10, 45, 0, 0, time.UTC)},
+		{rule: "0 8-17/3 * * 1-5", want: time.Date(2024, time.January, 31, 11, 0, 0, 0, time.UTC)},
+		// leap day
+		{rule: "0 0 29 2 *", want: time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		// either day field matches when both are restricted: the 15th or a monday
+		{rule: "0 0 15 * 1", want: time.Date(2024, time.February, 5, 0, 0, 0, 0, time.UTC)},
+		{rule: "0 0 30 2 *", want: time.Time{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.rule, func(t *testing.T) {
+			schedule, err := Parse(tc.rule)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, schedule.Next(start))
+		})
+	}
+}
+
+func TestNextIsStrictlyAfter(t *testing.T) {
+	schedule, err := Parse("0 9 1 * *")
+	require.NoError(t, err)
+
+	run := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+	require.Equal(t, time.Date(2024, time.April, 1, 9, 0, 0, 0, time.UTC), schedule.Next(run))
+}
@@ -0,0 +1,57 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	db "github.com/T-BO0/bank/db/sqlc"
+)
+
+// holdExpiryBatchSize bounds the holds released per tick, the rest wait for the next one
+const holdExpiryBatchSize = 100
+
+// HoldExpirer releases expired authorized holds on a fixed interval
+type HoldExpirer struct {
+	store    db.Store
+	interval time.Duration
+	now      func() time.Time
+}
+
+// NewHoldExpirer creates an expirer that looks for expired holds every interval
+func NewHoldExpirer(store db.Store, interval time.Duration) *HoldExpirer {
+	return &HoldExpirer{
+		store:    store,
+		interval: interval,
+		now:      func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Run releases expired holds every interval until ctx is done
+func (e *HoldExpirer) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce releases the holds expired now, up to holdExpiryBatchSize, and returns how many it released
+func (e *HoldExpirer) RunOnce(ctx context.Context) (int, error) {
+	expired, err := e.store.ExpireHoldsTx(ctx, e.now(), holdExpiryBatchSize)
+	if err != nil {
+		log.Printf("hold expiry failed: %v", err)
+		return 0, err
+	}
+
+	for _, hold := range expired {
+		log.Printf("hold %d on account %d expired", hold.ID, hold.FromAccountID)
+	}
+	return len(expired), nil
+}
@@ -0,0 +1,71 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mockdb "github.com/T-BO0/bank/db/mock"
+	db "github.com/T-BO0/bank/db/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHoldExpirerRunOnce(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name        string
+		buildStubs  func(store *mockdb.MockStore)
+		wantExpired int
+		wantErr     bool
+	}{
+		{
+			name: "NothingExpired",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ExpireHoldsTx(gomock.Any(), gomock.Eq(now), gomock.Eq(int32(holdExpiryBatchSize))).Times(1).
+					Return(nil, nil)
+			},
+			wantExpired: 0,
+		},
+		{
+			name: "ReleasesExpired",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ExpireHoldsTx(gomock.Any(), gomock.Eq(now), gomock.Eq(int32(holdExpiryBatchSize))).Times(1).
+					Return([]db.Hold{{ID: 1, Status: db.HoldExpired}, {ID: 2, Status: db.HoldExpired}}, nil)
+			},
+			wantExpired: 2,
+		},
+		{
+			name: "Error",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ExpireHoldsTx(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+					Return(nil, errors.New("connection refused"))
+			},
+			wantExpired: 0,
+			wantErr:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			expirer := NewHoldExpirer(store, time.Minute)
+			expirer.now = func() time.Time { return now }
+
+			expired, err := expirer.RunOnce(context.Background())
+			require.Equal(t, tc.wantExpired, expired)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
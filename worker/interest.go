@@ -0,0 +1,95 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	db "github.com/T-BO0/bank/db/sqlc"
+)
+
+// InterestJob accrues the interest of every finished bank day and posts the last finished month
+// Days missed while the job wasn't running are accrued by the next run, oldest first. Both steps are idempotent,
+// so a day or a month that was cut short is simply run again
+type InterestJob struct {
+	store    db.Store
+	interval time.Duration
+	now      func() time.Time
+	// lastAccrued is the last day accrued by this job, zero until the first run looked it up
+	lastAccrued time.Time
+}
+
+// NewInterestJob creates an interest job that runs every interval
+func NewInterestJob(store db.Store, interval time.Duration) *InterestJob {
+	return &InterestJob{
+		store:    store,
+		interval: interval,
+		now:      time.Now,
+	}
+}
+
+// Run accrues and posts interest every interval until ctx is done
+func (j *InterestJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			j.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce accrues every day since the last accrued one up to yesterday and then posts the month before the current one
+func (j *InterestJob) RunOnce(ctx context.Context) error {
+	today := db.BankDate(j.now())
+	yesterday := today.AddDate(0, 0, -1)
+
+	day, err := j.nextDayToAccrue(ctx, yesterday)
+	if err != nil {
+		log.Printf("interest accrual failed: %v", err)
+		return err
+	}
+	for ; !day.After(yesterday); day = day.AddDate(0, 0, 1) {
+		accrued, err := j.store.AccrueInterest(ctx, day)
+		if err != nil {
+			log.Printf("interest accrual failed: %v", err)
+			return err
+		}
+		j.lastAccrued = day
+		if accrued.Processed > 0 {
+			log.Printf("interest accrued on %d accounts for %s", accrued.Processed, accrued.Date.Format("2006-01-02"))
+		}
+	}
+
+	posted, err := j.store.PostInterest(ctx, today.AddDate(0, -1, 1-today.Day()))
+	if err != nil {
+		log.Printf("interest posting failed: %v", err)
+		return err
+	}
+	if posted.Processed > 0 || posted.Failed > 0 {
+		log.Printf("interest for %s posted to %d accounts, %d rejected", posted.Date.Format("2006-01"), posted.Processed, posted.Failed)
+	}
+	return nil
+}
+
+// nextDayToAccrue returns the first day the job has to accrue, yesterday when nothing was ever accrued
+// On the first run it starts at the last day found in the accruals, which an earlier process may not have finished
+func (j *InterestJob) nextDayToAccrue(ctx context.Context, yesterday time.Time) (time.Time, error) {
+	if !j.lastAccrued.IsZero() {
+		return j.lastAccrued.AddDate(0, 0, 1), nil
+	}
+
+	last, err := j.store.GetLastInterestAccrualDate(ctx)
+	if err == sql.ErrNoRows {
+		return yesterday, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(last.Year(), last.Month(), last.Day(), 0, 0, 0, 0, time.UTC), nil
+}
@@ -0,0 +1,136 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	mockdb "github.com/T-BO0/bank/db/mock"
+	db "github.com/T-BO0/bank/db/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterestJobRunOnce(t *testing.T) {
+	// 21:30 UTC is already the next day on the bank's clock
+	now := time.Date(2024, time.February, 29, 21, 30, 0, 0, time.UTC)
+	yesterday := time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)
+	lastMonth := time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+		wantErr    bool
+	}{
+		{
+			name: "AccruesAndPosts",
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					store.EXPECT().GetLastInterestAccrualDate(gomock.Any()).Times(1).Return(time.Time{}, sql.ErrNoRows),
+					store.EXPECT().AccrueInterest(gomock.Any(), gomock.Eq(yesterday)).Times(1).
+						Return(db.InterestRunResult{Date: yesterday, Processed: 3}, nil),
+					store.EXPECT().PostInterest(gomock.Any(), gomock.Eq(lastMonth)).Times(1).
+						Return(db.InterestRunResult{Date: lastMonth, Processed: 3}, nil),
+				)
+			},
+		},
+		{
+			name: "BackfillsMissedDays",
+			buildStubs: func(store *mockdb.MockStore) {
+				// the last run stopped part way through the 27th
+				lastAccrued := yesterday.AddDate(0, 0, -2)
+				gomock.InOrder(
+					store.EXPECT().GetLastInterestAccrualDate(gomock.Any()).Times(1).Return(lastAccrued, nil),
+					store.EXPECT().AccrueInterest(gomock.Any(), gomock.Eq(lastAccrued)).Times(1).Return(db.InterestRunResult{}, nil),
+					store.EXPECT().AccrueInterest(gomock.Any(), gomock.Eq(lastAccrued.AddDate(0, 0, 1))).Times(1).Return(db.InterestRunResult{}, nil),
+					store.EXPECT().AccrueInterest(gomock.Any(), gomock.Eq(yesterday)).Times(1).Return(db.InterestRunResult{}, nil),
+					store.EXPECT().PostInterest(gomock.Any(), gomock.Eq(lastMonth)).Times(1).Return(db.InterestRunResult{}, nil),
+				)
+			},
+		},
+		{
+			name: "LastAccrualDateError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetLastInterestAccrualDate(gomock.Any()).Times(1).Return(time.Time{}, errors.New("connection refused"))
+				store.EXPECT().AccrueInterest(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().PostInterest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantErr: true,
+		},
+		{
+			name: "AccrualError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetLastInterestAccrualDate(gomock.Any()).Times(1).Return(yesterday, nil)
+				store.EXPECT().AccrueInterest(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.InterestRunResult{}, errors.New("connection refused"))
+				store.EXPECT().PostInterest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantErr: true,
+		},
+		{
+			name: "PostingError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetLastInterestAccrualDate(gomock.Any()).Times(1).Return(yesterday, nil)
+				store.EXPECT().AccrueInterest(gomock.Any(), gomock.Any()).Times(1).Return(db.InterestRunResult{}, nil)
+				store.EXPECT().PostInterest(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.InterestRunResult{}, errors.New("connection refused"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			job := NewInterestJob(store, time.Hour)
+			job.now = func() time.Time { return now }
+
+			err := job.RunOnce(context.Background())
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestInterestJobSkippedDay(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	job := NewInterestJob(store, time.Hour)
+
+	march1 := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	march2 := time.Date(2024, time.March, 2, 0, 0, 0, 0, time.UTC)
+	march3 := time.Date(2024, time.March, 3, 0, 0, 0, 0, time.UTC)
+
+	gomock.InOrder(
+		// the first run looks the last accrued day up once, later runs go on from where the job got to
+		store.EXPECT().GetLastInterestAccrualDate(gomock.Any()).Times(1).Return(march1, nil),
+		store.EXPECT().AccrueInterest(gomock.Any(), gomock.Eq(march1)).Times(1).Return(db.InterestRunResult{}, nil),
+		store.EXPECT().PostInterest(gomock.Any(), gomock.Any()).Times(1).Return(db.InterestRunResult{}, nil),
+		// nothing ran on the 3rd, the run on the 4th accrues both the 2nd and the 3rd
+		store.EXPECT().AccrueInterest(gomock.Any(), gomock.Eq(march2)).Times(1).Return(db.InterestRunResult{}, nil),
+		store.EXPECT().AccrueInterest(gomock.Any(), gomock.Eq(march3)).Times(1).Return(db.InterestRunResult{}, nil),
+		store.EXPECT().PostInterest(gomock.Any(), gomock.Any()).Times(1).Return(db.InterestRunResult{}, nil),
+		// a second run on the same day has no day left to accrue
+		store.EXPECT().PostInterest(gomock.Any(), gomock.Any()).Times(1).Return(db.InterestRunResult{}, nil),
+	)
+
+	// 08:00 UTC is noon on the bank's clock
+	job.now = func() time.Time { return time.Date(2024, time.March, 2, 8, 0, 0, 0, time.UTC) }
+	require.NoError(t, job.RunOnce(context.Background()))
+
+	job.now = func() time.Time { return time.Date(2024, time.March, 4, 8, 0, 0, 0, time.UTC) }
+	require.NoError(t, job.RunOnce(context.Background()))
+	require.NoError(t, job.RunOnce(context.Background()))
+}
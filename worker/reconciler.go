@@ -0,0 +1,66 @@
+// Package worker runs background jobs next to the http server
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	db "github.com/T-BO0/bank/db/sqlc"
+)
+
+// Reconciler runs the ledger reconciliation on a fixed interval and logs what it finds
+type Reconciler struct {
+	store    db.Store
+	interval time.Duration
+}
+
+// NewReconciler creates a reconciler that runs every interval
+func NewReconciler(store db.Store, interval time.Duration) *Reconciler {
+	return &Reconciler{
+		store:    store,
+		interval: interval,
+	}
+}
+
+// Run reconciles every interval until ctx is done
+func (r *Reconciler) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce reconciles the ledger a single time and logs the report
+func (r *Reconciler) RunOnce(ctx context.Context) (db.ReconciliationReport, error) {
+	report, err := r.store.Reconcile(ctx)
+	if err != nil {
+		log.Printf("reconciliation failed: %v", err)
+		return report, err
+	}
+
+	if report.OK {
+		log.Printf("reconciliation ok: %d accounts checked", report.AccountsChecked)
+		return report, nil
+	}
+
+	log.Printf("reconciliation found %d balance mismatches, %d unbalanced transfers and %d orphaned entries",
+		len(report.BalanceMismatches), len(report.UnbalancedTransfers), len(report.OrphanedEntries))
+	for _, mismatch := range report.BalanceMismatches {
+		log.Printf("account %d balance %d does not match entries total %d", mismatch.ID, mismatch.Balance, mismatch.EntriesTotal)
+	}
+	for _, transfer := range report.UnbalancedTransfers {
+		log.Printf("transfer %d has %d entries that do not match it", transfer.ID, transfer.EntryCount)
+	}
+	for _, entry := range report.OrphanedEntries {
+		log.Printf("entry %d of account %d has no matching transfer", entry.ID, entry.AccountID)
+	}
+	return report, nil
+}
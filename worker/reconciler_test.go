@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mockdb "github.com/T-BO0/bank/db/mock"
+	db "github.com/T-BO0/bank/db/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcilerRunOnce(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	expected := db.ReconciliationReport{
+		AccountsChecked:   2,
+		BalanceMismatches: []db.ListBalanceMismatchesRow{{ID: 1, Balance: 100, EntriesTotal: 90}},
+	}
+	store.EXPECT().Reconcile(gomock.Any()).Times(1).Return(expected, nil)
+
+	report, err := NewReconciler(store, time.Minute).RunOnce(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, expected, report)
+}
+
+func TestReconcilerRunOnceError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().Reconcile(gomock.Any()).Times(1).Return(db.ReconciliationReport{}, errors.New("connection refused"))
+
+	_, err := NewReconciler(store, time.Minute).RunOnce(context.Background())
+	require.Error(t, err)
+}
+
+func TestReconcilerRunStops(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().Reconcile(gomock.Any()).MinTimes(1).Return(db.ReconciliationReport{OK: true}, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		NewReconciler(store, 10*time.Millisecond).Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("reconciler did not stop after the context was done")
+	}
+}
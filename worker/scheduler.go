@@ -0,0 +1,64 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	db "github.com/T-BO0/bank/db/sqlc"
+)
+
+// schedulerBatchSize bounds the scheduled transfers run per tick, the rest wait for the next one
+const schedulerBatchSize = 100
+
+// Scheduler runs due scheduled transfers on a fixed interval
+// Several schedulers can run against the same database, each due transfer is claimed by one of them
+type Scheduler struct {
+	store    db.Store
+	interval time.Duration
+	now      func() time.Time
+}
+
+// NewScheduler creates a scheduler that looks for due transfers every interval
+func NewScheduler(store db.Store, interval time.Duration) *Scheduler {
+	return &Scheduler{
+		store:    store,
+		interval: interval,
+		now:      func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Run runs due transfers every interval until ctx is done
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce runs the transfers due now, up to schedulerBatchSize, and returns how many it ran
+func (s *Scheduler) RunOnce(ctx context.Context) (int, error) {
+	for ran := 0; ran < schedulerBatchSize; ran++ {
+		result, err := s.store.RunScheduledTransferTx(ctx, s.now())
+		if errors.Is(err, db.ErrNoScheduledTransferDue) {
+			return ran, nil
+		}
+		if err != nil {
+			log.Printf("scheduled transfer failed: %v", err)
+			return ran, err
+		}
+
+		if result.Run.Status == db.ScheduledRunFailed {
+			log.Printf("scheduled transfer %d was rejected: %s", result.ScheduledTransfer.ID, result.Run.Error.String)
+		}
+	}
+	return schedulerBatchSize, nil
+}
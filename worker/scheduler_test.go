@@ -0,0 +1,89 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	mockdb "github.com/T-BO0/bank/db/mock"
+	db "github.com/T-BO0/bank/db/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedulerRunOnce(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+		wantRan    int
+		wantErr    bool
+	}{
+		{
+			name: "NothingDue",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().RunScheduledTransferTx(gomock.Any(), gomock.Eq(now)).Times(1).
+					Return(db.ScheduledTransferTxResult{}, db.ErrNoScheduledTransferDue)
+			},
+			wantRan: 0,
+		},
+		{
+			name: "RunsUntilNothingIsDue",
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					store.EXPECT().RunScheduledTransferTx(gomock.Any(), gomock.Eq(now)).Times(1).
+						Return(db.ScheduledTransferTxResult{Run: db.ScheduledTransferRun{Status: db.ScheduledRunSucceeded}}, nil),
+					store.EXPECT().RunScheduledTransferTx(gomock.Any(), gomock.Eq(now)).Times(1).
+						Return(db.ScheduledTransferTxResult{Run: db.ScheduledTransferRun{
+							Status: db.ScheduledRunFailed,
+							Error:  sql.NullString{String: db.ErrInsufficientFunds.Error(), Valid: true},
+						}}, nil),
+					store.EXPECT().RunScheduledTransferTx(gomock.Any(), gomock.Eq(now)).Times(1).
+						Return(db.ScheduledTransferTxResult{}, db.ErrNoScheduledTransferDue),
+				)
+			},
+			wantRan: 2,
+		},
+		{
+			name: "StopsOnError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().RunScheduledTransferTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.ScheduledTransferTxResult{}, errors.New("connection refused"))
+			},
+			wantRan: 0,
+			wantErr: true,
+		},
+		{
+			name: "BoundedBatch",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().RunScheduledTransferTx(gomock.Any(), gomock.Any()).Times(schedulerBatchSize).
+					Return(db.ScheduledTransferTxResult{Run: db.ScheduledTransferRun{Status: db.ScheduledRunSucceeded}}, nil)
+			},
+			wantRan: schedulerBatchSize,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			scheduler := NewScheduler(store, time.Minute)
+			scheduler.now = func() time.Time { return now }
+
+			ran, err := scheduler.RunOnce(context.Background())
+			require.Equal(t, tc.wantRan, ran)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}